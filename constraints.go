@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Validation annotations are not linked into the plugin, hence they are read
+// from the unknown fields of the field options. protoc-gen-validate's
+// `(validate.rules)` and protovalidate's `(buf.validate.field)` share the same
+// layout for the string rules used here.
+const (
+	pgvRulesNumber           protowire.Number = 1071
+	protovalidateFieldNumber protowire.Number = 1159
+
+	// FieldRules.string
+	stringRulesNumber protowire.Number = 14
+
+	// StringRules fields
+	stringMinLenNumber  protowire.Number = 2
+	stringMaxLenNumber  protowire.Number = 3
+	stringPatternNumber protowire.Number = 6
+	stringLenNumber     protowire.Number = 19
+)
+
+// placeholderFill is used to pad redaction values up to a minimum length
+const placeholderFill = "*"
+
+// StringConstraints are the validation rules of a string field which are
+// checked against its redaction value
+type StringConstraints struct {
+	Len     *uint64
+	MinLen  *uint64
+	MaxLen  *uint64
+	Pattern string
+}
+
+// stringConstraints extracts the string validation rules from the field options,
+// it returns false if the field has no such rules
+func stringConstraints(opts *descriptorpb.FieldOptions) (*StringConstraints, bool) {
+	if opts == nil {
+		return nil, false
+	}
+
+	found := false
+	c := &StringConstraints{}
+	eachField(opts.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, val []byte) {
+		if typ != protowire.BytesType || (num != pgvRulesNumber && num != protovalidateFieldNumber) {
+			return
+		}
+		eachField(val, func(num protowire.Number, typ protowire.Type, val []byte) {
+			if typ != protowire.BytesType || num != stringRulesNumber {
+				return
+			}
+			found = true
+			c.merge(val)
+		})
+	})
+	return c, found
+}
+
+// merge reads the StringRules message from its wire format
+func (c *StringConstraints) merge(b []byte) {
+	eachField(b, func(num protowire.Number, typ protowire.Type, val []byte) {
+		if typ == protowire.BytesType {
+			if num == stringPatternNumber {
+				c.Pattern = string(val)
+			}
+			return
+		}
+		if typ != protowire.VarintType {
+			return
+		}
+		v, n := protowire.ConsumeVarint(val)
+		if n < 0 {
+			return
+		}
+		switch num {
+		case stringLenNumber:
+			c.Len = &v
+		case stringMinLenNumber:
+			c.MinLen = &v
+		case stringMaxLenNumber:
+			c.MaxLen = &v
+		}
+	})
+}
+
+// Check returns an error describing the first constraint violated by value
+func (c *StringConstraints) Check(value string) error {
+	length := uint64(utf8.RuneCountInString(value))
+	if c.Len != nil && length != *c.Len {
+		return fmt.Errorf("length must be %d, got %d", *c.Len, length)
+	}
+	if c.MinLen != nil && length < *c.MinLen {
+		return fmt.Errorf("length must be at least %d, got %d", *c.MinLen, length)
+	}
+	if c.MaxLen != nil && length > *c.MaxLen {
+		return fmt.Errorf("length must be at most %d, got %d", *c.MaxLen, length)
+	}
+	if c.Pattern != "" {
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q cannot be evaluated: %v", c.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("value does not match pattern %q", c.Pattern)
+		}
+	}
+	return nil
+}
+
+// Placeholder returns a value derived from the input that satisfies the length
+// constraints, by padding or truncating it. It returns false when no such value
+// can be derived, e.g. when the pattern constraint is still violated.
+func (c *StringConstraints) Placeholder(value string) (string, bool) {
+	runes := []rune(value)
+	minLen, maxLen := c.MinLen, c.MaxLen
+	if c.Len != nil {
+		minLen, maxLen = c.Len, c.Len
+	}
+	if maxLen != nil && uint64(len(runes)) > *maxLen {
+		runes = runes[:*maxLen]
+	}
+	out := string(runes)
+	if minLen != nil && uint64(len(runes)) < *minLen {
+		out += strings.Repeat(placeholderFill, int(*minLen)-len(runes))
+	}
+	return out, c.Check(out) == nil
+}
+
+// eachField iterates over the top level fields of a wire-format message
+func eachField(b []byte, fn func(num protowire.Number, typ protowire.Type, val []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return
+		}
+		val := b[:m]
+		if typ == protowire.BytesType {
+			v, _ := protowire.ConsumeBytes(b)
+			val = v
+		}
+		fn(num, typ, val)
+		b = b[m:]
+	}
+}
+
+// respectConstraints checks the redaction value of a singular string field
+// against its validation rules, and replaces it with a compatible placeholder
+// when possible. Otherwise a warning is logged. The custom values, the
+// defaults and the placeholder vars are checked, the default expressions are
+// only known at run time.
+func (m *Module) respectConstraints(field pgs.Field, flData *FieldData) {
+	typ := field.Type()
+	if typ.ProtoType() != pgs.StringT || typ.IsRepeated() || typ.IsMap() {
+		return
+	}
+	c, ok := stringConstraints(field.Descriptor().GetOptions())
+	if !ok {
+		return
+	}
+	value, err := strconv.Unquote(m.stringLiteral(flData.RedactionValue))
+	if err != nil {
+		return
+	}
+	violation := c.Check(value)
+	if violation == nil {
+		return
+	}
+	if placeholder, ok := c.Placeholder(value); ok {
+		m.Logf("Warning: redaction value %q of %s violates its validation rules (%v), using %q instead",
+			value, field.FullyQualifiedName(), violation, placeholder)
+		flData.RedactionValue = strconv.Quote(placeholder)
+		return
+	}
+	m.Logf("Warning: redaction value %q of %s violates its validation rules (%v)",
+		value, field.FullyQualifiedName(), violation)
+}
+
+// stringLiteral returns the literal of the string redaction value, the
+// placeholder vars being replaced by the literal they hold
+func (m *Module) stringLiteral(value string) string {
+	if value != placeholderName(pgs.StringT) && value != runtimeMarkerName {
+		return value
+	}
+	if lit, ok := m.defaults[pgs.StringT]; ok {
+		return lit
+	}
+	return RedactionDefaults(pgs.StringT, false)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// stringRulesOptions builds field options carrying string validation rules
+// under the given extension number
+func stringRulesOptions(ext protowire.Number, rules []byte) *descriptorpb.FieldOptions {
+	var fieldRules []byte
+	fieldRules = protowire.AppendTag(fieldRules, stringRulesNumber, protowire.BytesType)
+	fieldRules = protowire.AppendBytes(fieldRules, rules)
+
+	var raw []byte
+	raw = protowire.AppendTag(raw, ext, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, fieldRules)
+
+	opts := &descriptorpb.FieldOptions{}
+	opts.ProtoReflect().SetUnknown(raw)
+	return opts
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// TestStringConstraintsParsing tests extraction of string rules from options
+func TestStringConstraintsParsing(t *testing.T) {
+	var rules []byte
+	rules = appendVarintField(rules, stringMinLenNumber, 2)
+	rules = appendVarintField(rules, stringMaxLenNumber, 8)
+	rules = protowire.AppendTag(rules, stringPatternNumber, protowire.BytesType)
+	rules = protowire.AppendString(rules, "^[A-Z]+$")
+
+	for _, ext := range []protowire.Number{pgvRulesNumber, protovalidateFieldNumber} {
+		c, ok := stringConstraints(stringRulesOptions(ext, rules))
+		require.True(t, ok)
+		require.NotNil(t, c.MinLen)
+		require.NotNil(t, c.MaxLen)
+		assert.Nil(t, c.Len)
+		assert.Equal(t, uint64(2), *c.MinLen)
+		assert.Equal(t, uint64(8), *c.MaxLen)
+		assert.Equal(t, "^[A-Z]+$", c.Pattern)
+	}
+
+	t.Run("no_rules", func(t *testing.T) {
+		_, ok := stringConstraints(&descriptorpb.FieldOptions{})
+		assert.False(t, ok)
+		_, ok = stringConstraints(nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("unrelated_extension", func(t *testing.T) {
+		_, ok := stringConstraints(stringRulesOptions(1234, rules))
+		assert.False(t, ok)
+	})
+}
+
+// TestStringConstraintsPlaceholder tests checking and adapting redaction values
+func TestStringConstraintsPlaceholder(t *testing.T) {
+	u := func(v uint64) *uint64 { return &v }
+
+	tests := []struct {
+		name        string
+		constraints StringConstraints
+		value       string
+		valid       bool
+		placeholder string
+		compatible  bool
+	}{
+		{"no_constraints", StringConstraints{}, "REDACTED", true, "REDACTED", true},
+		{"max_len", StringConstraints{MaxLen: u(4)}, "REDACTED", false, "REDA", true},
+		{"min_len", StringConstraints{MinLen: u(10)}, "REDACTED", false, "REDACTED**", true},
+		{"exact_len", StringConstraints{Len: u(3)}, "REDACTED", false, "RED", true},
+		{"multibyte_len", StringConstraints{MaxLen: u(2)}, "ééé", false, "éé", true},
+		{"pattern_match", StringConstraints{Pattern: "^[A-Z]+$"}, "REDACTED", true, "REDACTED", true},
+		{"pattern_mismatch", StringConstraints{Pattern: "^[0-9]+$"}, "REDACTED", false, "REDACTED", false},
+		{"invalid_pattern", StringConstraints{Pattern: "("}, "REDACTED", false, "REDACTED", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraints.Check(tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			placeholder, ok := tt.constraints.Placeholder(tt.value)
+			assert.Equal(t, tt.placeholder, placeholder)
+			assert.Equal(t, tt.compatible, ok)
+		})
+	}
+}
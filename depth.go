@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+)
+
+// limitDepth bounds the nesting of the redaction calls of the messages of the
+// file to maxDepth: the nested calls beyond it redact the fields to nil. The
+// depth of a message is the longest chain of nested calls reaching it from
+// the other messages of the file, the recursive calls are always cut as their
+// depth is unbounded. The data of the messages and of their fields follow the
+// order of the messages and of their fields.
+func (m *Module) limitDepth(msgs []pgs.Message, data []*MessageData) {
+	index := make(map[string]int, len(msgs))
+	for i, msg := range msgs {
+		index[msg.FullyQualifiedName()] = i
+	}
+
+	// nested calls of each message: the index of the called message, -1 for
+	// the messages of the other files
+	type call struct {
+		field  *FieldData
+		target int
+	}
+	calls := make([][]call, len(msgs))
+	for i, msg := range msgs {
+		if data[i] == nil || data[i].Ignore || data[i].ToNil || data[i].ToEmpty {
+			continue
+		}
+		for j, field := range msg.Fields() {
+			if j >= len(data[i].Fields) || !data[i].Fields[j].NestedEmbedCall {
+				continue
+			}
+			em := field.Type().Embed()
+			if em == nil && field.Type().Element() != nil {
+				em = field.Type().Element().Embed()
+			}
+			target := -1
+			if em != nil {
+				if k, ok := index[em.FullyQualifiedName()]; ok {
+					target = k
+				}
+			}
+			calls[i] = append(calls[i], call{field: data[i].Fields[j], target: target})
+		}
+	}
+
+	// the recursive calls are found by a depth-first search, the messages are
+	// then visited in topological order
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(msgs))
+	order := make([]int, 0, len(msgs))
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		for _, c := range calls[i] {
+			switch {
+			case c.target < 0:
+			case state[c.target] == visiting:
+				m.Debug(fmt.Sprintf("Cutting the recursive redaction of %s in %s", msgs[c.target].FullyQualifiedName(), msgs[i].FullyQualifiedName()))
+				cutNestedCall(c.field)
+			case state[c.target] == unvisited:
+				visit(c.target)
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+	}
+	for i := range msgs {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+
+	// the calls beyond maxDepth are cut, hence do not reach their messages
+	depth := make([]int, len(msgs))
+	for n := len(order) - 1; n >= 0; n-- {
+		i := order[n]
+		for _, c := range calls[i] {
+			if !c.field.NestedEmbedCall {
+				continue
+			}
+			if depth[i]+1 > m.maxDepth {
+				cutNestedCall(c.field)
+				continue
+			}
+			if c.target >= 0 && depth[c.target] < depth[i]+1 {
+				depth[c.target] = depth[i] + 1
+			}
+		}
+	}
+}
+
+// cutNestedCall redacts the message field, or all its items, to nil instead
+// of calling the redaction of the message
+func cutNestedCall(field *FieldData) {
+	field.NestedEmbedCall = false
+	field.ImportedRedactor = false
+	field.Iterate = false
+	field.RedactionValue = "nil"
+}
@@ -2,6 +2,13 @@ package main
 
 import (
 	"fmt"
+	"go/token"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
 	"google.golang.org/grpc/codes"
@@ -109,6 +116,82 @@ func (m *Module) validateMessage(msg pgs.Message) error {
 		}
 	}
 
+	if err := m.validateExcept(msg); err != nil {
+		return err
+	}
+	return m.validateGatedBy(msg)
+}
+
+// validateGatedBy validates the gate of the gated_by option of the message is a
+// singular bool field without rules, as it is kept
+func (m *Module) validateGatedBy(msg pgs.Message) error {
+	gatedBy := ""
+	m.must(msg.Extension(redact.E_GatedBy, &gatedBy))
+	if gatedBy == "" {
+		return nil
+	}
+	gate := m.gateField(msg)
+	if gate == nil {
+		return ValidationError{
+			Entity:   fmt.Sprintf("message %s", msg.FullyQualifiedName()),
+			Expected: "(redact.v3.gated_by) naming a field of the message",
+			Got:      fmt.Sprintf("unknown field %q", gatedBy),
+			Hint:     "use the proto name of a bool field, e.g. is_sensitive",
+		}
+	}
+	if typ := gate.Type(); typ.IsRepeated() || typ.IsMap() || typ.ProtoType() != pgs.BoolT {
+		return ValidationError{
+			Entity:   gate.FullyQualifiedName(),
+			Expected: "singular bool field for (redact.v3.gated_by)",
+			Got:      typ.ProtoType().String(),
+			Hint:     "the message is redacted only when the field is true",
+		}
+	}
+	rules := &redact.FieldRules{}
+	if m.must(gate.Extension(redact.E_Value, &rules)) && rules.GetValues() != nil {
+		return ValidationError{
+			Entity:   gate.FullyQualifiedName(),
+			Expected: "either (redact.v3.gated_by) or a (redact.v3.value) rule",
+			Got:      "both",
+			Hint:     "the gate of the message is never redacted, remove the rule",
+		}
+	}
+	return nil
+}
+
+// validateExcept validates the fields listed by the except option of the
+// message: the unknown names are warned about, and the listed fields are kept
+// hence cannot have rules
+func (m *Module) validateExcept(msg pgs.Message) error {
+	except := m.exceptFields(msg)
+	if except == nil {
+		return nil
+	}
+	fields := make(map[string]pgs.Field, len(msg.Fields()))
+	for _, field := range msg.Fields() {
+		fields[field.Name().String()] = field
+	}
+	names := make([]string, 0, len(except))
+	for name := range except {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			m.Logf("Warning: (redact.v3.except) of %s lists the unknown field %q", msg.FullyQualifiedName(), name)
+			continue
+		}
+		rules := &redact.FieldRules{}
+		if m.must(field.Extension(redact.E_Value, &rules)) && rules.GetValues() != nil {
+			return ValidationError{
+				Entity:   field.FullyQualifiedName(),
+				Expected: "either (redact.v3.except) or a (redact.v3.value) rule",
+				Got:      "both",
+				Hint:     "the fields listed by except are never redacted, remove the rule or the name",
+			}
+		}
+	}
 	return nil
 }
 
@@ -118,9 +201,16 @@ func (m *Module) validateService(srv pgs.Service) error {
 		return fmt.Errorf("service is nil")
 	}
 
-	// Validate internal service status code
+	// Validate internal service status code, the methods inheriting it report
+	// it themselves, pointing at the methods affected
 	srvCode := uint32(codes.PermissionDenied)
 	if m.must(srv.Extension(redact.E_InternalServiceCode, &srvCode)) {
+		for _, meth := range srv.Methods() {
+			methCode := uint32(0)
+			if !m.must(meth.Extension(redact.E_InternalMethodCode, &methCode)) {
+				return nil
+			}
+		}
 		if err := m.validateStatusCode(srvCode, srv.FullyQualifiedName()); err != nil {
 			return err
 		}
@@ -143,15 +233,35 @@ func (m *Module) validateMethod(meth pgs.Method) error {
 		return fmt.Errorf("method %s has nil output", meth.Name())
 	}
 
-	// Validate internal method status code
+	// Validate the effective status code of the method, its own or the one
+	// inherited from the service
 	methCode := uint32(codes.PermissionDenied)
-	if m.must(meth.Extension(redact.E_InternalMethodCode, &methCode)) {
-		if err := m.validateStatusCode(methCode, meth.FullyQualifiedName()); err != nil {
-			return err
-		}
+	inherited := !m.must(meth.Extension(redact.E_InternalMethodCode, &methCode))
+	if inherited && !m.must(meth.Service().Extension(redact.E_InternalServiceCode, &methCode)) {
+		return nil
 	}
+	return m.validateMethodCode(meth, methCode, inherited)
+}
 
-	return nil
+// validateMethodCode validates the effective status code of the method, the
+// error reports whether it is set on the method or inherited from the service,
+// pointing at the option to fix
+func (m *Module) validateMethodCode(meth pgs.Method, code uint32, inherited bool) error {
+	if m.validateStatusCode(code, meth.FullyQualifiedName()) == nil {
+		return nil
+	}
+	source := "(redact.v3.internal_method_code) of the method"
+	hint := "see https://grpc.io/docs/guides/status-codes/ for valid codes"
+	if inherited {
+		source = fmt.Sprintf("(redact.v3.internal_service_code) inherited from %s", meth.Service().FullyQualifiedName())
+		hint = "fix the code of the service, or override it with (redact.v3.internal_method_code)"
+	}
+	return ValidationError{
+		Entity:   fmt.Sprintf("status code of method %s", meth.FullyQualifiedName()),
+		Expected: "valid gRPC status code (0-16)",
+		Got:      fmt.Sprintf("%d from %s", code, source),
+		Hint:     hint,
+	}
 }
 
 // validateStatusCode validates a gRPC status code
@@ -176,7 +286,7 @@ func (m *Module) validateTypeMatch(
 	fieldType := field.Type()
 
 	// Check type match
-	if ruleType != 0 && ruleType != fieldType.ProtoType() {
+	if ruleType != 0 && ruleType != rulesProtoType(fieldType.ProtoType()) {
 		return ValidationError{
 			Entity:   fmt.Sprintf("field %s", field.FullyQualifiedName()),
 			Expected: fmt.Sprintf("rule for type %s", fieldType.ProtoType()),
@@ -263,6 +373,10 @@ func (m *Module) validateRules(rules *redact.FieldRules, field pgs.Field) error
 		}
 	}
 
+	if err := validateRuleLabel(rules, field); err != nil {
+		return err
+	}
+
 	// Validate message rules
 	if msgRule, ok := rules.Values.(*redact.FieldRules_Message); ok {
 		if msgRule.Message == nil {
@@ -273,6 +387,16 @@ func (m *Module) validateRules(rules *redact.FieldRules, field pgs.Field) error
 				Hint:     "use (redact.custom).message.nil, .empty, or .skip",
 			}
 		}
+		if err := validateDepth(msgRule.Message, field); err != nil {
+			return err
+		}
+	}
+
+	if err := validateRoundRules(rules, field, field.Type().ProtoType()); err != nil {
+		return err
+	}
+	if err := validateRegexReplace(rules, field); err != nil {
+		return err
 	}
 
 	// Validate element rules
@@ -282,7 +406,38 @@ func (m *Module) validateRules(rules *redact.FieldRules, field pgs.Field) error
 				Entity:   field.FullyQualifiedName(),
 				Expected: "element rule definition",
 				Got:      "nil element rule",
-				Hint:     "use (redact.custom).element.nested, .empty, or .item.*",
+				Hint:     "use (redact.custom).element.nested, .empty, .clear_elements, .collapse, or .item.*",
+			}
+		}
+
+		if key := elemRule.Element.GetKey(); key != nil {
+			if err := validateKeyRules(elemRule.Element, field); err != nil {
+				return err
+			}
+		}
+		if elemRule.Element.GetCollapse() {
+			if err := validateCollapseRules(elemRule.Element, field); err != nil {
+				return err
+			}
+		}
+
+		if elemRule.Element.GetItem().GetCopyFrom() != "" {
+			return ValidationError{
+				Entity:   field.FullyQualifiedName(),
+				Expected: "(redact.custom).copy_from on a singular field",
+				Got:      "element.item.copy_from",
+				Hint:     "the items cannot be copied from a sibling field",
+			}
+		}
+		if item := elemRule.Element.GetItem(); item != nil && field.Type().Element() != nil {
+			if err := validateRoundRules(item, field, field.Type().Element().ProtoType()); err != nil {
+				return err
+			}
+			if err := validateRegexReplace(item, field); err != nil {
+				return err
+			}
+			if err := validateDepth(item.GetMessage(), field); err != nil {
+				return err
 			}
 		}
 
@@ -302,6 +457,228 @@ func (m *Module) validateRules(rules *redact.FieldRules, field pgs.Field) error
 	return nil
 }
 
+// validateRuleLabel validates the rules match the cardinality of the field: the
+// repeated and map fields only use the element rules, e.g. not the message
+// rules of their items, and the singular fields never do. The copy_from rules
+// are validated with the sibling field.
+func validateRuleLabel(rules *redact.FieldRules, field pgs.Field) error {
+	if rules.GetCopyFrom() != "" {
+		return nil
+	}
+	typ := field.Type()
+	_, element := rules.Values.(*redact.FieldRules_Element)
+	switch {
+	case (typ.IsRepeated() || typ.IsMap()) && !element:
+		got := "non-repeated rule"
+		hint := "repeated fields require element rules"
+		if _, ok := rules.Values.(*redact.FieldRules_Message); ok {
+			got = "(redact.custom).message.*"
+			hint = "use (redact.custom).element.nested or .element.item.message.* to redact the items"
+		}
+		return ValidationError{
+			Entity:   fmt.Sprintf("repeated field %s", field.FullyQualifiedName()),
+			Expected: "(redact.custom).element.*",
+			Got:      got,
+			Hint:     hint,
+		}
+	case !typ.IsRepeated() && !typ.IsMap() && element:
+		want := ToCustomRule(typ.ProtoType(), typ.ProtoLabel())
+		return ValidationError{
+			Entity:   fmt.Sprintf("singular field %s", field.FullyQualifiedName()),
+			Expected: want,
+			Got:      "(redact.custom).element.*",
+			Hint:     fmt.Sprintf("element rules only apply to repeated and map fields, use %s instead", want),
+		}
+	}
+	return nil
+}
+
+// validateKeyRules validates the rules of the keys of a map field, only the
+// string keys can be redacted, along with the scalar values
+func validateKeyRules(rule *redact.ElementRules, field pgs.Field) error {
+	typ := field.Type()
+	if !typ.IsMap() || typ.Key().ProtoType() != pgs.StringT {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "map field with string keys",
+			Got:      fmt.Sprintf("(redact.custom).element.key on %s", typ.ProtoType()),
+			Hint:     "only the string keys of maps can be redacted",
+		}
+	}
+	switch rule.GetKey().GetValues().(type) {
+	case *redact.FieldRules_String_, *redact.FieldRules_PanMask:
+	default:
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "(redact.custom).element.key.string or .pan_mask",
+			Got:      fmt.Sprintf("%T", rule.GetKey().GetValues()),
+			Hint:     "the keys are redacted to a string",
+		}
+	}
+	if rule.Empty || rule.Nested || rule.ClearElements || rule.GetItem().GetMessage() != nil {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "(redact.custom).element.key with scalar item rules only",
+			Got:      "empty, nested, clear_elements or message item rules",
+			Hint:     "the map is rebuilt with the redacted keys and values",
+		}
+	}
+	return nil
+}
+
+// validateCollapseRules validates the collapse of a list field, only the lists
+// of scalars are collapsed, to the value of the item rules if any
+func validateCollapseRules(rule *redact.ElementRules, field pgs.Field) error {
+	typ := field.Type()
+	if !typ.IsRepeated() || typ.Element().IsEmbed() {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "repeated scalar field",
+			Got:      fmt.Sprintf("(redact.custom).element.collapse on %s", typ.ProtoType()),
+			Hint:     "only the lists of scalars and enums can be collapsed",
+		}
+	}
+	if rule.Empty || rule.Nested || rule.ClearElements || rule.Key != nil {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "(redact.custom).element.collapse with an optional item value",
+			Got:      "empty, nested, clear_elements or key rules",
+			Hint:     "the list is replaced by a single sentinel item",
+		}
+	}
+	switch rule.GetItem().GetValues().(type) {
+	case *redact.FieldRules_Message, *redact.FieldRules_Element, *redact.FieldRules_PanMask,
+		*redact.FieldRules_EnumLast, *redact.FieldRules_Round, *redact.FieldRules_RoundTo,
+		*redact.FieldRules_CopyFrom, *redact.FieldRules_ZeroFill, *redact.FieldRules_RegexReplace,
+		*redact.FieldRules_Magnitude:
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "(redact.custom).element.item value of the sentinel",
+			Got:      fmt.Sprintf("%T", rule.GetItem().GetValues()),
+			Hint:     "the sentinel item is a value, e.g. .item.string",
+		}
+	}
+	return nil
+}
+
+// validateDepth validates the depth of the nested redaction of the message
+// rules: only the nested calls of the messages of the same file are limited,
+// the messages of the other files are redacted by their Redact() method
+func validateDepth(rule *redact.MessageRules, field pgs.Field) error {
+	depth := rule.GetDepth()
+	if depth == 0 {
+		return nil
+	}
+	invalid := func(expected, got string) error {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: expected,
+			Got:      got,
+			Hint:     "the depth limits the levels of the nested redaction of the message",
+		}
+	}
+	if depth < 0 {
+		return invalid("positive (redact.custom).message.depth", strconv.Itoa(int(depth)))
+	}
+	if rule.Empty || rule.Nil || rule.Skip {
+		return invalid("(redact.custom).message.depth without empty, nil or skip", "both")
+	}
+	em := field.Type().Embed()
+	if em == nil && field.Type().Element() != nil {
+		em = field.Type().Element().Embed()
+	}
+	if em != nil && em.File().Name() != field.File().Name() {
+		return invalid("(redact.custom).message.depth of a message of the same file",
+			fmt.Sprintf("%s of %s", em.FullyQualifiedName(), em.File().Name()))
+	}
+	return nil
+}
+
+// validateRegexReplace validates the pattern of the regex_replace rules compiles,
+// failing at generation time instead of at the init of the generated package
+func validateRegexReplace(rules *redact.FieldRules, field pgs.Field) error {
+	rule := rules.GetRegexReplace()
+	if rule == nil {
+		return nil
+	}
+	if _, err := regexp.Compile(rule.GetPattern()); err != nil {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "valid (redact.custom).regex_replace.pattern",
+			Got:      err.Error(),
+			Hint:     "the pattern must be a regular expression in RE2 syntax",
+		}
+	}
+	return nil
+}
+
+// validateRoundRules validates the rounding factor of the round and round_to
+// rules against the numeric type of the field, or of its items, and the
+// magnitude rules only apply to the integers
+func validateRoundRules(rules *redact.FieldRules, field pgs.Field, typ pgs.ProtoType) error {
+	invalid := func(expected, got string) error {
+		return ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: expected,
+			Got:      got,
+			Hint:     "the field is truncated to a multiple of the factor",
+		}
+	}
+	switch rule := rules.GetValues().(type) {
+	case *redact.FieldRules_Round:
+		var lo, hi int64
+		switch typ {
+		case pgs.Int32T, pgs.SInt32, pgs.SFixed32:
+			lo, hi = math.MinInt32, math.MaxInt32
+		case pgs.Int64T, pgs.SInt64, pgs.SFixed64:
+			lo, hi = math.MinInt64, math.MaxInt64
+		case pgs.UInt32T, pgs.Fixed32T:
+			lo, hi = 1, math.MaxUint32
+		case pgs.UInt64T, pgs.Fixed64T:
+			lo, hi = 1, math.MaxInt64
+		default:
+			return invalid("integer field for (redact.custom).round", typ.String())
+		}
+		if rule.Round == 0 || rule.Round < lo || rule.Round > hi {
+			return invalid(fmt.Sprintf("nonzero rounding factor of %s", typ), strconv.FormatInt(rule.Round, 10))
+		}
+	case *redact.FieldRules_Magnitude:
+		switch typ {
+		case pgs.Int32T, pgs.SInt32, pgs.SFixed32, pgs.Int64T, pgs.SInt64, pgs.SFixed64,
+			pgs.UInt32T, pgs.Fixed32T, pgs.UInt64T, pgs.Fixed64T:
+		default:
+			return ValidationError{
+				Entity:   field.FullyQualifiedName(),
+				Expected: "integer field for (redact.custom).magnitude",
+				Got:      typ.String(),
+				Hint:     "the field is redacted to the largest power of ten not above its absolute value",
+			}
+		}
+		if !rule.Magnitude {
+			return ValidationError{
+				Entity:   field.FullyQualifiedName(),
+				Expected: "(redact.custom).magnitude = true",
+				Got:      "false",
+				Hint:     "remove the rule to keep the field",
+			}
+		}
+	case *redact.FieldRules_RoundTo:
+		limit := math.MaxFloat64
+		switch typ {
+		case pgs.FloatT:
+			limit = math.MaxFloat32
+		case pgs.DoubleT:
+		default:
+			return invalid("float or double field for (redact.custom).round_to", typ.String())
+		}
+		factor := math.Abs(rule.RoundTo)
+		if factor == 0 || math.IsNaN(factor) || factor > limit || float32(factor) == 0 && typ == pgs.FloatT {
+			return invalid(fmt.Sprintf("nonzero finite rounding factor of %s", typ), strconv.FormatFloat(rule.RoundTo, 'g', -1, 64))
+		}
+	}
+	return nil
+}
+
 // recoverFromPanic recovers from panics and converts them to errors
 func (m *Module) recoverFromPanic(context string) {
 	if r := recover(); r != nil {
@@ -320,6 +697,39 @@ func (m *Module) validateImportPath(path string) error {
 		return fmt.Errorf("import path too long: %d characters", len(path))
 	}
 
+	// the characters allowed by the Go specification in import paths
+	for _, c := range path {
+		if !unicode.IsGraphic(c) || unicode.IsSpace(c) || c == unicode.ReplacementChar ||
+			strings.ContainsRune(invalidImportChars, c) {
+			return ValidationError{
+				Entity:   "import path",
+				Expected: "graphic characters without spaces or " + invalidImportChars,
+				Got:      strconv.Quote(path),
+				Hint:     "check the go_package option of the proto file",
+			}
+		}
+	}
+
+	return nil
+}
+
+// invalidImportChars are the ASCII punctuation characters which are not
+// allowed in Go import paths
+const invalidImportChars = "!\"#$%&'()*,:;<=>?[\\]^`{|}"
+
+// validateBuildTag validates the build tag of the generated files, empty when
+// the files are not constrained
+func (m *Module) validateBuildTag(tag string) error {
+	for _, c := range tag {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
+			return ValidationError{
+				Entity:   "build tag",
+				Expected: "letters, digits, underscores and dots",
+				Got:      tag,
+				Hint:     "use a single build tag, e.g. build_tag=redact",
+			}
+		}
+	}
 	return nil
 }
 
@@ -339,6 +749,23 @@ func (m *Module) validatePackageName(name string) error {
 				Hint:     "package names cannot start with numbers",
 			}
 		}
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			return ValidationError{
+				Entity:   "package name",
+				Expected: "identifier of letters, digits and underscores",
+				Got:      fmt.Sprintf("name with %q: %s", c, name),
+				Hint:     "set a valid package name in the go_package option, e.g. \"example.com/foo-bar;foobar\"",
+			}
+		}
+	}
+
+	if token.IsKeyword(name) {
+		return ValidationError{
+			Entity:   "package name",
+			Expected: "identifier other than a Go keyword",
+			Got:      name,
+			Hint:     "Go keywords cannot be used as package names",
+		}
 	}
 
 	return nil
@@ -5,6 +5,7 @@ import (
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 
 	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
@@ -315,6 +316,31 @@ func TestValidateImportPath(t *testing.T) {
 			path:      string(make([]byte, 1001)),
 			shouldErr: true,
 		},
+		{
+			name:      "valid_with_version_and_tilde",
+			path:      "example.com/~user/project-go_v2.1+incompatible",
+			shouldErr: false,
+		},
+		{
+			name:      "path_with_space",
+			path:      "github.com/user/my project",
+			shouldErr: true,
+		},
+		{
+			name:      "path_with_newline",
+			path:      "github.com/user/project\nfmt",
+			shouldErr: true,
+		},
+		{
+			name:      "path_with_quote",
+			path:      `github.com/user/project"`,
+			shouldErr: true,
+		},
+		{
+			name:      "path_with_control_character",
+			path:      "github.com/user/\x00project",
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -329,6 +355,18 @@ func TestValidateImportPath(t *testing.T) {
 	}
 }
 
+// TestValidateImportPathError tests the invalid import paths are reported with
+// the offending path
+func TestValidateImportPathError(t *testing.T) {
+	m := &Module{ModuleBase: &pgs.ModuleBase{}}
+
+	err := m.validateImportPath("github.com/user/my project")
+	var validationErr ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, `"github.com/user/my project"`, validationErr.Got)
+	assert.Contains(t, err.Error(), "import path")
+}
+
 // TestValidatePackageName tests package name validation
 func TestValidatePackageName(t *testing.T) {
 	m := &Module{ModuleBase: &pgs.ModuleBase{}}
@@ -368,6 +406,31 @@ func TestValidatePackageName(t *testing.T) {
 			pkgName:   "user1",
 			shouldErr: false,
 		},
+		{
+			name:      "keyword_type",
+			pkgName:   "type",
+			shouldErr: true,
+		},
+		{
+			name:      "keyword_func",
+			pkgName:   "func",
+			shouldErr: true,
+		},
+		{
+			name:      "valid_keyword_prefix",
+			pkgName:   "types",
+			shouldErr: false,
+		},
+		{
+			name:      "hyphen",
+			pkgName:   "user-service",
+			shouldErr: true,
+		},
+		{
+			name:      "dot",
+			pkgName:   "user.v1",
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,30 +1,37 @@
 // Code generated by protoc-gen-redact. DO NOT EDIT.
+// versions:
+// 	protoc-gen-redact dev
 // source: examples/tests/message.proto
+// annotations: sha256:9f9e41a4e382b5176c473b872f3a113221bfe554077acd4fd66ad3c5d616f2c0
 
 package tests
 
 import (
-	context "context"
-
 	redact "github.com/menta2k/protoc-gen-redact/v3/redact/v3"
-	grpc "google.golang.org/grpc"
-	codes "google.golang.org/grpc/codes"
-	status "google.golang.org/grpc/status"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
 var (
-	_ grpc.Server
-	_ context.Context
 	_ redact.Redactor
-	_ codes.Code
-	_ status.Status
-	_ emptypb.Empty
-	_ redact.FieldRules
+	_ *emptypb.Empty
+	_ *redact.FieldRules
 )
 
+// TestMessage must implement redact.Redactor, to be redacted by redact.Apply
+var _ redact.Redactor = (*TestMessage)(nil)
+
 // Redact method implementation for TestMessage
+// Redact: bool_value->true, bytes_value->[]byte("redacted-value-value"),
+// double_value->6.4, enum_value->2, fixed32_value->32, fixed64_value->64,
+// float_value->3.2, int32_value->32, int64_value->64,
+// map1_empty->map[int64]string{}, map1_item->items("3"),
+// map1_nested->items("REDACTED"), map2_empty->map[string]*emptypb.Empty{},
+// map2_item_empty->items(&emptypb.Empty{}), map2_item_nil->items(nil),
+// map2_item_skip->skip, map2_nested->nested, message_empty->&TestMessage{},
+// message_nil->nil, message_skip->skip, sfixed32_value->32,
+// sfixed64_value->64, sint32_value->32, sint64_value->64,
+// string_value->"redacted-value-value", uint32_value->32, uint64_value->64
 func (x *TestMessage) Redact() string {
 	if x == nil {
 		return ""
@@ -70,10 +77,10 @@ func (x *TestMessage) Redact() string {
 	x.BoolValue = true
 
 	// Redacting field: StringValue
-	x.StringValue = `redacted-value-value`
+	x.StringValue = "redacted-value-value"
 
 	// Redacting field: BytesValue
-	x.BytesValue = []byte(`redacted-value-value`)
+	x.BytesValue = []byte("redacted-value-value")
 
 	// Redacting field: EnumValue
 	x.EnumValue = 2
@@ -100,12 +107,14 @@ func (x *TestMessage) Redact() string {
 
 	// Redacting field: Map2Nested
 	for k := range x.Map2Nested {
-		redact.Apply(x.Map2Nested[k])
+		if x.Map2Nested[k] != nil {
+			redact.Apply(x.Map2Nested[k])
+		}
 	}
 
 	// Redacting field: Map1Item
 	for k := range x.Map1Item {
-		x.Map1Item[k] = `3`
+		x.Map1Item[k] = "3"
 	}
 
 	// Redacting field: Map2ItemNil
@@ -123,7 +132,37 @@ func (x *TestMessage) Redact() string {
 	return x.String()
 }
 
+// RepeatedM must implement redact.Redactor, to be redacted by redact.Apply
+var _ redact.Redactor = (*RepeatedM)(nil)
+
 // Redact method implementation for RepeatedM
+// Redact: bool_value_empties->[]bool{}, bool_value_nested->items(false),
+// bool_values->items(true), bytes_value_empties->[][]byte{},
+// bytes_value_nested->items(nil),
+// bytes_values->items([]byte("redacted-value-value")),
+// double_value_empties->[]float64{}, double_value_nested->items(0),
+// double_values->items(6.4), enum_value_empties->[]TestEnum{},
+// enum_value_nested->items(0), enum_values->items(2),
+// fixed32_value_empties->[]uint32{}, fixed32_value_nested->items(0),
+// fixed32_values->items(32), fixed64_value_empties->[]uint64{},
+// fixed64_value_nested->items(0), fixed64_values->items(64),
+// float_value_empties->[]float32{}, float_value_nested->items(0),
+// float_values->items(3.2), int32_value_empties->[]int32{},
+// int32_value_nested->items(0), int32_values->items(32),
+// int64_value_empties->[]int64{}, int64_value_nested->items(0),
+// int64_values->items(64), message_empties->items(&TestMessage{}),
+// message_nested->nested, message_nils->items(nil), message_skips->skip,
+// sfixed32_value_empties->[]int32{}, sfixed32_value_nested->items(0),
+// sfixed32_values->items(32), sfixed64_value_empties->[]int64{},
+// sfixed64_value_nested->items(0), sfixed64_values->items(64),
+// sint32_value_empties->[]int32{}, sint32_value_nested->items(0),
+// sint32_values->items(32), sint64_value_empties->[]int64{},
+// sint64_value_nested->items(0), sint64_values->items(64),
+// string_value_empties->[]string{}, string_value_nested->items("REDACTED"),
+// string_values->items("redacted-value-value"),
+// uint32_value_empties->[]uint32{}, uint32_value_nested->items(0),
+// uint32_values->items(32), uint64_value_empties->[]uint64{},
+// uint64_value_nested->items(0), uint64_values->items(64)
 func (x *RepeatedM) Redact() string {
 	if x == nil {
 		return ""
@@ -308,7 +347,7 @@ func (x *RepeatedM) Redact() string {
 
 	// Redacting field: StringValues
 	for k := range x.StringValues {
-		x.StringValues[k] = `redacted-value-value`
+		x.StringValues[k] = "redacted-value-value"
 	}
 
 	// Redacting field: BytesValueEmpties
@@ -321,7 +360,7 @@ func (x *RepeatedM) Redact() string {
 
 	// Redacting field: BytesValues
 	for k := range x.BytesValues {
-		x.BytesValues[k] = []byte(`redacted-value-value`)
+		x.BytesValues[k] = []byte("redacted-value-value")
 	}
 
 	// Redacting field: EnumValueEmpties
@@ -347,7 +386,9 @@ func (x *RepeatedM) Redact() string {
 
 	// Redacting field: MessageNested
 	for k := range x.MessageNested {
-		redact.Apply(x.MessageNested[k])
+		if x.MessageNested[k] != nil {
+			redact.Apply(x.MessageNested[k])
+		}
 	}
 
 	// Redacting field: MessageEmpties
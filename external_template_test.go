@@ -214,3 +214,22 @@ func TestTemplateParameterValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestCheckTemplate tests the templates are checked against the ProtoFileData
+// fields
+func TestCheckTemplate(t *testing.T) {
+	funcs := map[string]interface{}{
+		"package": func(pgs.Node) string { return "" },
+		"name":    func(pgs.Node) string { return "" },
+	}
+
+	embedded, err := template.New("redact").Funcs(funcs).Parse(redactTpl)
+	require.NoError(t, err)
+	assert.NoError(t, checkTemplate(embedded), "The embedded template should match")
+
+	unknown, err := template.New("redact").Funcs(funcs).Parse("package {{ .PackageName }}")
+	require.NoError(t, err)
+	err = checkTemplate(unknown)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match the ProtoFileData fields")
+}
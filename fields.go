@@ -2,16 +2,59 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
+	"google.golang.org/protobuf/runtime/protoimpl"
+	"google.golang.org/protobuf/types/descriptorpb"
 
 	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
 )
 
+// explicitPresence checks if the singular scalar field of a proto2 or editions
+// file tracks its presence, hence is a pointer in the generated Go code, like
+// the proto3 optional fields. The presence of the editions fields is resolved
+// from their features rather than from the syntax.
+func explicitPresence(field pgs.Field) bool {
+	typ := field.Type()
+	if field.InRealOneOf() || typ.IsRepeated() || typ.IsMap() || typ.IsEmbed() {
+		return false
+	}
+	switch field.File().Descriptor().GetSyntax() {
+	case "proto2":
+		return field.Descriptor().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	case "editions":
+		return fieldPresence(field) != descriptorpb.FeatureSet_IMPLICIT
+	}
+	return false
+}
+
+// hasPresence checks if the singular scalar field tracks its presence, either
+// a proto3 optional field (synthetic oneof) or an explicit presence field
+func hasPresence(field pgs.Field) bool {
+	return field.InOneOf() && field.OneOf().IsSynthetic() || explicitPresence(field)
+}
+
+// fieldPresence resolves the field presence feature of the editions field,
+// inherited from its file, EXPLICIT by default
+func fieldPresence(field pgs.Field) descriptorpb.FeatureSet_FieldPresence {
+	unknown := descriptorpb.FeatureSet_FIELD_PRESENCE_UNKNOWN
+	if presence := field.Descriptor().GetOptions().GetFeatures().GetFieldPresence(); presence != unknown {
+		return presence
+	}
+	if presence := field.File().Descriptor().GetOptions().GetFeatures().GetFieldPresence(); presence != unknown {
+		return presence
+	}
+	return descriptorpb.FeatureSet_EXPLICIT
+}
+
 // processFields extracts each fields information
 func (m *Module) processFields(
 	field pgs.Field,
 	nameWithAlias func(n pgs.Entity) string,
+	allFields bool,
 ) *FieldData {
 	// Validate field before processing
 	if err := m.validateField(field); err != nil {
@@ -29,16 +72,26 @@ func (m *Module) processFields(
 	// In proto3, fields with explicit `optional` keyword become pointers
 	// These fields are implemented as synthetic oneofs (proto3_optional)
 	// Exception: bytes fields are always []byte, never *[]byte, even with explicit optional
-	hasExplicitOptional := field.InOneOf() && field.OneOf().IsSynthetic()
+	hasExplicitOptional := hasPresence(field)
 	isOptional := hasExplicitOptional && typ.ProtoType() != pgs.BytesT
 
 	flData := &FieldData{
-		Name:        m.ctx.Name(field).String(),
-		IsMap:       typ.IsMap(),
-		IsRepeated:  typ.IsRepeated(),
-		IsMessage:   typ.IsEmbed(),
-		IsOptional:  isOptional,
-		FieldGoType: goTypeName(typ.ProtoType()),
+		Name:            m.ctx.Name(field).String(),
+		IsMap:           typ.IsMap(),
+		IsRepeated:      typ.IsRepeated(),
+		IsMessage:       typ.IsEmbed(),
+		IsOptional:      isOptional,
+		InOneOf:         field.InRealOneOf(),
+		IsOptionalBytes: hasExplicitOptional && typ.ProtoType() == pgs.BytesT,
+		FieldGoType:     goTypeName(typ.ProtoType()),
+	}
+	if enum := typ.Enum(); enum != nil {
+		// the redaction values of the optional enum fields are converted
+		flData.FieldGoType = nameWithAlias(enum)
+	}
+	if flData.InOneOf {
+		flData.OneOf = m.ctx.Name(field.OneOf()).String()
+		flData.OneOfWrapper = m.ctx.OneofOption(field).String()
 	}
 	em := typ.Embed()
 	if em == nil {
@@ -50,16 +103,55 @@ func (m *Module) processFields(
 	if em != nil {
 		flData.EmbedMessageName = m.ctx.Name(em).String()
 		flData.EmbedMessageNameWithAlias = nameWithAlias(em)
+		flData.EmptyFactory = m.emptyFactoryCalls[em.FullyQualifiedName()]
+		flData.Redactor = m.redactor(field.File(), em)
+	}
+
+	m.must(field.Extension(redact.E_Allow, &flData.Allow))
+	// the fields listed by the except option of the message are kept as the
+	// allowed fields
+	if allFields && m.exceptFields(field.Message())[field.Name().String()] {
+		flData.Allow = true
+	}
+	// the gate of the message is kept, the redacted messages remain flagged
+	if gate := m.gateField(field.Message()); gate != nil && gate.Name() == field.Name() {
+		flData.Allow = true
 	}
 
-	_redact, fieldRules := false, &redact.FieldRules{}
-	// ok := m.must(field.Extension(redact.E_Redact, &_redact))
+	// the fields of the all_fields messages, and the deprecated fields with
+	// redact_deprecated, are redacted by default
+	_redact, fieldRules := allFields || m.redactsDeprecated(field), &redact.FieldRules{}
 	ok := m.must(field.Extension(redact.E_Value, &fieldRules))
 
+	// the fields embedding an auto_nested message are nested-redacted, as with
+	// an explicit rule
+	if !ok && !_redact && !flData.Allow && em != nil && m.autoNested(em) {
+		fieldRules, ok = autoNestedRules(typ), true
+	}
+
 	// safe field: no option is defined
-	if !ok {
+	if !ok && !_redact {
+		return flData
+	}
+
+	// explicitly safe field: never redacted, hence no rule can be applied
+	if flData.Allow && !ok {
+		return flData
+	}
+	if flData.Allow {
+		m.Fail(ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "either (redact.v3.allow) or a (redact.v3.value) rule",
+			Got:      "both",
+			Hint:     "allowed fields are never redacted, remove one of the options",
+		})
 		return flData
 	}
+	if !ok {
+		// no rules of its own, the defaults of the all_fields message, or of
+		// the deprecated field, are used
+		fieldRules = nil
+	}
 
 	// Validate rules before processing
 	if err := m.validateRules(fieldRules, field); err != nil {
@@ -76,24 +168,39 @@ func (m *Module) processFields(
 		}
 		// default rules will be used
 		flData.Redact = true
-		flData.RedactionValue = RedactionDefaults(
+		if typ.IsEmbed() {
+			// the message is redacted by its own rules, or by value for the
+			// well-known types, rather than replaced by a default
+			m.nestedEmbedCall(flData, field, em, nameWithAlias)
+			return flData
+		}
+		flData.RedactionValue = m.redactionDefault(
 			typ.ProtoType(),
 			typ.IsRepeated() || typ.IsMap(),
 		)
-		if typ.IsEmbed() {
-			flData.NestedEmbedCall = true
+		if m.respectValidate {
+			m.respectConstraints(field, flData)
 		}
 		return flData
 	}
 
 	// custom field rules are defined, hence prefill defaults
 	flData.Redact = true
-	flData.RedactionValue = RedactionDefaults(
+	flData.RedactionValue = m.redactionDefault(
 		typ.ProtoType(),
 		typ.IsRepeated() || typ.IsMap(),
 	)
-	// custom values
-	m.redactedCustomValue(flData, field, fieldRules)
+	// custom values, the rule information is extracted once per field
+	m.redactedCustomValue(flData, field, fieldRules, m.RuleInformation(fieldRules))
+	if fieldRules.GetEnumLast() || fieldRules.GetElement().GetItem().GetEnumLast() {
+		m.enumLastValue(flData, field, nameWithAlias)
+	}
+	if flData.NestedEmbedCall {
+		m.nestedEmbedCall(flData, field, em, nameWithAlias)
+	}
+	if m.respectValidate {
+		m.respectConstraints(field, flData)
+	}
 	return flData
 }
 
@@ -101,6 +208,7 @@ func (m *Module) redactedCustomValue(
 	flData *FieldData,
 	field pgs.Field,
 	fieldRules *redact.FieldRules,
+	info RuleInfo,
 ) {
 	// Validate inputs
 	if flData == nil {
@@ -118,11 +226,13 @@ func (m *Module) redactedCustomValue(
 		return
 	}
 
-	// extract rule information
-	info := m.RuleInformation(fieldRules)
+	if name := fieldRules.GetCopyFrom(); name != "" {
+		m.copyFromValue(flData, field, name)
+		return
+	}
 
 	// match field types & rule types with better error message
-	if info.ProtoType != 0 && info.ProtoType != typ.ProtoType() {
+	if info.ProtoType != 0 && info.ProtoType != rulesProtoType(typ.ProtoType()) {
 		err := m.validateTypeMatch(field, info.ProtoType, info.ProtoLabel)
 		if err != nil {
 			m.Fail(err)
@@ -142,7 +252,13 @@ func (m *Module) redactedCustomValue(
 	}
 	if info.ProtoType != pgs.MessageT && info.ProtoLabel != pgs.Repeated {
 		// simple type fields
-		flData.RedactionValue = fmt.Sprintf("%v", info.RedactionValue)
+		if !roundValue(flData, fieldRules, info) {
+			flData.RedactionValue = info.Literal
+		}
+		flData.PANMask = fieldRules.GetPanMask()
+		flData.BytesZeroFill = fieldRules.GetZeroFill()
+		flData.Magnitude = fieldRules.GetMagnitude()
+		m.regexReplace(flData, field, fieldRules)
 		return
 	}
 
@@ -152,23 +268,7 @@ func (m *Module) redactedCustomValue(
 		if !ok {
 			m.Failf("Invalid message rule type for field %s", field.Name())
 		}
-		rule := messageRule.Message
-		// default value is nil
-		flData.RedactionValue = `nil`
-		if rule.Empty {
-			// flData.RedactionValue = m.ctx.Type(field).String() + "{}"
-			flData.RedactionValue = fmt.Sprintf("&%s{}", flData.EmbedMessageNameWithAlias)
-			return
-		}
-		if rule.Nil {
-			flData.RedactionValue = "nil"
-			return
-		}
-		if rule.Skip {
-			flData.EmbedSkip = true
-			return
-		}
-		flData.NestedEmbedCall = true
+		m.messageRuleValue(flData, messageRule.Message)
 		return
 	}
 
@@ -178,6 +278,11 @@ func (m *Module) redactedCustomValue(
 		m.Failf("Invalid element rule type for field %s", field.Name())
 	}
 	rule := elementRule.Element
+	// the rule information of the items, shared by collapse and the item rules
+	var item RuleInfo
+	if rules := rule.Item; rules != nil && rules.Values != nil {
+		item = m.RuleInformation(rules)
+	}
 	if rule.Empty {
 		if flData.EmbedMessageNameWithAlias == "" {
 			flData.RedactionValue = m.ctx.Type(field).String() + "{}"
@@ -187,67 +292,456 @@ func (m *Module) redactedCustomValue(
 			flData.RedactionValue = fmt.Sprintf("[]*%s{}", flData.EmbedMessageNameWithAlias)
 			return
 		}
-		// map type
+		// map type, the keys are scalars, e.g. the sint32 and fixed64 keys are
+		// rendered as the int32 and uint64 Go types
 		key := m.ctx.Type(field).Key().String()
 		flData.RedactionValue = fmt.Sprintf("map[%s]*%s{}", key, flData.EmbedMessageNameWithAlias)
 		return
 	}
+	if rule.ClearElements {
+		// iterate over all items and replace them with empty values
+		flData.Iterate = true
+		flData.RedactionValue = zeroValue(typ.Element().ProtoType())
+		if flData.EmbedMessageNameWithAlias != "" {
+			flData.RedactionValue = m.emptyMessageValue(flData)
+		}
+		return
+	}
+	if rule.Collapse {
+		// replace the non-empty lists by a single sentinel item
+		flData.Collapse = true
+		literal := m.redactionDefault(typ.Element().ProtoType(), false)
+		if rules := rule.Item; rules != nil && rules.Values != nil {
+			if item.ProtoType != typ.Element().ProtoType() {
+				m.failWithInvalidType(field)
+				return // unreachable
+			}
+			literal = item.Literal
+		}
+		flData.RedactionValue = fmt.Sprintf("%s{%s}", m.ctx.Type(field), literal)
+		return
+	}
 	if rule.Nested {
 		// iterate over all items and redact with defaults
 		flData.Iterate = true
-		flData.RedactionValue = RedactionDefaults(typ.Element().ProtoType(), false)
 		if typ.Element().IsEmbed() {
 			flData.NestedEmbedCall = true
+			return
 		}
+		flData.RedactionValue = m.redactionDefault(typ.Element().ProtoType(), false)
 		return
 	}
+	if key := rule.GetKey(); key != nil {
+		// rebuild the map with the redacted keys
+		flData.KeyRedact = true
+		flData.KeyRedactionValue = m.RuleInformation(key).Literal
+		flData.KeyPANMask = key.GetPanMask()
+	}
 	if rules := rule.Item; rules != nil && rules.Values != nil {
 		if rules.GetElement() != nil {
 			// Use the improved error message
 			m.failWithNestedError(field)
 			return
 		}
-		info := m.RuleInformation(rules)
-		// match types
-		if info.ProtoType != typ.Element().ProtoType() {
+		// match types, the rounding rules match any numeric type
+		if item.ProtoType != 0 && item.ProtoType != typ.Element().ProtoType() {
 			m.failWithInvalidType(field)
 			return // unreachable
 		}
 		// default value is nil
 		flData.Iterate = true
 		flData.RedactionValue = "nil"
-		if info.ProtoType != pgs.MessageT {
+		if item.ProtoType != pgs.MessageT {
 			// simple type fields
-			flData.RedactionValue = fmt.Sprintf("%v", info.RedactionValue)
+			if !roundValue(flData, rules, item) {
+				flData.RedactionValue = item.Literal
+			}
+			flData.PANMask = rules.GetPanMask()
+			flData.BytesZeroFill = rules.GetZeroFill()
+			flData.Magnitude = rules.GetMagnitude()
+			m.regexReplace(flData, field, rules)
 		} else {
 			// message type embedded field
 			messageRule, ok := rules.Values.(*redact.FieldRules_Message)
 			if !ok {
 				m.Failf("Invalid message rule type for field %s", field.Name())
 			}
-			rule := messageRule.Message
-			flData.RedactionValue = `nil`
-			if rule.Empty {
-				// flData.RedactionValue = m.ctx.Type(field).String() + "{}"
-				flData.RedactionValue = fmt.Sprintf("&%s{}", flData.EmbedMessageNameWithAlias)
-				return
+			m.messageRuleValue(flData, messageRule.Message)
+		}
+	}
+}
+
+// regexReplace sets the package-level var of the compiled pattern of the
+// regex_replace rules, and the replacement of the matches
+func (m *Module) regexReplace(flData *FieldData, field pgs.Field, rules *redact.FieldRules) {
+	rule := rules.GetRegexReplace()
+	if rule == nil {
+		return
+	}
+	flData.RegexVar = "redactRegexp" + m.ctx.Name(field.Message()).String() + "_" + flData.Name
+	flData.RegexPattern = strconv.Quote(rule.GetPattern())
+	flData.RegexReplacement = strconv.Quote(rule.GetReplacement())
+}
+
+// exceptFields returns the fields kept by the except option of the message,
+// by their proto names, nil without the option
+func (m *Module) exceptFields(msg pgs.Message) map[string]bool {
+	except := ""
+	m.must(msg.Extension(redact.E_Except, &except))
+	var set map[string]bool
+	for _, name := range redact.ExceptFields(except) {
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[name] = true
+	}
+	return set
+}
+
+// gateField returns the bool field gating the redaction of the message with
+// gated_by, nil without the option or if the field does not exist
+func (m *Module) gateField(msg pgs.Message) pgs.Field {
+	gatedBy := ""
+	m.must(msg.Extension(redact.E_GatedBy, &gatedBy))
+	if gatedBy == "" {
+		return nil
+	}
+	for _, field := range msg.Fields() {
+		if field.Name().String() == gatedBy {
+			return field
+		}
+	}
+	return nil
+}
+
+// autoNested checks if the fields embedding the message are nested-redacted
+// by default
+func (m *Module) autoNested(em pgs.Message) bool {
+	autoNested := false
+	m.must(em.Extension(redact.E_AutoNested, &autoNested))
+	return autoNested
+}
+
+// autoNestedRules returns the implicit rules of the fields embedding an
+// auto_nested message: the nested redaction of the message, or of the items
+func autoNestedRules(typ pgs.FieldType) *redact.FieldRules {
+	if typ.IsRepeated() || typ.IsMap() {
+		return &redact.FieldRules{Values: &redact.FieldRules_Element{Element: &redact.ElementRules{Nested: true}}}
+	}
+	return &redact.FieldRules{Values: &redact.FieldRules_Message{Message: &redact.MessageRules{}}}
+}
+
+// enumLastValue sets the redaction value of the enum field, or of its items,
+// to the highest-numbered value of the enum
+func (m *Module) enumLastValue(flData *FieldData, field pgs.Field, nameWithAlias func(n pgs.Entity) string) {
+	enum := field.Type().Enum()
+	if enum == nil && field.Type().Element() != nil {
+		enum = field.Type().Element().Enum()
+	}
+	if enum == nil || len(enum.Values()) == 0 {
+		m.failWithInvalidType(field)
+		return
+	}
+	last := enum.Values()[0]
+	for _, val := range enum.Values() {
+		if val.Value() > last.Value() {
+			last = val
+		}
+	}
+	flData.RedactionValue = nameWithAlias(last)
+}
+
+// roundValue sets the rounding factor of the round and round_to rules, it
+// returns false for the other rules
+func roundValue(flData *FieldData, rules *redact.FieldRules, info RuleInfo) bool {
+	switch rules.GetValues().(type) {
+	case *redact.FieldRules_Round:
+		flData.RoundTo = info.Literal
+	case *redact.FieldRules_RoundTo:
+		flData.RoundTo = info.Literal
+		flData.RoundFloat = true
+	default:
+		return false
+	}
+	return true
+}
+
+// copyFromValue sets the field to be copied from its sibling, the fields must
+// be singular scalars of the same Go type, outside of the oneofs
+func (m *Module) copyFromValue(flData *FieldData, field pgs.Field, name string) {
+	invalid := func(expected, got, hint string) {
+		m.Fail(ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: expected,
+			Got:      got,
+			Hint:     hint,
+		})
+	}
+	var src pgs.Field
+	for _, sibling := range field.Message().Fields() {
+		if sibling.Name().String() == name {
+			src = sibling
+		}
+	}
+	switch {
+	case src == nil:
+		invalid("a field of "+field.Message().FullyQualifiedName(), fmt.Sprintf("(redact.v3.value).copy_from = %q", name),
+			"copy_from takes the proto name of a sibling field")
+		return
+	case src == field:
+		invalid("a sibling field", "the field itself", "remove (redact.v3.value).copy_from")
+		return
+	}
+	typ, srcTyp := field.Type(), src.Type()
+	for _, f := range []pgs.Field{field, src} {
+		if f.Type().IsRepeated() || f.Type().IsMap() || f.Type().IsEmbed() || f.InRealOneOf() {
+			invalid("singular scalar fields outside of the oneofs", f.FullyQualifiedName(),
+				"only the scalar and enum fields can be copied")
+			return
+		}
+	}
+	assignable := typ.ProtoType() == srcTyp.ProtoType() &&
+		(typ.ProtoType() == pgs.BytesT || hasPresence(field) == hasPresence(src))
+	if typ.IsEnum() {
+		assignable = assignable && typ.Enum().FullyQualifiedName() == srcTyp.Enum().FullyQualifiedName()
+	}
+	if !assignable {
+		invalid(fmt.Sprintf("a sibling field assignable to %s", field.Name()), src.FullyQualifiedName(),
+			"the fields must have the same type and presence")
+		return
+	}
+	flData.CopyFrom = m.ctx.Name(src).String()
+}
+
+// messageRuleValue applies the message rules of a singular message field, or
+// of the message items of a repeated/map field
+func (m *Module) messageRuleValue(flData *FieldData, rule *redact.MessageRules) {
+	// default value is nil
+	flData.RedactionValue = `nil`
+	if rule.Empty {
+		flData.RedactionValue = m.emptyMessageValue(flData)
+		// the values of the empty factories may not be empty, they are kept
+		flData.ResetEmpty = m.resetEmpty && flData.EmptyFactory == "" && !flData.IsRepeated && !flData.IsMap
+		return
+	}
+	if rule.Nil {
+		return
+	}
+	if rule.Skip {
+		flData.EmbedSkip = true
+		return
+	}
+	flData.NestedEmbedCall = true
+	flData.Depth = int(rule.Depth)
+}
+
+// emptyMessageValue returns the empty value of the embed message, the result
+// of its empty_factory, a new one or, with shared_empty, the package-level
+// value shared by the fields
+func (m *Module) emptyMessageValue(flData *FieldData) string {
+	if flData.EmptyFactory != "" {
+		return flData.EmptyFactory
+	}
+	if !m.sharedEmpty {
+		return fmt.Sprintf("&%s{}", flData.EmbedMessageNameWithAlias)
+	}
+	flData.SharedEmpty = true
+	return "redactedEmpty" + strings.ReplaceAll(flData.EmbedMessageNameWithAlias, ".", "_")
+}
+
+// emptyFactory references the function returning the empty value of a message
+type emptyFactory struct {
+	msg pgs.Message
+	ref *GoRef
+}
+
+// emptyFactories returns the empty_factory functions of the messages
+// referenced by the file: embedded by the fields of its messages, or returned
+// by the methods of its redacted servers. These are sorted by message.
+func (m *Module) emptyFactories(file pgs.File) []emptyFactory {
+	msgs := map[string]pgs.Message{}
+	for _, msg := range file.AllMessages() {
+		for _, field := range msg.Fields() {
+			em := field.Type().Embed()
+			if ele := field.Type().Element(); em == nil && ele != nil {
+				em = ele.Embed()
 			}
-			if rule.Nil {
-				flData.RedactionValue = "nil"
-				return
+			if em != nil {
+				msgs[em.FullyQualifiedName()] = em
 			}
-			if rule.Skip {
-				flData.EmbedSkip = true
-				return
+		}
+	}
+	if m.generatesServices(file) {
+		for _, srv := range file.Services() {
+			for _, meth := range srv.Methods() {
+				msgs[meth.Output().FullyQualifiedName()] = meth.Output()
 			}
-			flData.NestedEmbedCall = true
 		}
 	}
+
+	list := make([]emptyFactory, 0, len(msgs))
+	for _, msg := range msgs {
+		val := ""
+		m.must(msg.Extension(redact.E_EmptyFactory, &val))
+		if val == "" {
+			continue
+		}
+		ref, err := parseGoRef(val)
+		if err != nil {
+			m.Fail(ValidationError{
+				Entity:   msg.FullyQualifiedName(),
+				Expected: "(redact.v3.empty_factory) formatted as <import-path>.<Name>",
+				Got:      val,
+				Hint:     err.Error(),
+			})
+			return nil
+		}
+		list = append(list, emptyFactory{msg: msg, ref: ref})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].msg.FullyQualifiedName() < list[j].msg.FullyQualifiedName()
+	})
+	return list
+}
+
+// nestedEmbedCall marks the embed message to be redacted by its own Redact()
+// method, well-known types without such method are replaced by a value instead
+// and the redaction of ignored messages, a no-op, is skipped
+func (m *Module) nestedEmbedCall(
+	flData *FieldData,
+	field pgs.Field,
+	em pgs.Message,
+	nameWithAlias func(n pgs.Entity) string,
+) {
+	if value, ok := m.wellKnownDefault(em, nameWithAlias); ok {
+		flData.NestedEmbedCall = false
+		flData.RedactionValue = value
+		return
+	}
+	flData.NestedEmbedCall = true
+	if em == nil {
+		return
+	}
+	ignored := false
+	m.must(em.Extension(redact.E_Ignored, &ignored))
+	if ignored {
+		// ignored wins over the rules of the fields embedding the message, the
+		// call of its no-op Redact() is skipped
+		flData.NestedEmbedCall = false
+		flData.EmbedSkip = true
+		if m.warnNoopNested {
+			m.Logf("Warning: %s calls the redaction of %s which is ignored, its values are kept, "+
+				"consider using (redact.v3.value).message.skip", field.FullyQualifiedName(), em.FullyQualifiedName())
+		}
+		return
+	}
+	// the messages of the file are called with the remaining depth, in the
+	// files limiting it
+	flData.LocalRedactor = em.File().Name() == field.File().Name()
+	if !flData.LocalRedactor && importsRedact(em.File()) {
+		m.importedRedactor(flData, field, em)
+	}
+	if !m.warnNoopNested {
+		return
+	}
+	if !m.redactsFields(em) {
+		m.Logf("Warning: %s calls the redaction of %s which has no redactable fields, "+
+			"consider using (redact.v3.value).message.skip", field.FullyQualifiedName(), em.FullyQualifiedName())
+	}
+}
+
+// importedRedactor marks the message of another file to be asserted to have a
+// generated Redact() method, the messages of skipped files have none. Only the
+// files importing the redaction rules are checked, the Redact() of the other
+// messages, e.g. well-known types, would be a no-op.
+func (m *Module) importedRedactor(flData *FieldData, field pgs.Field, em pgs.Message) {
+	fileSkip := false
+	m.must(em.File().Extension(redact.E_FileSkip, &fileSkip))
+	if fileSkip {
+		m.Fail(ValidationError{
+			Entity:   field.FullyQualifiedName(),
+			Expected: "a message with a generated Redact() method",
+			Got:      fmt.Sprintf("%s of the skipped file %s", em.FullyQualifiedName(), em.File().Name()),
+			Hint:     "remove (redact.v3.file_skip) or use (redact.v3.value).message.skip",
+		})
+		return
+	}
+	flData.ImportedRedactor = true
+}
+
+// redactor returns the function redacting the message in the file generated
+// for file: redact.Apply calling its Redact() method, or with separate_package
+// the Redact<Message>() function generated for the messages of the package.
+// The messages of the other packages, without Redact() methods when generated
+// with separate_package, are redacted with redact.ApplyReflect.
+func (m *Module) redactor(file pgs.File, msg pgs.Message) string {
+	if m.separatePackage == "" {
+		return "redact.Apply"
+	}
+	if msg.File().Name() != file.Name() && !importsRedact(msg.File()) {
+		// as importedRedactor, only the files importing the rules are assumed to
+		// be generated, e.g. not the well-known types
+		return "redact.Apply"
+	}
+	if m.ctx.ImportPath(msg) == m.ctx.ImportPath(file) {
+		return "Redact" + m.ctx.Name(msg).String()
+	}
+	return "redact.ApplyReflect"
+}
+
+// importsRedact checks if the file imports the redaction rules
+func importsRedact(file pgs.File) bool {
+	for _, imp := range file.Imports() {
+		if imp.Package().ProtoName() == "redact.v3" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactsFields checks if the generated Redact() method of the message redacts
+// any of its fields, it is a no-op for ignored, nil or empty messages
+func (m *Module) redactsFields(msg pgs.Message) bool {
+	for _, ext := range []*protoimpl.ExtensionInfo{redact.E_Ignored, redact.E_Nil, redact.E_Empty} {
+		opt := false
+		m.must(msg.Extension(ext, &opt))
+		if opt {
+			return false
+		}
+	}
+	allFields := false
+	m.must(msg.Extension(redact.E_AllFields, &allFields))
+	except := m.exceptFields(msg)
+	allFields = allFields || except != nil
+	for _, field := range msg.Fields() {
+		allow := except[field.Name().String()]
+		if !allow {
+			m.must(field.Extension(redact.E_Allow, &allow))
+		}
+		if (allFields || m.redactsDeprecated(field)) && !allow {
+			return true
+		}
+	}
+	for _, field := range msg.Fields() {
+		rules := &redact.FieldRules{}
+		if m.must(field.Extension(redact.E_Value, &rules)) && rules.GetValues() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// redactsDeprecated checks if the field is redacted to its defaults as a
+// deprecated field, with redact_deprecated
+func (m *Module) redactsDeprecated(field pgs.Field) bool {
+	return m.redactDeprecated && field.Descriptor().GetOptions().GetDeprecated()
 }
 
 // RuleInfo response type for Module.RuleInformation
 type RuleInfo struct {
 	RedactionValue interface{}
+	// Literal is the Go literal of RedactionValue, used in the generated code
+	Literal string
 	// equivalent field type information
 	ProtoType  pgs.ProtoType
 	ProtoLabel pgs.ProtoLabel
@@ -260,51 +754,93 @@ func (m *Module) RuleInformation(rules *redact.FieldRules) (res RuleInfo) {
 	case *redact.FieldRules_Float:
 		res.ProtoType = pgs.FloatT
 		res.RedactionValue = rule.Float
+		res.Literal = strconv.FormatFloat(float64(rule.Float), 'g', -1, 32)
 	case *redact.FieldRules_Double:
 		res.ProtoType = pgs.DoubleT
 		res.RedactionValue = rule.Double
+		res.Literal = strconv.FormatFloat(rule.Double, 'g', -1, 64)
 	case *redact.FieldRules_Int32:
 		res.ProtoType = pgs.Int32T
 		res.RedactionValue = rule.Int32
+		res.Literal = strconv.FormatInt(int64(rule.Int32), 10)
 	case *redact.FieldRules_Int64:
 		res.ProtoType = pgs.Int64T
 		res.RedactionValue = rule.Int64
+		res.Literal = strconv.FormatInt(rule.Int64, 10)
 	case *redact.FieldRules_Uint32:
 		res.ProtoType = pgs.UInt32T
 		res.RedactionValue = rule.Uint32
+		res.Literal = strconv.FormatUint(uint64(rule.Uint32), 10)
 	case *redact.FieldRules_Uint64:
 		res.ProtoType = pgs.UInt64T
 		res.RedactionValue = rule.Uint64
+		res.Literal = strconv.FormatUint(rule.Uint64, 10)
 	case *redact.FieldRules_Sint32:
 		res.ProtoType = pgs.SInt32
 		res.RedactionValue = rule.Sint32
+		res.Literal = strconv.FormatInt(int64(rule.Sint32), 10)
 	case *redact.FieldRules_Sint64:
 		res.ProtoType = pgs.SInt64
 		res.RedactionValue = rule.Sint64
+		res.Literal = strconv.FormatInt(rule.Sint64, 10)
 	case *redact.FieldRules_Fixed32:
 		res.ProtoType = pgs.Fixed32T
 		res.RedactionValue = rule.Fixed32
+		res.Literal = strconv.FormatUint(uint64(rule.Fixed32), 10)
 	case *redact.FieldRules_Fixed64:
 		res.ProtoType = pgs.Fixed64T
 		res.RedactionValue = rule.Fixed64
+		res.Literal = strconv.FormatUint(rule.Fixed64, 10)
 	case *redact.FieldRules_Sfixed32:
 		res.ProtoType = pgs.SFixed32
 		res.RedactionValue = rule.Sfixed32
+		res.Literal = strconv.FormatInt(int64(rule.Sfixed32), 10)
 	case *redact.FieldRules_Sfixed64:
 		res.ProtoType = pgs.SFixed64
 		res.RedactionValue = rule.Sfixed64
+		res.Literal = strconv.FormatInt(rule.Sfixed64, 10)
 	case *redact.FieldRules_Bool:
 		res.ProtoType = pgs.BoolT
 		res.RedactionValue = rule.Bool
+		res.Literal = strconv.FormatBool(rule.Bool)
 	case *redact.FieldRules_String_:
 		res.ProtoType = pgs.StringT
-		res.RedactionValue = fmt.Sprintf("`%v`", rule.String_)
+		res.Literal = strconv.Quote(rule.String_)
+		res.RedactionValue = res.Literal
 	case *redact.FieldRules_Bytes:
 		res.ProtoType = pgs.BytesT
-		res.RedactionValue = fmt.Sprintf("[]byte(`%v`)", string(rule.Bytes))
+		res.Literal = "[]byte(" + strconv.Quote(string(rule.Bytes)) + ")"
+		res.RedactionValue = res.Literal
 	case *redact.FieldRules_Enum:
 		res.ProtoType = pgs.EnumT
 		res.RedactionValue = rule.Enum
+		res.Literal = strconv.FormatInt(int64(rule.Enum), 10)
+	case *redact.FieldRules_PanMask:
+		res.ProtoType = pgs.StringT
+		res.RedactionValue = rule.PanMask
+	case *redact.FieldRules_RegexReplace:
+		res.ProtoType = pgs.StringT
+		res.RedactionValue = rule.RegexReplace
+	case *redact.FieldRules_EnumLast:
+		res.ProtoType = pgs.EnumT
+		res.RedactionValue = rule.EnumLast
+	case *redact.FieldRules_ZeroFill:
+		res.ProtoType = pgs.BytesT
+		res.RedactionValue = rule.ZeroFill
+	case *redact.FieldRules_Round:
+		// any integer type, checked by validateRoundRules
+		res.RedactionValue = rule.Round
+		res.Literal = strconv.FormatInt(rule.Round, 10)
+	case *redact.FieldRules_Magnitude:
+		// any integer type, checked by validateRoundRules
+		res.RedactionValue = rule.Magnitude
+	case *redact.FieldRules_RoundTo:
+		// float or double, checked by validateRoundRules
+		res.RedactionValue = rule.RoundTo
+		res.Literal = strconv.FormatFloat(rule.RoundTo, 'g', -1, 64)
+	case *redact.FieldRules_CopyFrom:
+		// the type of the sibling field, checked by copyFromValue
+		res.RedactionValue = rule.CopyFrom
 	case *redact.FieldRules_Message:
 		res.ProtoType = pgs.MessageT
 		if rule == nil || rule.Message == nil {
@@ -360,3 +896,18 @@ func goTypeName(pt pgs.ProtoType) string {
 		return ""
 	}
 }
+
+// zeroValue returns the Go literal of the zero value of a proto type
+func zeroValue(pt pgs.ProtoType) string {
+	switch pt {
+	case pgs.BoolT:
+		return "false"
+	case pgs.StringT:
+		return `""`
+	case pgs.BytesT, pgs.MessageT:
+		return "nil"
+	default:
+		// numeric and enum types
+		return "0"
+	}
+}
@@ -2,9 +2,14 @@ package main
 
 import (
 	"fmt"
+	"go/token"
+	"sort"
 	"strconv"
+	"strings"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
 )
 
 // importPaths extracts all the imports of the proto files and assign them
@@ -19,29 +24,65 @@ func (m *Module) importPaths(file pgs.File) (path2Alias, alias2Path map[string]s
 		return nil, nil
 	}
 
-	// Initialize standard imports
+	// Initialize standard imports, their aliases are reserved: the imported
+	// packages with the same name, e.g. a proto package named status, are
+	// suffixed by addImport
 	path2Alias = map[string]string{
-		"context":                                           "context",
-		"google.golang.org/grpc":                            "grpc",
-		"google.golang.org/grpc/codes":                      "codes",
-		"google.golang.org/grpc/status":                     "status",
 		"github.com/menta2k/protoc-gen-redact/v3/redact/v3": "redact",
 	}
 	alias2Path = map[string]string{
-		"context": "context",
-		"grpc":    "google.golang.org/grpc",
-		"codes":   "google.golang.org/grpc/codes",
-		"status":  "google.golang.org/grpc/status",
-		"redact":  "github.com/menta2k/protoc-gen-redact/v3/redact/v3",
+		"redact": "github.com/menta2k/protoc-gen-redact/v3/redact/v3",
+	}
+
+	// the redacted server wrappers use the grpc packages
+	if m.generatesServices(file) {
+		for alias, path := range map[string]string{
+			"context": "context",
+			"grpc":    "google.golang.org/grpc",
+			"codes":   "google.golang.org/grpc/codes",
+			"status":  "google.golang.org/grpc/status",
+			// the redacted clients wrap the connections
+			"redactgrpc": "github.com/menta2k/protoc-gen-redact/v3/redact/v3/redactgrpc",
+		} {
+			path2Alias[path] = alias
+			alias2Path[alias] = path
+		}
+	}
+
+	// timestamps are redacted to the epoch, and the proofs recorded with the
+	// current time, using the time package
+	if m.importsTime(file) {
+		path2Alias["time"] = "time"
+		alias2Path["time"] = "time"
+	}
+
+	// the regex_replace rules compile their patterns with the regexp package
+	if m.importsRegexp(file) {
+		path2Alias["regexp"] = "regexp"
+		alias2Path["regexp"] = "regexp"
+	}
+
+	// the redacted clones are printed, or marshalled, as JSON
+	if m.importsProtojson() {
+		path2Alias["google.golang.org/protobuf/proto"] = "proto"
+		alias2Path["proto"] = "google.golang.org/protobuf/proto"
+		path2Alias["google.golang.org/protobuf/encoding/protojson"] = "protojson"
+		alias2Path["protojson"] = "google.golang.org/protobuf/encoding/protojson"
 	}
 
-	self := m.ctx.ImportPath(file).String()
+	self := m.generatedImportPath(file)
 
 	// Validate import path
 	if err := m.validateImportPath(self); err != nil {
 		m.Failf("Invalid file import path: %v", err)
 		return path2Alias, alias2Path
 	}
+
+	// the messages and services are referenced from the sibling package, their
+	// package is imported first, under its own name
+	if m.separatePackage != "" {
+		m.addImport(path2Alias, alias2Path, m.ctx.ImportPath(file).String(), m.ctx.PackageName(file).String())
+	}
 	for _, imp := range file.Imports() {
 		// Validate import
 		if imp == nil {
@@ -66,41 +107,139 @@ func (m *Module) importPaths(file pgs.File) (path2Alias, alias2Path map[string]s
 			continue
 		}
 
-		// Only add imports that contain messages, enums, or services that might be used
-		// Skip imports that only provide annotations or are metadata-only
-		hasUsableTypes := len(imp.AllMessages()) > 0 || len(imp.AllEnums()) > 0 || len(imp.Services()) > 0
-		if !hasUsableTypes {
-			m.Debug(fmt.Sprintf("Skipping import %s: no usable types", path))
+		// Only add imports that can be safely referenced, skip imports that only
+		// provide annotations or services
+		if referenceType(imp) == nil {
+			m.Debug(fmt.Sprintf("Skipping import %s: no referenceable types", path))
 			continue
 		}
 
 		alias := m.ctx.PackageName(imp).String()
 
-		// Validate package name
-		if err := m.validatePackageName(alias); err != nil {
+		// Validate package name, the Go keywords are suffixed by addImport
+		if err := m.validatePackageName(alias); err != nil && !token.IsKeyword(alias) {
 			m.Debug(fmt.Sprintf("Skipping import with invalid package name %s: %v", alias, err))
 			continue
 		}
 
-		_, ok := alias2Path[alias]
-		cnt := 0
-		for ok {
-			cnt++
-			_, ok = alias2Path[alias+strconv.Itoa(cnt)]
+		m.addImport(path2Alias, alias2Path, path, alias)
+	}
+
+	// the context predicate is only used by the redacted servers, it is
+	// imported after the proto packages, keeping their aliases stable
+	if ref := m.ctxPredicate; ref != nil && m.generatesServices(file) && ref.ImportPath != self {
+		if _, ok := path2Alias[ref.ImportPath]; !ok {
+			m.addImport(path2Alias, alias2Path, ref.ImportPath, ref.Alias())
+		}
+	}
+
+	// the empty factories are always referenced, by their signature assertions
+	for _, factory := range m.emptyFactories(file) {
+		ref := factory.ref
+		if _, ok := path2Alias[ref.ImportPath]; !ok && ref.ImportPath != self {
+			m.addImport(path2Alias, alias2Path, ref.ImportPath, ref.Alias())
 		}
-		if cnt > 0 {
-			alias += strconv.Itoa(cnt)
-			m.Debug(fmt.Sprintf("Resolved import alias conflict: %s -> %s", path, alias))
+	}
+
+	// the packages of the default expressions are referenced by their type
+	// checks, even in the files without fields redacted to the defaults
+	for _, imp := range m.defaultExprImports() {
+		if _, ok := path2Alias[imp.Path]; !ok && imp.Path != self {
+			m.addImport(path2Alias, alias2Path, imp.Path, imp.Alias)
 		}
-		path2Alias[path] = alias
-		alias2Path[alias] = path
 	}
 	return
 }
 
+// addImport registers the import path with a unique alias, derived from the
+// given one, the Go keywords are suffixed as they cannot be used as aliases
+func (m *Module) addImport(path2Alias, alias2Path map[string]string, path, alias string) {
+	_, ok := alias2Path[alias]
+	ok = ok || token.IsKeyword(alias)
+	cnt := 0
+	for ok {
+		cnt++
+		_, ok = alias2Path[alias+strconv.Itoa(cnt)]
+	}
+	if cnt > 0 {
+		alias += strconv.Itoa(cnt)
+		m.Debug(fmt.Sprintf("Resolved import alias conflict: %s -> %s", path, alias))
+	}
+	path2Alias[path] = alias
+	alias2Path[alias] = path
+}
+
+// importGroups sorts the imports by path in goimports-style groups: the
+// standard library, the external packages and the local packages, those of
+// local_prefix or by default of the module of the file. Empty groups are
+// omitted.
+func (m *Module) importGroups(file pgs.File, alias2Path map[string]string) [][]*ImportData {
+	local := m.localPrefixes
+	if len(local) == 0 {
+		local = []string{modulePrefix(m.ctx.ImportPath(file).String())}
+	}
+
+	groups := make([][]*ImportData, 3)
+	for alias, path := range alias2Path {
+		group := 1
+		switch {
+		case hasPathPrefix(path, local):
+			group = 2
+		case isStdImport(path):
+			group = 0
+		}
+		groups[group] = append(groups[group], &ImportData{Alias: alias, Path: path})
+	}
+
+	list := make([][]*ImportData, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].Path < group[j].Path })
+		list = append(list, group)
+	}
+	return list
+}
+
+// isStdImport checks if the import path is a package of the standard library,
+// whose first element, unlike the hosts of the other packages, has no dot
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// modulePrefix guesses the module of the import path from its shape: the host
+// with the next two elements, e.g. github.com/acme/api, or the first element
+// of the paths without host
+func modulePrefix(path string) string {
+	elems := strings.Split(path, "/")
+	if !strings.Contains(elems[0], ".") {
+		return elems[0]
+	}
+	if len(elems) > 3 {
+		elems = elems[:3]
+	}
+	return strings.Join(elems, "/")
+}
+
+// hasPathPrefix checks if the import path is one of the prefixes, or a package
+// nested in one of them
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix != "" && (path == prefix || strings.HasPrefix(path, prefix+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
 // references lists all the import-references from different proto packages
-// to suppress any unused import errors
-func (m *Module) references(file pgs.File, nameWithAlias func(n pgs.Entity) string) []string {
+// to suppress any unused import errors. Only the packages imported by
+// importPaths are referenced, once each by a type of the first of their
+// imported files, so that no reference dangles on a skipped package.
+func (m *Module) references(file pgs.File, path2Alias map[string]string, nameWithAlias func(n pgs.Entity) string) []string {
 	// Add panic recovery
 	defer m.recoverFromPanic("processing import references")
 
@@ -113,15 +252,37 @@ func (m *Module) references(file pgs.File, nameWithAlias func(n pgs.Entity) stri
 	imports := file.Imports()
 	list := make([]string, 0, len(imports)+5)
 
-	// Add standard references
-	list = append(list, "grpc.Server",
-		"context.Context",
-		"redact.Redactor",
-		"codes.Code",
-		"status.Status",
-	)
+	// Add standard references, the grpc packages are only imported for the
+	// redacted server wrappers
+	if m.generatesServices(file) {
+		list = append(list, "grpc.Server",
+			"context.Context",
+			"redact.Redactor",
+			"codes.Code",
+			"status.Status",
+			"redactgrpc.RedactFunc",
+		)
+	} else {
+		list = append(list, "redact.Redactor")
+	}
+	if m.importsTime(file) {
+		list = append(list, "time.Time")
+	}
+	if m.importsRegexp(file) {
+		list = append(list, "*regexp.Regexp")
+	}
+	if m.importsProtojson() {
+		list = append(list, "proto.Message", "protojson.MarshalOptions")
+	}
+
+	// the package of the messages is referenced as the imported ones, with
+	// separate_package
+	if m.separatePackage != "" {
+		imports = append([]pgs.File{file}, imports...)
+	}
 
-	self := m.ctx.ImportPath(file)
+	self := m.generatedImportPath(file)
+	referenced := make(map[string]bool, len(imports))
 	for _, imp := range imports {
 		// Validate import
 		if imp == nil {
@@ -129,43 +290,98 @@ func (m *Module) references(file pgs.File, nameWithAlias func(n pgs.Entity) stri
 			continue
 		}
 
-		if m.ctx.ImportPath(imp) == self {
+		// the packages skipped by importPaths have no alias, and the packages
+		// of several imported files, e.g. through a chain of imports, are
+		// referenced once
+		path := m.ctx.ImportPath(imp).String()
+		if _, ok := path2Alias[path]; !ok || path == self || referenced[path] {
 			continue
 		}
 
-		// Only reference imports that have usable types
+		// Only reference imports that have referenceable types
 		// This matches the filter in importPaths()
-		hasUsableTypes := len(imp.AllMessages()) > 0 || len(imp.AllEnums()) > 0 || len(imp.Services()) > 0
-		if !hasUsableTypes {
-			continue
+		switch ref := referenceType(imp).(type) {
+		case pgs.Message:
+			list = append(list, "*"+nameWithAlias(ref))
+			referenced[path] = true
+		case pgs.Enum:
+			list = append(list, nameWithAlias(ref))
+			referenced[path] = true
 		}
+	}
 
-		// messages
-		msgL := imp.AllMessages()
-		if len(msgL) > 0 {
-			if msgL[0] != nil {
-				list = append(list, nameWithAlias(msgL[0]))
-			}
-			continue
-		}
-		// or enums
-		enmL := imp.AllEnums()
-		if len(enmL) > 0 {
-			if enmL[0] != nil {
-				list = append(list, nameWithAlias(enmL[0]))
+	m.Debug(fmt.Sprintf("Generated %d import references", len(list)))
+	return list
+}
+
+// generatedImportPath returns the import path of the package of the generated
+// file, the sibling package of the messages with separate_package
+func (m *Module) generatedImportPath(file pgs.File) string {
+	path := m.ctx.ImportPath(file).String()
+	if m.separatePackage != "" {
+		path += "/" + m.separatePackage
+	}
+	return path
+}
+
+// generatesServices checks if the redacted server wrappers of the services of
+// the file are generated, the files without services or with messages_only do
+// not depend on the grpc packages
+func (m *Module) generatesServices(file pgs.File) bool {
+	return !m.messagesOnly && len(file.Services()) > 0
+}
+
+// importsTime checks if the generated file uses the time package, to redact
+// timestamps or to record the proofs of redaction
+func (m *Module) importsTime(file pgs.File) bool {
+	return m.proof || importsTimestamp(file)
+}
+
+// importsProtojson checks if the generated file clones the messages to print
+// them, or marshal them, with the protojson package
+func (m *Module) importsProtojson() bool {
+	return m.stringer || m.redactedJSON
+}
+
+// importsRegexp checks if the generated file uses the regexp package, for the
+// regex_replace rules of the fields, or of their items
+func (m *Module) importsRegexp(file pgs.File) bool {
+	for _, msg := range file.AllMessages() {
+		for _, field := range msg.Fields() {
+			var rules redact.FieldRules
+			if !m.must(field.Extension(redact.E_Value, &rules)) {
+				continue
 			}
-			continue
-		}
-		// or services
-		srvL := imp.Services()
-		if len(srvL) > 0 {
-			if srvL[0] != nil {
-				list = append(list, nameWithAlias(srvL[0]))
+			if rules.GetRegexReplace() != nil || rules.GetElement().GetItem().GetRegexReplace() != nil {
+				return true
 			}
-			continue
 		}
 	}
+	return false
+}
 
-	m.Debug(fmt.Sprintf("Generated %d import references", len(list)))
+// defaultExprImports lists the packages referenced by the default expressions,
+// sorted by path, keeping their aliases stable
+func (m *Module) defaultExprImports() []*ImportData {
+	var list []*ImportData
+	for _, expr := range m.defaultExprs {
+		for alias, path := range expr.Imports {
+			list = append(list, &ImportData{Alias: alias, Path: path})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
 	return list
 }
+
+// referenceType returns the type of the imported file used to reference its
+// package: the first top-level message, or enum. Only the types generated by
+// protoc-gen-go are used, hence service only files have no reference type.
+func referenceType(imp pgs.File) pgs.Entity {
+	if msgs := imp.Messages(); len(msgs) > 0 && msgs[0] != nil {
+		return msgs[0]
+	}
+	if enums := imp.Enums(); len(enums) > 0 && enums[0] != nil {
+		return enums[0]
+	}
+	return nil
+}
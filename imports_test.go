@@ -1,9 +1,14 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestImportAliasGeneration tests the generation of unique aliases for imports
@@ -76,7 +81,7 @@ func TestImportAliasGeneration(t *testing.T) {
 	}
 }
 
-// TestStandardImports tests that standard imports are always included
+// TestStandardImports tests the standard imports of the files with services
 func TestStandardImports(t *testing.T) {
 	standardImports := map[string]string{
 		"context": "context",
@@ -96,7 +101,8 @@ func TestStandardImports(t *testing.T) {
 	assert.Equal(t, 5, len(standardImports), "Should have 5 standard imports")
 }
 
-// TestStandardReferences tests that standard references are included
+// TestStandardReferences tests the standard references of the files with
+// services
 func TestStandardReferences(t *testing.T) {
 	standardRefs := []string{
 		"grpc.Server",
@@ -113,6 +119,77 @@ func TestStandardReferences(t *testing.T) {
 	}
 }
 
+// TestServiceFreeImports tests the files without services do not import the
+// grpc packages
+func TestServiceFreeImports(t *testing.T) {
+	file := syntheticFile(t, 1)
+	m := syntheticModule(1)
+
+	path2Alias, alias2Path := m.importPaths(file)
+	assert.Equal(t, map[string]string{"redact": "github.com/menta2k/protoc-gen-redact/v3/redact/v3"}, alias2Path)
+	assert.NotContains(t, path2Alias, "google.golang.org/grpc")
+
+	refs := m.references(file, path2Alias, func(n pgs.Entity) string { return m.ctx.Name(n).String() })
+	assert.Contains(t, refs, "redact.Redactor")
+	assert.NotContains(t, refs, "grpc.Server")
+	assert.NotContains(t, refs, "context.Context")
+}
+
+// TestReferencesSkippedImports tests only the packages imported by importPaths
+// are referenced
+func TestReferencesSkippedImports(t *testing.T) {
+	file := syntheticFile(t, 1)
+	m := syntheticModule(1)
+	name := func(n pgs.Entity) string { return m.ctx.Name(n).String() }
+
+	path2Alias, _ := m.importPaths(file)
+	assert.Contains(t, m.references(file, path2Alias, name), "*FieldRules")
+	assert.NotContains(t, m.references(file, map[string]string{}, name), "*FieldRules",
+		"Should not reference the packages skipped by importPaths")
+}
+
+// TestImportGroups tests the imports are sorted in goimports-style groups
+func TestImportGroups(t *testing.T) {
+	file := syntheticFile(t, 1)
+	m := syntheticModule(1)
+	m.localPrefixes = []string{"github.com/acme/api/"}
+
+	groups := m.importGroups(file, map[string]string{
+		"time":    "time",
+		"context": "context",
+		"redact":  "github.com/menta2k/protoc-gen-redact/v3/redact/v3",
+		"grpc":    "google.golang.org/grpc",
+		"user":    "github.com/acme/api/user",
+		"api":     "github.com/acme/api",
+		"apiv2":   "github.com/acme/apiv2",
+	})
+	paths := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		list := make([]string, 0, len(group))
+		for _, imp := range group {
+			list = append(list, imp.Path)
+		}
+		paths = append(paths, list)
+	}
+	assert.Equal(t, [][]string{
+		{"context", "time"},
+		{"github.com/acme/apiv2", "github.com/menta2k/protoc-gen-redact/v3/redact/v3", "google.golang.org/grpc"},
+		{"github.com/acme/api", "github.com/acme/api/user"},
+	}, paths)
+
+	groups = m.importGroups(file, map[string]string{"redact": "github.com/menta2k/protoc-gen-redact/v3/redact/v3"})
+	require.Len(t, groups, 1, "Should omit the empty groups")
+	assert.Equal(t, &ImportData{Alias: "redact", Path: "github.com/menta2k/protoc-gen-redact/v3/redact/v3"}, groups[0][0])
+
+	for path, want := range map[string]string{
+		"github.com/acme/api/user/v1": "github.com/acme/api",
+		"example.com/user":            "example.com/user",
+		"acme/user":                   "acme",
+	} {
+		assert.Equal(t, want, modulePrefix(path), "Module of %s", path)
+	}
+}
+
 // TestImportPathHandling tests various import path scenarios
 func TestImportPathHandling(t *testing.T) {
 	tests := []struct {
@@ -257,6 +334,24 @@ func TestImportConflictResolution(t *testing.T) {
 	}
 }
 
+// TestAddImportKeyword tests the aliases equal to a Go keyword are suffixed
+func TestAddImportKeyword(t *testing.T) {
+	m := syntheticModule(1)
+	path2Alias := map[string]string{"example.com/type1": "type1"}
+	alias2Path := map[string]string{"type1": "example.com/type1"}
+
+	m.addImport(path2Alias, alias2Path, "example.com/type", "type")
+	m.addImport(path2Alias, alias2Path, "example.com/func", "func")
+	m.addImport(path2Alias, alias2Path, "example.com/types", "types")
+
+	assert.Equal(t, "type2", path2Alias["example.com/type"], "Should skip the keyword and the taken alias")
+	assert.Equal(t, "func1", path2Alias["example.com/func"])
+	assert.Equal(t, "types", path2Alias["example.com/types"], "Should keep the non-keyword aliases")
+	for alias, path := range alias2Path {
+		assert.Equal(t, alias, path2Alias[path], "Should keep both maps consistent")
+	}
+}
+
 // TestWellKnownTypeImports tests handling of well-known protobuf types
 func TestWellKnownTypeImports(t *testing.T) {
 	wellKnownTypes := map[string]string{
@@ -380,21 +475,20 @@ func contains(s, substr string) bool {
 
 // TestImportOrderPreservation tests that import order is deterministic
 func TestImportOrderPreservation(t *testing.T) {
-	// Map iteration order is random in Go, but we want deterministic output
-	// This test documents the expected behavior
+	// Map iteration order is random in Go, but templates range over maps in
+	// sorted key order, hence the rendered imports are deterministic
 	imports := map[string]string{
+		"status":  "google.golang.org/grpc/status",
 		"context": "context",
 		"grpc":    "google.golang.org/grpc",
-		"status":  "google.golang.org/grpc/status",
 	}
+	tpl := template.Must(template.New("imports").Parse(`{{ range $alias, $path := . }}{{ $alias }} {{ end }}`))
 
-	// Verify all expected imports are present
-	assert.Contains(t, imports, "context")
-	assert.Contains(t, imports, "grpc")
-	assert.Contains(t, imports, "status")
-
-	// In the actual generated code, imports should be sorted for consistency
-	assert.Len(t, imports, 3)
+	for i := 0; i < 10; i++ {
+		out := &strings.Builder{}
+		require.NoError(t, tpl.Execute(out, imports))
+		assert.Equal(t, "context grpc status ", out.String(), "Imports should be sorted by alias")
+	}
 }
 
 // TestEmptyImportHandling tests handling of files with no additional imports
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -130,6 +132,16 @@ func TestIntegrationProtoCompilation(t *testing.T) {
 		assert.NotContains(t, outputStr, "warning", "Should not have compilation warnings")
 	})
 
+	t.Run("verify_nil_nested_fields", func(t *testing.T) {
+		redactFile := filepath.Join(testDir, "test.pb.redact.go")
+		content, err := os.ReadFile(redactFile)
+		require.NoError(t, err, "Should read generated redaction file")
+
+		assert.Contains(t, string(content), "if x.Owner != nil {\n\t\tredact.Apply(x.Owner)\n\t}",
+			"Should guard the nested calls against nil pointers")
+		testFixture(t, testDir)
+	})
+
 	t.Run("verify_generated_code_structure", func(t *testing.T) {
 		redactFile := filepath.Join(testDir, "test.pb.redact.go")
 		content, err := os.ReadFile(redactFile)
@@ -150,7 +162,7 @@ func TestIntegrationProtoCompilation(t *testing.T) {
 			},
 			{
 				name:     "custom_string_redaction",
-				contains: "`r*d@ct*d`",
+				contains: `"r*d@ct*d"`,
 				reason:   "Should have custom email redaction",
 			},
 			{
@@ -498,3 +510,1880 @@ func BenchmarkCodeGeneration(b *testing.B) {
 	// Cleanup
 	os.Remove(filepath.Join(testDir, "test.pb.redact.go"))
 }
+
+// buildPlugin builds the protoc-gen-redact plugin, returning its path
+func buildPlugin(t *testing.T) string {
+	t.Helper()
+
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-redact")
+	buildOutput, err := exec.Command("go", "build", "-o", pluginPath, ".").CombinedOutput()
+	require.NoError(t, err, "Should build protoc-gen-redact plugin: %s", string(buildOutput))
+	return pluginPath
+}
+
+// runFixture runs protoc with the Go, gRPC and redact plugins over the given
+// proto files, passing opts as additional redact plugin parameters. Generated
+// files are removed once the test finishes. It returns the protoc output.
+func runFixture(t *testing.T, opts []string, protoFiles ...string) (string, error) {
+	t.Helper()
+
+	currentDir, err := os.Getwd()
+	require.NoError(t, err, "Should get current directory")
+
+	pluginPath := buildPlugin(t)
+
+	t.Cleanup(func() {
+		for _, protoFile := range protoFiles {
+			base := strings.TrimSuffix(protoFile, ".proto")
+			for _, pattern := range []string{base + ".pb.*", base + "_grpc.pb.go"} {
+				matches, _ := filepath.Glob(pattern)
+				for _, file := range matches {
+					os.Remove(file)
+				}
+			}
+		}
+	})
+
+	redactOpts := append([]string{"paths=source_relative"}, opts...)
+	args := []string{
+		"--experimental_allow_proto3_optional",
+		"--go_out=" + currentDir,
+		"--go_opt=paths=source_relative",
+		"--go-grpc_out=" + currentDir,
+		"--go-grpc_opt=paths=source_relative",
+		"--plugin=protoc-gen-redact=" + pluginPath,
+		"--redact_out=" + currentDir,
+		"--redact_opt=" + strings.Join(redactOpts, ","),
+		"-I=" + currentDir,
+	}
+	output, err := exec.Command("protoc", append(args, protoFiles...)...).CombinedOutput()
+	return string(output), err
+}
+
+// generateFixture is runFixture, requiring the generation to succeed
+func generateFixture(t *testing.T, opts []string, protoFiles ...string) string {
+	t.Helper()
+
+	output, err := runFixture(t, opts, protoFiles...)
+	require.NoError(t, err, "protoc should generate fixture code: %s", output)
+	return output
+}
+
+// readGenerated returns the content of a file generated by generateFixture
+func readGenerated(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "Should read generated file %s", path)
+	return string(content)
+}
+
+// buildFixture verifies the generated fixture package compiles
+func buildFixture(t *testing.T, pkgDir string) {
+	t.Helper()
+
+	output, err := exec.Command("go", "build", "./"+pkgDir).CombinedOutput()
+	require.NoError(t, err, "Generated code should compile: %s", string(output))
+}
+
+// testFixture runs the tests living next to the generated fixture package
+func testFixture(t *testing.T, pkgDir string) {
+	t.Helper()
+
+	output, err := exec.Command("go", "test", "-count=1", "./"+pkgDir).CombinedOutput()
+	require.NoError(t, err, "Fixture tests should pass: %s", string(output))
+}
+
+// TestRespectValidateConstraints tests redaction values are checked against
+// the validation rules of the fields with respect_validate
+func TestRespectValidateConstraints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output := generateFixture(t, []string{"respect_validate=true"},
+		"testdata/constraints/validate/validate.proto",
+		"testdata/constraints/constraints.proto",
+	)
+	content := readGenerated(t, "testdata/constraints/constraints.pb.redact.go")
+
+	assert.Contains(t, content, `x.Pin = "REDA"`, "Should truncate to max_len")
+	assert.Contains(t, content, `x.Code = "x*****"`, "Should pad to min_len")
+	assert.Contains(t, content, `x.Zip = "REDACTED"`, "Should keep the value when no placeholder fits")
+	assert.Contains(t, content, `x.Name = "REDACTED"`, "Should keep compatible values")
+	assert.Contains(t, content, `x.Handle = "REDACTED****"`, "Should pad the default to min_len")
+	assert.Contains(t, content, `x.Bio = "REDACTED"`, "Should keep compatible defaults")
+
+	assert.Contains(t, output, "constraints.Account.zip", "Should warn about the pattern violation")
+	assert.NotContains(t, output, "constraints.Account.name", "Should not warn about compatible values")
+
+	buildFixture(t, "testdata/constraints")
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		generateFixture(t, nil,
+			"testdata/constraints/validate/validate.proto",
+			"testdata/constraints/constraints.proto",
+		)
+		content := readGenerated(t, "testdata/constraints/constraints.pb.redact.go")
+		assert.Contains(t, content, `x.Pin = "REDACTED"`)
+		assert.Contains(t, content, `x.Handle = "REDACTED"`)
+	})
+
+	t.Run("placeholder_vars", func(t *testing.T) {
+		generateFixture(t, []string{"respect_validate=true", "var_placeholders=true"},
+			"testdata/constraints/validate/validate.proto",
+			"testdata/constraints/constraints.proto",
+		)
+		content := readGenerated(t, "testdata/constraints/constraints.pb.redact.go")
+		assert.Contains(t, content, `x.Handle = "REDACTED****"`, "Should replace the violating placeholder var")
+		assert.Contains(t, content, "x.Bio = RedactedString", "Should keep the compatible placeholder var")
+		buildFixture(t, "testdata/constraints")
+	})
+}
+
+// TestOverriddenRedactionDefaults tests the default_<type> parameters flow
+// into the generated code
+func TestOverriddenRedactionDefaults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"default_int64=-1", "default_string=[MASKED]"},
+		"testdata/defaults/defaults.proto",
+	)
+	content := readGenerated(t, "testdata/defaults/defaults.pb.redact.go")
+
+	assert.Contains(t, content, "x.Amounts[k] = -1", "Should use the overridden int64 default")
+	assert.Contains(t, content, `x.Notes[k] = "[MASKED]"`, "Should use the overridden string default")
+	assert.Contains(t, content, "x.Flags[k] = false", "Should keep the predefined bool default")
+	buildFixture(t, "testdata/defaults")
+
+	t.Run("invalid_override", func(t *testing.T) {
+		output, err := runFixture(t, []string{"default_int64=abc"}, "testdata/defaults/defaults.proto")
+		require.Error(t, err, "Should fail with an invalid default")
+		assert.Contains(t, output, "default_int64")
+	})
+}
+
+// TestRedactionThroughGenericClients tests the redacted server applies the
+// redaction regardless of how the method is invoked
+func TestRedactionThroughGenericClients(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/generic/generic.proto")
+	testFixture(t, "testdata/generic")
+}
+
+// TestOptionalBytesPresence tests proto3 optional bytes keep their presence
+func TestOptionalBytesPresence(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/optionalbytes/optionalbytes.proto")
+	content := readGenerated(t, "testdata/optionalbytes/optionalbytes.pb.redact.go")
+
+	assert.Contains(t, content, "if x.Signature != nil {", "Should guard optional bytes on presence")
+	assert.NotContains(t, content, "SignatureTmp", "Optional bytes are not pointers")
+	assert.NotContains(t, content, "if x.Payload != nil {", "Should not guard non-optional bytes")
+	testFixture(t, "testdata/optionalbytes")
+}
+
+// TestVarPlaceholders tests the redaction defaults are emitted as reassignable
+// package-level vars, declared once per Go package
+func TestVarPlaceholders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"var_placeholders=true"},
+		"testdata/placeholders/placeholders.proto",
+		"testdata/placeholders/other.proto",
+	)
+	owner := readGenerated(t, "testdata/placeholders/other.pb.redact.go")
+	content := readGenerated(t, "testdata/placeholders/placeholders.pb.redact.go")
+
+	assert.Regexp(t, `RedactedString\s+string\s+= "REDACTED"`, owner, "First file of the package declares the vars")
+	assert.NotRegexp(t, `RedactedString\s+string`, content, "Vars are declared once per package")
+	assert.Contains(t, content, "x.Emails[k] = RedactedString", "Should reference the placeholder var")
+	testFixture(t, "testdata/placeholders")
+}
+
+// TestPlaceholderOwnerSkip tests the package-level vars are not declared by a
+// file with file_skip, which is not generated
+func TestPlaceholderOwnerSkip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	for _, opt := range []string{"var_placeholders=true", "runtime_marker=true", "version_const=true"} {
+		t.Run(opt, func(t *testing.T) {
+			generateFixture(t, []string{opt}, "testdata/ownerskip/a.proto", "testdata/ownerskip/b.proto")
+			_, err := os.Stat("testdata/ownerskip/a.pb.redact.go")
+			assert.True(t, os.IsNotExist(err), "Should not generate the skipped file")
+			testFixture(t, "testdata/ownerskip")
+		})
+	}
+}
+
+// TestRuntimeMarker tests the string redaction default is emitted as the
+// RedactedStringValue var, settable at runtime
+func TestRuntimeMarker(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"runtime_marker=true"}, "testdata/runtimemarker/runtimemarker.proto")
+	content := readGenerated(t, "testdata/runtimemarker/runtimemarker.pb.redact.go")
+
+	assert.Regexp(t, `RedactedStringValue\s+string\s+= "REDACTED"`, content)
+	assert.NotContains(t, content, "RedactedInt64", "Should only declare the string marker")
+	assert.Contains(t, content, "x.Emails[k] = RedactedStringValue")
+	assert.Contains(t, content, "x.Name = RedactedStringValue")
+	assert.Contains(t, content, `x.Password = "hidden"`, "Should keep the explicit values")
+	testFixture(t, "testdata/runtimemarker")
+
+	_, err := runFixture(t, []string{"runtime_marker=true", "var_placeholders=true"}, "testdata/runtimemarker/runtimemarker.proto")
+	assert.Error(t, err, "Should fail with var_placeholders")
+}
+
+// TestVersionConst tests the generated files carry the plugin version and the
+// hash of their annotations, and the RedactGenVersion constant is declared once
+// per Go package with version_const
+func TestVersionConst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"version_const=true", "var_placeholders=true"},
+		"testdata/placeholders/placeholders.proto",
+		"testdata/placeholders/other.proto",
+	)
+	owner := readGenerated(t, "testdata/placeholders/other.pb.redact.go")
+	content := readGenerated(t, "testdata/placeholders/placeholders.pb.redact.go")
+
+	assert.Contains(t, content, "// versions:\n// \tprotoc-gen-redact dev\n")
+	assert.Contains(t, owner, `const RedactGenVersion = "dev"`, "First file of the package declares the constant")
+	assert.NotContains(t, content, "RedactGenVersion", "The constant is declared once per package")
+
+	hash := regexp.MustCompile(`// annotations: sha256:([0-9a-f]{64})\n`)
+	ownerHash, contentHash := hash.FindStringSubmatch(owner), hash.FindStringSubmatch(content)
+	require.Len(t, ownerHash, 2, "Should carry the hash of the annotations")
+	require.Len(t, contentHash, 2, "Should carry the hash of the annotations")
+	assert.NotEqual(t, ownerHash[1], contentHash[1], "Files with other annotations have other hashes")
+	testFixture(t, "testdata/placeholders")
+}
+
+// TestWellKnownTypes tests Timestamp, Duration, Any and Struct fields are redacted by
+// value, since they have no Redact() method
+func TestWellKnownTypes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/wellknown/wellknown.proto")
+	content := readGenerated(t, "testdata/wellknown/wellknown.pb.redact.go")
+
+	assert.Contains(t, content, "x.CreatedAt = timestamppb.New(time.Unix(0, 0))", "Should redact to the epoch")
+	assert.Contains(t, content, "x.Ttl = durationpb.New(0)", "Should redact to zero")
+	assert.Contains(t, content, "x.Grace = nil")
+	assert.Contains(t, content, "x.Renewal = &durationpb.Duration{}")
+	assert.Contains(t, content, "x.Detail = &anypb.Any{}", "Should clear the Any envelope")
+	assert.Contains(t, content, "x.Metadata = &structpb.Struct{}", "Should empty the Struct")
+	assert.Contains(t, content, "x.Attribute = structpb.NewNullValue()", "Should set the Value to null")
+	assert.NotContains(t, content, "redact.Apply(x.CreatedAt)", "Should not call Redact() on well-known types")
+	testFixture(t, "testdata/wellknown")
+}
+
+// TestWellKnownWrappers tests the wrapper types are redacted to a wrapper of
+// their scalar default
+func TestWellKnownWrappers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/wrappers/wrappers.proto")
+	content := readGenerated(t, "testdata/wrappers/wrappers.pb.redact.go")
+
+	assert.Contains(t, content, `x.Nickname = wrapperspb.String("REDACTED")`, "Should wrap the string default")
+	assert.Contains(t, content, "x.Age = wrapperspb.Int32(0)", "Should wrap the int32 default")
+	assert.NotContains(t, content, "redact.Apply(x.Nickname)", "Should not call Redact() on wrappers")
+	testFixture(t, "testdata/wrappers")
+}
+
+// TestCrossPackageMapValues tests map values of an imported message type are
+// redacted by the Redact() generated in their own package
+func TestCrossPackageMapValues(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/crosspkg/common/common.proto",
+		"testdata/crosspkg/crosspkg.proto",
+	)
+	content := readGenerated(t, "testdata/crosspkg/crosspkg.pb.redact.go")
+
+	assert.Contains(t, content, `common "github.com/menta2k/protoc-gen-redact/v3/testdata/crosspkg/common"`,
+		"Should import the package of the map value type")
+	assert.Contains(t, content, "redact.Apply(x.Profiles[k])", "Should redact each map value")
+	testFixture(t, "testdata/crosspkg")
+}
+
+// TestCrossPackageOutput tests the responses of an imported message type are
+// redacted by the Redact() generated in their own package
+func TestCrossPackageOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/importedoutput/common/common.proto",
+		"testdata/importedoutput/importedoutput.proto",
+	)
+	content := readGenerated(t, "testdata/importedoutput/importedoutput.pb.redact.go")
+
+	assert.Contains(t, content, `common "github.com/menta2k/protoc-gen-redact/v3/testdata/importedoutput/common"`,
+		"Should import the package of the output type")
+	assert.Contains(t, content, "(*common.Profile, error)", "Should reference the output with its alias")
+	assert.Contains(t, content, "res = &common.Session{}", "Should read the options of the imported output")
+	assert.NotContains(t, content, "func (x *Profile) Redact()", "Should not generate Redact() for the imported output")
+	testFixture(t, "testdata/importedoutput")
+}
+
+// TestImportedRedactors tests the imported messages called for redaction are
+// asserted to have a generated Redact() method
+func TestImportedRedactors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/importedredactor/common/common.proto",
+		"testdata/importedredactor/importedredactor.proto",
+	)
+	content := readGenerated(t, "testdata/importedredactor/importedredactor.pb.redact.go")
+
+	assert.Equal(t, 1, strings.Count(content, "_ = (*common.Profile).Redact"), "Should assert each imported message once")
+	assert.NotContains(t, content, "(*common.Audit).Redact", "Should not assert the ignored messages")
+	assert.NotContains(t, content, "(*emptypb.Empty).Redact", "Should not assert the messages without redaction rules")
+	testFixture(t, "testdata/importedredactor")
+
+	// without the Redact() of the imported package, the values would be kept
+	require.NoError(t, os.Remove("testdata/importedredactor/common/common.pb.redact.go"))
+	buildOutput, err := exec.Command("go", "build", "./testdata/importedredactor").CombinedOutput()
+	require.Error(t, err, "Should not compile without the imported Redact()")
+	assert.Contains(t, string(buildOutput), "(*common.Profile).Redact undefined")
+
+	output, err := runFixture(t, nil,
+		"testdata/importedredactor/skipped/skipped.proto",
+		"testdata/importedredactor/invalid/invalid.proto",
+	)
+	require.Error(t, err, "Should reject the nested calls to messages of skipped files")
+	assert.Contains(t, output, "importedredactor.skipped.Secret of the skipped file")
+}
+
+// TestStandardAliasCollisions tests the imported packages named as the
+// standard imports get distinct aliases
+func TestStandardAliasCollisions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/stdalias/codes/codes.proto",
+		"testdata/stdalias/status/status.proto",
+		"testdata/stdalias/stdalias.proto",
+	)
+	content := readGenerated(t, "testdata/stdalias/stdalias.pb.redact.go")
+
+	assert.Contains(t, content, `status "google.golang.org/grpc/status"`, "Should keep the standard aliases")
+	assert.Contains(t, content, `codes "google.golang.org/grpc/codes"`)
+	assert.Contains(t, content, `status1 "github.com/menta2k/protoc-gen-redact/v3/testdata/stdalias/status"`,
+		"Should suffix the user packages")
+	assert.Contains(t, content, `codes1 "github.com/menta2k/protoc-gen-redact/v3/testdata/stdalias/codes"`)
+	assert.Contains(t, content, "(*status1.Detail, error)")
+	assert.Contains(t, content, "x.Code = codes1.Code_CODE_DENIED")
+	assert.Contains(t, content, "status.Error(codes.PermissionDenied,")
+	testFixture(t, "testdata/stdalias")
+}
+
+// TestResetAndCopy tests fields neither allowed nor redacted are dropped with
+// reset_and_copy
+func TestResetAndCopy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"reset_and_copy=true"}, "testdata/resetcopy/resetcopy.proto")
+	content := readGenerated(t, "testdata/resetcopy/resetcopy.pb.redact.go")
+
+	assert.Contains(t, content, "x.Reset()", "Should reset the message")
+	assert.Contains(t, content, "keepId := x.Id", "Should copy allowed fields")
+	assert.NotContains(t, content, "keepNotes", "Should drop unannotated fields")
+	assert.Contains(t, content, "// Dropped field: Notes", "Should document dropped fields")
+	assert.Contains(t, content, "keepChannel := x.Channel", "Should copy the oneofs with kept fields")
+	assert.Contains(t, content, "case *Contact_Email, *Contact_Phone:", "Should only copy the kept fields of the oneof")
+	testFixture(t, "testdata/resetcopy")
+}
+
+// TestWarnNoopNested tests nested redaction calls into messages without any
+// redactable fields are reported with warn_noop_nested
+func TestWarnNoopNested(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output := generateFixture(t, []string{"warn_noop_nested=true"}, "testdata/noopnested/noopnested.proto")
+
+	assert.Contains(t, output, "noopnested.Order.item calls the redaction of .noopnested.Item", "Should warn about the no-op nested call")
+	assert.Contains(t, output, "noopnested.Order.items calls the redaction of .noopnested.Item", "Should warn about no-op nested items")
+	assert.NotContains(t, output, "noopnested.Order.payment", "Should not warn about redactable messages")
+	assert.Contains(t, output, "noopnested.Order.audit calls the redaction of .noopnested.Audit which is ignored", "Should warn about ignored messages")
+	testFixture(t, "testdata/noopnested")
+
+	output = generateFixture(t, nil, "testdata/noopnested/noopnested.proto")
+	assert.NotContains(t, output, "Warning", "Should not warn by default")
+}
+
+// TestMaxFieldLen tests the length of the fields are capped on redaction
+// with max_field_len
+func TestMaxFieldLen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"max_field_len=8"}, "testdata/maxlen/maxlen.proto")
+	content := readGenerated(t, "testdata/maxlen/maxlen.pb.redact.go")
+
+	assert.Contains(t, content, "x.Message = redact.TruncateString(x.Message, 8)", "Should cap strings")
+	assert.Contains(t, content, "redact.TruncateMap(x.Labels, 8)", "Should cap maps")
+	assert.NotContains(t, content, "x.Host", "Should skip oneof fields")
+	testFixture(t, "testdata/maxlen")
+
+	output, err := runFixture(t, []string{"max_field_len=-1"}, "testdata/maxlen/maxlen.proto")
+	assert.Error(t, err, "Should reject negative lengths")
+	assert.Contains(t, output, "max_field_len")
+}
+
+// TestPANMask tests the card numbers are masked but the last four digits
+func TestPANMask(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/panmask/panmask.proto")
+	content := readGenerated(t, "testdata/panmask/panmask.pb.redact.go")
+
+	assert.Contains(t, content, "x.Card = redact.MaskPAN(x.Card)")
+	assert.Contains(t, content, "*x.BackupCard = redact.MaskPAN(*x.BackupCard)", "Should mask optional fields")
+	assert.Contains(t, content, "x.Cards[k] = redact.MaskPAN(x.Cards[k])", "Should mask the items")
+	testFixture(t, "testdata/panmask")
+}
+
+// TestRecursiveOneOf tests the redaction of a message embedding itself through
+// a oneof, the recursion is bounded by the depth of the message
+func TestRecursiveOneOf(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	for _, opts := range [][]string{nil, {"fallible=true"}} {
+		generateFixture(t, opts, "testdata/recursive/recursive.proto")
+		content := readGenerated(t, "testdata/recursive/recursive.pb.redact.go")
+
+		assert.Contains(t, content, "if v, ok := x.V.(*Node_Child); ok {", "Should switch on the oneof wrapper")
+		assert.Contains(t, content, "redact.Apply(v.Child)", "Should recurse into the child")
+		assert.Contains(t, content, "v.Leaf = -1")
+		assert.NotContains(t, content, "x.Leaf", "Should not reference the oneof fields on the message")
+		testFixture(t, "testdata/recursive")
+	}
+}
+
+// TestProof tests the redacted fields are recorded as proof of redaction
+func TestProof(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"proof=true"}, "testdata/proof/proof.proto")
+	content := readGenerated(t, "testdata/proof/proof.pb.redact.go")
+
+	assert.Contains(t, content, `redact.RecordProof("proof.Account", []string{"password", "tokens", "profile"}, time.Now())`)
+	assert.Equal(t, 1, strings.Count(content, "redact.RecordProof("), "Should skip messages without redacted fields")
+	testFixture(t, "testdata/proof")
+
+	generateFixture(t, nil, "testdata/proof/proof.proto")
+	content = readGenerated(t, "testdata/proof/proof.pb.redact.go")
+	assert.NotContains(t, content, "RecordProof", "Should not record proofs by default")
+}
+
+// TestClearElements tests the items of repeated and map fields are cleared,
+// keeping their count
+func TestClearElements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/clearelements/clearelements.proto")
+	content := readGenerated(t, "testdata/clearelements/clearelements.pb.redact.go")
+
+	assert.Contains(t, content, "x.Items[k] = &Item{}", "Should replace the messages with empty ones")
+	assert.Contains(t, content, "x.BySku[k] = &Item{}")
+	assert.Contains(t, content, `x.Notes[k] = ""`, "Should replace the scalars with zero values")
+	assert.Contains(t, content, "x.Quantities[k] = 0")
+	assert.Contains(t, content, "x.Gifts = []*Item{}", "Should not iterate emptied fields")
+	testFixture(t, "testdata/clearelements")
+}
+
+// TestEmitMetadata tests the JSON sidecar describing the redaction of the
+// generated files
+func TestEmitMetadata(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"emit_metadata"}, "testdata/metadata/metadata.proto")
+	content := readGenerated(t, "testdata/metadata/metadata.pb.redact.json")
+
+	meta := &fileMetadata{}
+	require.NoError(t, json.Unmarshal([]byte(content), meta), "Should emit valid JSON")
+	assert.Equal(t, "testdata/metadata/metadata.proto", meta.Source)
+	require.Len(t, meta.Messages, 3)
+	assert.Equal(t, &messageMetadata{Name: "Address", Strategy: strategyNil}, meta.Messages[0], "Should sort the messages")
+	assert.Equal(t, &messageMetadata{Name: "Audit", Strategy: strategyIgnore}, meta.Messages[1])
+
+	user := meta.Messages[2]
+	assert.Equal(t, "User", user.Name)
+	assert.Equal(t, strategyFields, user.Strategy)
+	assert.Equal(t, []*fieldMetadata{
+		{Name: "Address", Type: "Address", Redact: true, Strategy: strategyNested},
+		{Name: "Billing", Type: "Address", Redact: true, Strategy: strategySkip},
+		{Name: "Cards", Type: "string", Redact: true, Strategy: strategyPANMask},
+		{Name: "Password", Type: "string", Redact: true, Strategy: strategyValue, Value: `"hidden"`},
+		{Name: "Pins", Type: "int64", Redact: true, Strategy: strategyItems, Value: "0"},
+		{Name: "Username", Type: "string", Strategy: strategySafe},
+	}, user.Fields, "Should sort the fields")
+
+	generateFixture(t, []string{"emit_metadata"}, "testdata/metadata/metadata.proto")
+	assert.Equal(t, content, readGenerated(t, "testdata/metadata/metadata.pb.redact.json"), "Should be stable")
+
+	require.NoError(t, os.Remove("testdata/metadata/metadata.pb.redact.json"))
+	generateFixture(t, nil, "testdata/metadata/metadata.proto")
+	assert.NoFileExists(t, "testdata/metadata/metadata.pb.redact.json", "Should not emit metadata by default")
+}
+
+// TestOneOfOptionalFields tests the optional fields of messages held by
+// oneofs, two levels down, keep their pointer semantics
+func TestOneOfOptionalFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/oneofoptional/oneofoptional.proto")
+	content := readGenerated(t, "testdata/oneofoptional/oneofoptional.pb.redact.go")
+
+	assert.Contains(t, content, "if v, ok := x.V.(*Outer_Inner); ok {")
+	assert.Contains(t, content, "if v, ok := x.W.(*Inner_Leaf); ok {")
+	assert.Contains(t, content, "x.Token = &TokenTmp", "Should assign optional scalars through pointers")
+	assert.Contains(t, content, "x.Code = &CodeTmp")
+	assert.Contains(t, content, "if x.Blob != nil {", "Should guard the optional bytes")
+	testFixture(t, "testdata/oneofoptional")
+}
+
+// TestStringLiterals tests the custom redaction values are quoted, hence the
+// values with backticks or newlines compile
+func TestStringLiterals(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/literals/literals.proto")
+	content := readGenerated(t, "testdata/literals/literals.pb.redact.go")
+
+	assert.Contains(t, content, "x.Backtick = \"a`b\"")
+	assert.Contains(t, content, "x.Raw = []byte(\"c`d\")")
+	assert.Contains(t, content, `x.Multiline = "line1\nline2"`)
+	testFixture(t, "testdata/literals")
+}
+
+// TestEmptyErrMessage tests the internal methods with an empty error message
+// fall back to the default one
+func TestEmptyErrMessage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/errmessage/errmessage.proto")
+	content := readGenerated(t, "testdata/errmessage/errmessage.pb.redact.go")
+
+	assert.NotContains(t, content, "status.Error(codes.PermissionDenied, ``)", "Should not generate empty messages")
+	assert.Contains(t, content, "`Permission Denied. Method: \"AdminServer.Blank\" has been redacted`")
+	assert.Contains(t, content, "`Custom is internal`")
+	testFixture(t, "testdata/errmessage")
+}
+
+// TestBacktickErrMessage tests the error messages holding backticks are
+// quoted, keeping the generated code compilable
+func TestBacktickErrMessage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/errmessage/errmessage.proto")
+	content := readGenerated(t, "testdata/errmessage/errmessage.pb.redact.go")
+
+	assert.Contains(t, content, "status.Error(codes.PermissionDenied, \"`Backtick` is internal\")")
+	buildFixture(t, "testdata/errmessage")
+}
+
+// TestReportOnly tests the report of the redaction replaces the generated code
+// with report_only
+func TestReportOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"report_only=true"}, "testdata/report/report.proto")
+	assert.NoFileExists(t, "testdata/report/report.pb.redact.go", "Should not generate the code")
+	content := readGenerated(t, "testdata/report/report.pb.redact.report.txt")
+
+	assert.Contains(t, content, "Redaction report for testdata/report/report.proto")
+	assert.Contains(t, content, "message User: 2/4 fields redacted")
+	assert.Contains(t, content, `+ Password: value "hidden"`)
+	assert.Contains(t, content, "+ Address: nested")
+	assert.Contains(t, content, "- Username\n", "Should list the unredacted fields")
+	assert.Contains(t, content, "- Email: WARNING: looks like PII but is not redacted", "Should flag the PII-looking fields")
+	assert.Contains(t, content, "message Address: 0/1 fields redacted\n  WARNING: no redacted fields", "Should flag the messages without redactions")
+	assert.Contains(t, content, "message Audit: ignored")
+}
+
+// TestPIILint tests the unredacted fields looking like PII are reported with
+// warn_pii, or fail the generation with strict_pii
+func TestPIILint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output := generateFixture(t, []string{"warn_pii=true"}, "testdata/pii/pii.proto")
+	assert.Contains(t, output, "pii.User.email_address looks like PII but is not redacted", "Should warn about PII-looking fields")
+	assert.NotContains(t, output, "pii.User.password", "Should not warn about redacted fields")
+	assert.NotContains(t, output, "pii.User.api_token", "Should not warn about allowed fields")
+	assert.NotContains(t, output, "pii.User.username", "Should not warn about other fields")
+	assert.NotContains(t, output, "pii.Session.secret", "Should not warn about fields of nil messages")
+
+	output = generateFixture(t, []string{"warn_pii=true", "pii_keywords=phone:user"}, "testdata/pii/pii.proto")
+	assert.Contains(t, output, "pii.User.phone looks like PII", "Should use the overridden keywords")
+	assert.Contains(t, output, "pii.User.username looks like PII")
+	assert.NotContains(t, output, "pii.User.email_address", "Should replace the default keywords")
+
+	output = generateFixture(t, nil, "testdata/pii/pii.proto")
+	assert.NotContains(t, output, "looks like PII", "Should not warn by default")
+
+	output, err := runFixture(t, []string{"strict_pii=true"}, "testdata/pii/pii.proto")
+	assert.Error(t, err, "Should fail with strict_pii")
+	assert.Contains(t, output, "pii.User.email_address looks like PII")
+}
+
+// TestMapOfNestedRepeated tests the map values whose message holds repeated
+// and map fields, or embeds sensitive messages, are redacted recursively
+func TestMapOfNestedRepeated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/mapnested/mapnested.proto")
+	content := readGenerated(t, "testdata/mapnested/mapnested.pb.redact.go")
+
+	assert.Contains(t, content, "redact.Apply(x.Lists[k])", "Should redact the map values")
+	assert.Contains(t, content, "redact.Apply(x.Archived[k])")
+	assert.Contains(t, content, "redact.Apply(x.BySku[k])", "Should redact the nested map values")
+	assert.Contains(t, content, "redact.Apply(x.Entries[k])")
+	assert.Contains(t, content, "redact.Apply(x.Key)", "Should redact the composite keys of the map values")
+	testFixture(t, "testdata/mapnested")
+}
+
+// TestBuildTag tests the generated files are constrained by the build tag,
+// with a stub without redaction for the other builds
+func TestBuildTag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"build_tag=redact"}, "testdata/buildtag/buildtag.proto")
+	content := readGenerated(t, "testdata/buildtag/buildtag.pb.redact.go")
+	stub := readGenerated(t, "testdata/buildtag/buildtag.pb.redact.noredact.go")
+
+	assert.Contains(t, content, "//go:build redact\n")
+	assert.Contains(t, content, `x.Password = "hidden"`)
+	assert.Contains(t, stub, "//go:build !redact\n")
+	assert.NotContains(t, stub, "x.Password", "Should not redact in the stub")
+	assert.Contains(t, stub, "func (x *Account) Redact() string {", "Should keep the same methods")
+
+	testFixture(t, "testdata/buildtag")
+	output, err := exec.Command("go", "test", "-count=1", "-tags", "redact", "./testdata/buildtag").CombinedOutput()
+	require.NoError(t, err, "Fixture tests should pass with the build tag: %s", string(output))
+
+	output2, err := runFixture(t, []string{"build_tag=a b"}, "testdata/buildtag/buildtag.proto")
+	assert.Error(t, err, "Should reject invalid build tags")
+	assert.Contains(t, output2, "build_tag")
+}
+
+// TestStringer tests the redacted clones are printed with stringer
+func TestStringer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	for _, opts := range [][]string{{"stringer=true"}, {"stringer=true", "fallible=true"}} {
+		generateFixture(t, opts, "testdata/stringer/stringer.proto")
+		content := readGenerated(t, "testdata/stringer/stringer.pb.redact.go")
+
+		assert.Contains(t, content, "func (x *Account) RedactedString() string {")
+		assert.Contains(t, content, "func (x *Account) GoString() string {")
+		assert.Contains(t, content, `protojson "google.golang.org/protobuf/encoding/protojson"`)
+		if len(opts) == 1 {
+			assert.Contains(t, content, "return protojson.Format(redact.ApplyCopy(x))", "Should print the redacted copy")
+		}
+		testFixture(t, "testdata/stringer")
+	}
+
+	generateFixture(t, nil, "testdata/stringer/stringer.proto")
+	content := readGenerated(t, "testdata/stringer/stringer.pb.redact.go")
+	assert.NotContains(t, content, "RedactedString", "Should not generate the methods by default")
+	assert.NotContains(t, content, "protojson", "Should not import protojson by default")
+}
+
+// TestAllowWithRules tests the allowed fields cannot have redaction rules
+func TestAllowWithRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output, err := runFixture(t, nil, "testdata/allowconflict/allowconflict.proto")
+	assert.Error(t, err, "Should reject allowed fields with redaction rules")
+	assert.Contains(t, output, "allowconflict.Account.password")
+	assert.NotContains(t, output, "allowconflict.Account.username", "Should accept allowed fields without rules")
+}
+
+// TestEnumLast tests the enum fields are redacted to the highest-numbered
+// value of their enum
+func TestEnumLast(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/enumlast/common/common.proto",
+		"testdata/enumlast/enumlast.proto",
+	)
+	content := readGenerated(t, "testdata/enumlast/enumlast.pb.redact.go")
+
+	assert.Contains(t, content, "x.Status = Account_STATUS_REDACTED")
+	assert.Contains(t, content, "x.Level = common.Level_LEVEL_REDACTED", "Should use the alias of the imported enum")
+	assert.Contains(t, content, "x.Levels[k] = common.Level_LEVEL_REDACTED")
+	testFixture(t, "testdata/enumlast")
+
+	output, err := runFixture(t, nil, "testdata/enumlast/invalid/invalid.proto")
+	assert.Error(t, err, "Should reject enum_last on non enum fields")
+	assert.Contains(t, output, "enumlast.invalid.Account.status")
+}
+
+// TestCustomRedactor tests the fields of the messages using the custom
+// redactor are routed through the registered redact.FieldRedactor
+func TestCustomRedactor(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/customredactor/customredactor.proto")
+	content := readGenerated(t, "testdata/customredactor/customredactor.pb.redact.go")
+
+	assert.Contains(t, content, "redactor := redact.GetRedactor()")
+	assert.Contains(t, content, `x.Username = redact.RedactFieldAs(redactor, "username", x.Username)`)
+	assert.Contains(t, content, `x.Contact = redact.RedactFieldAs(redactor, "contact", x.Contact)`, "Should route the oneof as a whole")
+	assert.NotContains(t, content, `"email"`)
+	assert.Equal(t, 1, strings.Count(content, "redact.GetRedactor()"), "Should only route the opted-in messages")
+
+	testFixture(t, "testdata/customredactor")
+}
+
+// TestPathsImport tests the generated files land in the directory of their
+// import path with paths=import
+func TestPathsImport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	outDir := t.TempDir()
+	output, err := exec.Command("protoc",
+		"--plugin=protoc-gen-redact="+buildPlugin(t),
+		"--redact_out="+outDir,
+		"--redact_opt=paths=import,emit_metadata,build_tag=redact",
+		"-I=.",
+		"testdata/proof/proof.proto",
+	).CombinedOutput()
+	require.NoError(t, err, "protoc should generate with paths=import: %s", string(output))
+
+	pkgDir := filepath.Join(outDir, "github.com/menta2k/protoc-gen-redact/v3/testdata/proof")
+	for _, name := range []string{"proof.pb.redact.go", "proof.pb.redact.noredact.go", "proof.pb.redact.json"} {
+		assert.FileExists(t, filepath.Join(pkgDir, name), "Should generate in the import path directory")
+	}
+	assert.NoFileExists(t, filepath.Join(outDir, "testdata/proof/proof.pb.redact.go"), "Should not generate relative to the source")
+}
+
+// TestRedactInput tests the requests of the methods with redact_input are
+// redacted before calling the handler
+func TestRedactInput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/redactinput/redactinput.proto")
+	content := readGenerated(t, "testdata/redactinput/redactinput.pb.redact.go")
+
+	assert.Equal(t, 3, strings.Count(content, "redact.Apply(in)"), "Should only redact the requests of the opted-in methods")
+	assert.Contains(t, content, "redact.Apply(in)\n\t// Note: Redaction for server streaming", "Should redact the request of server streaming methods")
+	testFixture(t, "testdata/redactinput")
+
+	generateFixture(t, []string{"fallible=true"}, "testdata/redactinput/redactinput.proto")
+	content = readGenerated(t, "testdata/redactinput/redactinput.pb.redact.go")
+	assert.Contains(t, content, "if err := redact.Apply(in); err != nil {", "Should fail the call when the redaction fails")
+	testFixture(t, "testdata/redactinput")
+
+	output, err := runFixture(t, nil, "testdata/redactinput/invalid/invalid.proto")
+	require.Error(t, err, "Should reject redact_input on client streaming methods")
+	assert.Contains(t, output, "client streaming method")
+}
+
+// TestMapKeys tests the maps with string keys are rebuilt with their keys
+// redacted
+func TestMapKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/mapkeys/mapkeys.proto")
+	content := readGenerated(t, "testdata/mapkeys/mapkeys.pb.redact.go")
+
+	assert.Contains(t, content, `x.Headers = redact.RedactMapKeys(x.Headers, func(string) string { return "***" })`)
+	assert.Contains(t, content, `x.Headers[k] = "REDACTED"`, "Should redact the values after the keys")
+	assert.Contains(t, content, "x.Balances = redact.RedactMapKeys(x.Balances, redact.MaskPAN)")
+	assert.NotContains(t, content, "x.Sessions = nil", "Should keep the values without item rules")
+	testFixture(t, "testdata/mapkeys")
+
+	generateFixture(t, []string{"emit_metadata"}, "testdata/mapkeys/mapkeys.proto")
+	meta := &fileMetadata{}
+	require.NoError(t, json.Unmarshal([]byte(readGenerated(t, "testdata/mapkeys/mapkeys.pb.redact.json")), meta))
+	require.Len(t, meta.Messages, 2)
+	assert.Equal(t, []*fieldMetadata{
+		{Name: "Balances", Redact: true, Strategy: strategyKeys, Key: strategyPANMask},
+		{Name: "Headers", Redact: true, Strategy: strategyItems, Value: `"REDACTED"`, Key: `"***"`},
+		{Name: "Sessions", Type: "Session", Redact: true, Strategy: strategyKeys, Key: `"session"`},
+	}, meta.Messages[0].Fields)
+
+	output, err := runFixture(t, nil, "testdata/mapkeys/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the key rules of maps without string keys")
+	assert.Contains(t, output, "map field with string keys")
+}
+
+// TestRepeatedBytes tests the items of the repeated bytes fields are redacted
+// one by one, keeping the length of the fields
+func TestRepeatedBytes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/repeatedbytes/repeatedbytes.proto")
+	content := readGenerated(t, "testdata/repeatedbytes/repeatedbytes.pb.redact.go")
+
+	assert.Contains(t, content, "x.Blobs[k] = nil")
+	assert.Contains(t, content, `x.Masked[k] = []byte("***")`)
+	assert.Contains(t, content, "x.Dropped = [][]byte{}")
+	assert.NotContains(t, content, "x.Blobs = nil", "Should not drop the whole field")
+	testFixture(t, "testdata/repeatedbytes")
+}
+
+// TestEditions tests the fields of the edition 2023 files are redacted with
+// respect to their presence features
+func TestEditions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/editions/implicit/implicit.proto",
+		"testdata/editions/editions.proto",
+	)
+	content := readGenerated(t, "testdata/editions/editions.pb.redact.go")
+
+	assert.Contains(t, content, "x.Name = &NameTmp", "Should redact the explicit presence fields as pointers")
+	assert.Contains(t, content, "StatusTmp := Status(1)")
+	assert.Contains(t, content, "*x.Card = redact.MaskPAN(*x.Card)")
+	assert.Contains(t, content, `x.Implicit = "hidden"`, "Should redact the implicit presence fields by value")
+
+	content = readGenerated(t, "testdata/editions/implicit/implicit.pb.redact.go")
+	assert.Contains(t, content, `x.Email = "hidden"`, "Should inherit the implicit presence of the file")
+	assert.Contains(t, content, "x.Phone = &PhoneTmp", "Should override the presence of the file")
+	assert.Contains(t, content, `x.Street = "hidden"`)
+	testFixture(t, "testdata/editions")
+}
+
+// TestRounding tests the numeric fields are truncated to a multiple of their
+// rounding factor
+func TestRounding(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/rounding/rounding.proto")
+	content := readGenerated(t, "testdata/rounding/rounding.pb.redact.go")
+
+	assert.Contains(t, content, "x.Amount = (x.Amount / 1000) * 1000")
+	assert.Contains(t, content, "x.Score = redact.RoundTo(x.Score, 0.5)")
+	assert.Contains(t, content, "*x.Fee = (*x.Fee / 100) * 100")
+	assert.Contains(t, content, "x.Totals[k] = (x.Totals[k] / 1000) * 1000")
+	assert.Contains(t, content, "v.Daily = (v.Daily / 50) * 50")
+	testFixture(t, "testdata/rounding")
+
+	generateFixture(t, []string{"emit_metadata"}, "testdata/rounding/rounding.proto")
+	meta := &fileMetadata{}
+	require.NoError(t, json.Unmarshal([]byte(readGenerated(t, "testdata/rounding/rounding.pb.redact.json")), meta))
+	require.Len(t, meta.Messages, 1)
+	assert.Contains(t, meta.Messages[0].Fields,
+		&fieldMetadata{Name: "Amount", Type: "int64", Redact: true, Strategy: strategyRound, Value: "1000"})
+
+	output, err := runFixture(t, nil, "testdata/rounding/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the zero rounding factor")
+	assert.Contains(t, output, "nonzero rounding factor")
+}
+
+// TestCopyFrom tests the fields are set to the values of their siblings
+func TestCopyFrom(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/copyfrom/copyfrom.proto")
+	content := readGenerated(t, "testdata/copyfrom/copyfrom.pb.redact.go")
+
+	assert.Contains(t, content, "x.DisplayName = x.UserId")
+	assert.Contains(t, content, "NicknameTmp := *x.Alias", "Should copy the optional fields by value")
+	assert.Contains(t, content, "x.RequestedRole = x.Role")
+	testFixture(t, "testdata/copyfrom")
+
+	output, err := runFixture(t, nil, "testdata/copyfrom/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the siblings of another type")
+	assert.Contains(t, output, "a sibling field assignable to display_name")
+}
+
+// TestMessagesOnly tests the redacted server wrappers, and their grpc imports,
+// are not generated with messages_only
+func TestMessagesOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"messages_only=true"}, "testdata/messagesonly/messagesonly.proto")
+	content := readGenerated(t, "testdata/messagesonly/messagesonly.pb.redact.go")
+
+	assert.Contains(t, content, "func (x *Credentials) Redact() string")
+	assert.NotContains(t, content, "RegisterRedactedAuth")
+	assert.NotContains(t, content, "google.golang.org/grpc", "Should not import the grpc packages")
+	assert.NotContains(t, content, "context")
+	testFixture(t, "testdata/messagesonly")
+
+	generateFixture(t, nil, "testdata/messagesonly/messagesonly.proto")
+	content = readGenerated(t, "testdata/messagesonly/messagesonly.pb.redact.go")
+	assert.Contains(t, content, "func RegisterRedactedAuthServer(", "Should generate the wrappers by default")
+}
+
+// TestServiceFreeFile tests the generated files without services do not depend
+// on the grpc packages
+func TestServiceFreeFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/repeatedbytes/repeatedbytes.proto")
+	content := readGenerated(t, "testdata/repeatedbytes/repeatedbytes.pb.redact.go")
+
+	assert.NotContains(t, content, "google.golang.org/grpc", "Should not import the grpc packages")
+	assert.NotContains(t, content, "context.Context")
+	assert.Contains(t, content, "_ redact.Redactor")
+	buildFixture(t, "testdata/repeatedbytes")
+}
+
+// TestAllFields tests all the fields of the all_fields messages are redacted
+// with their defaults, unless allowed or having rules of their own
+func TestAllFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/allfields/allfields.proto")
+	content := readGenerated(t, "testdata/allfields/allfields.pb.redact.go")
+
+	assert.Contains(t, content, "// Safe field: Id")
+	assert.Contains(t, content, `x.Name = "REDACTED"`)
+	assert.Contains(t, content, "x.Balance = 0")
+	assert.Contains(t, content, "x.Tags = nil")
+	assert.Contains(t, content, "redact.Apply(x.Inner)")
+	assert.Contains(t, content, `x.Note = "custom"`, "Should apply the rules of the fields")
+	assert.Contains(t, content, "// Safe field: Hint", "Should not redact the fields of the nested messages")
+	testFixture(t, "testdata/allfields")
+}
+
+// TestMaxDepth tests the nested redaction calls beyond max_depth redact the
+// fields to nil
+func TestMaxDepth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"max_depth=2"}, "testdata/maxdepth/maxdepth.proto")
+	content := readGenerated(t, "testdata/maxdepth/maxdepth.pb.redact.go")
+
+	// Level0 -> Level1 -> Level2 and, cut from Level2, Level3 -> Level4
+	assert.Equal(t, 3, strings.Count(content, "redact.Apply(x.Next)"), "Should call the redaction within the max depth")
+	assert.Contains(t, content, "x.Next = nil")
+	assert.Contains(t, content, "x.Items = nil")
+	assert.Contains(t, content, "x.Child = nil", "Should cut the recursive redaction")
+	testFixture(t, "testdata/maxdepth")
+
+	generateFixture(t, nil, "testdata/maxdepth/maxdepth.proto")
+	content = readGenerated(t, "testdata/maxdepth/maxdepth.pb.redact.go")
+	assert.Equal(t, 4, strings.Count(content, "redact.Apply(x.Next)"), "Should be unlimited by default")
+
+	output, err := runFixture(t, []string{"max_depth=-1"}, "testdata/maxdepth/maxdepth.proto")
+	require.Error(t, err, "Should reject a negative max depth")
+	assert.Contains(t, output, "max_depth")
+}
+
+// TestWorkers tests the messages processed by the workers parameter generate
+// the same code as sequentially
+func TestWorkers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"workers=1"}, "testdata/maxdepth/maxdepth.proto")
+	sequential := readGenerated(t, "testdata/maxdepth/maxdepth.pb.redact.go")
+	generateFixture(t, []string{"workers=4"}, "testdata/maxdepth/maxdepth.proto")
+	assert.Equal(t, sequential, readGenerated(t, "testdata/maxdepth/maxdepth.pb.redact.go"))
+
+	output, err := runFixture(t, []string{"workers=-1"}, "testdata/maxdepth/maxdepth.proto")
+	require.Error(t, err, "Should reject a negative number of workers")
+	assert.Contains(t, output, "workers")
+}
+
+// TestClearUnknown tests the unknown fields are cleared on redaction with
+// clear_unknown
+func TestClearUnknown(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"clear_unknown=true"}, "testdata/clearunknown/clearunknown.proto")
+	content := readGenerated(t, "testdata/clearunknown/clearunknown.pb.redact.go")
+
+	assert.Equal(t, 1, strings.Count(content, "x.ProtoReflect().SetUnknown(nil)"), "Should not clear the ignored messages")
+	testFixture(t, "testdata/clearunknown")
+
+	generateFixture(t, nil, "testdata/clearunknown/clearunknown.proto")
+	content = readGenerated(t, "testdata/clearunknown/clearunknown.pb.redact.go")
+	assert.NotContains(t, content, "SetUnknown", "Should keep the unknown fields by default")
+}
+
+// TestSharedEmpty tests the messages redacted to empty share a package-level
+// empty value with shared_empty
+func TestSharedEmpty(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"shared_empty=true"}, "testdata/sharedempty/sharedempty.proto")
+	content := readGenerated(t, "testdata/sharedempty/sharedempty.pb.redact.go")
+
+	assert.Equal(t, 1, strings.Count(content, "redactedEmptyConfig *Config = &Config{}"), "Should declare the empty value once")
+	assert.Contains(t, content, "x.Primary = redactedEmptyConfig")
+	assert.Contains(t, content, "x.Replicas[k] = redactedEmptyConfig")
+	testFixture(t, "testdata/sharedempty")
+
+	generateFixture(t, nil, "testdata/sharedempty/sharedempty.proto")
+	content = readGenerated(t, "testdata/sharedempty/sharedempty.pb.redact.go")
+	assert.Contains(t, content, "x.Primary = &Config{}", "Should allocate the empty values by default")
+	assert.NotContains(t, content, "redactedEmptyConfig")
+}
+
+// TestResetEmpty tests the messages redacted to empty are reset in place with
+// reset_empty, which cannot be combined with shared_empty
+func TestResetEmpty(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"reset_empty=true"}, "testdata/resetempty/resetempty.proto")
+	content := readGenerated(t, "testdata/resetempty/resetempty.pb.redact.go")
+
+	assert.Contains(t, content, "x.Primary.Reset()")
+	assert.Contains(t, content, "x.Primary = &Config{}", "Should allocate the nil messages")
+	assert.Contains(t, content, "v.Override.Reset()")
+	assert.Contains(t, content, "x.Replicas[k] = &Config{}", "Should replace the items")
+	testFixture(t, "testdata/resetempty")
+
+	output, err := runFixture(t, []string{"reset_empty=true", "shared_empty=true"}, "testdata/resetempty/resetempty.proto")
+	require.Error(t, err, "Should reject shared_empty")
+	assert.Contains(t, output, "mutually exclusive")
+}
+
+// TestZeroFill tests the bytes fields are zeroed keeping their length
+func TestZeroFill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/zerofill/zerofill.proto")
+	content := readGenerated(t, "testdata/zerofill/zerofill.pb.redact.go")
+
+	assert.Contains(t, content, "x.Sig = make([]byte, len(x.Sig))")
+	assert.Contains(t, content, "x.Chain[k] = make([]byte, len(x.Chain[k]))")
+	assert.Contains(t, content, "v.PublicKey = make([]byte, len(v.PublicKey))")
+	testFixture(t, "testdata/zerofill")
+}
+
+// TestCollapse tests the non-empty lists are collapsed to a single sentinel
+// item
+func TestCollapse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/collapse/collapse.proto")
+	content := readGenerated(t, "testdata/collapse/collapse.pb.redact.go")
+
+	assert.Contains(t, content, "if len(x.Tags) > 0 {\n\t\tx.Tags = []string{\"REDACTED\"}")
+	assert.Contains(t, content, "x.Ids = []int64{-1}")
+	assert.Contains(t, content, "x.Roles = []Role{0}")
+	testFixture(t, "testdata/collapse")
+
+	output, err := runFixture(t, nil, "testdata/collapse/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the collapsed maps")
+	assert.Contains(t, output, "repeated scalar field")
+}
+
+// TestRuleLabels tests the repeated fields only accept element rules, and the
+// singular fields never do
+func TestRuleLabels(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output, err := runFixture(t, nil, "testdata/rulelabels/invalid/repeated.proto")
+	require.Error(t, err, "Should reject the message rules of the repeated fields")
+	assert.Contains(t, output, "repeated field .rulelabels.invalid.Repeated.items")
+	assert.Contains(t, output, "got (redact.custom).message.*")
+
+	output, err = runFixture(t, nil, "testdata/rulelabels/invalid/singular.proto")
+	require.Error(t, err, "Should reject the element rules of the singular fields")
+	assert.Contains(t, output, "singular field .rulelabels.invalid.Singular.entry")
+	assert.Contains(t, output, "expected (redact.custom).message")
+}
+
+// TestExcept tests all the fields of the except messages are redacted by
+// default, but the listed ones
+func TestExcept(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output := generateFixture(t, nil, "testdata/except/except.proto")
+	content := readGenerated(t, "testdata/except/except.pb.redact.go")
+
+	assert.Contains(t, output, `lists the unknown field "unknown"`, "Should warn about the unknown names")
+	assert.Contains(t, content, "// Safe field: Id")
+	assert.Contains(t, content, "// Safe field: CreatedAt")
+	assert.Contains(t, content, `x.Name = "REDACTED"`)
+	assert.Contains(t, content, `x.Note = "custom"`, "Should apply the rules of the fields")
+	assert.Contains(t, content, "// Redact: birth_year->0, contact->nested, diagnoses->nil, name->\"REDACTED\",\n// note->\"custom\"\n",
+		"Should document the redacted fields of Redact()")
+	testFixture(t, "testdata/except")
+
+	output, err := runFixture(t, nil, "testdata/except/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the listed fields with rules")
+	assert.Contains(t, output, "either (redact.v3.except) or a (redact.v3.value) rule")
+}
+
+// TestRegexReplace tests the matches of the regex_replace patterns are replaced
+// in the string fields, with the patterns compiled once in package vars
+func TestRegexReplace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/regexreplace/regexreplace.proto")
+	content := readGenerated(t, "testdata/regexreplace/regexreplace.pb.redact.go")
+
+	assert.Contains(t, content, `"regexp"`)
+	assert.Regexp(t, `redactRegexpTicket_Body\s+\*regexp.Regexp = regexp.MustCompile\("\\\\d"\)`, content)
+	assert.Contains(t, content, `x.Body = redactRegexpTicket_Body.ReplaceAllString(x.Body, "#")`)
+	assert.Contains(t, content, `*x.Email = redactRegexpTicket_Email.ReplaceAllString(*x.Email, "***@")`)
+	assert.Contains(t, content, `v.Phone = redactRegexpTicket_Phone.ReplaceAllString(v.Phone, "0")`)
+	testFixture(t, "testdata/regexreplace")
+
+	output, err := runFixture(t, nil, "testdata/regexreplace/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the patterns which do not compile")
+	assert.Contains(t, output, "missing closing )")
+}
+
+// TestGatedBy tests the messages with gated_by are redacted only when their
+// gate is true, and that the gate is validated
+func TestGatedBy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/gatedby/gatedby.proto")
+	content := readGenerated(t, "testdata/gatedby/gatedby.pb.redact.go")
+
+	assert.Contains(t, content, "if !x.GetIsSensitive() {")
+	assert.Contains(t, content, "if !x.GetPrivate() {")
+	assert.Contains(t, content, "// Safe field: Private", "Should keep the gate")
+	testFixture(t, "testdata/gatedby")
+
+	output, err := runFixture(t, nil, "testdata/gatedby/invalid/unknown.proto")
+	require.Error(t, err, "Should reject the unknown gates")
+	assert.Contains(t, output, `unknown field "sensitive"`)
+
+	output, err = runFixture(t, nil, "testdata/gatedby/invalid/type.proto")
+	require.Error(t, err, "Should reject the gates which are not bool")
+	assert.Contains(t, output, "singular bool field for (redact.v3.gated_by)")
+}
+
+// TestMessageDepth tests the nested redaction is limited to the depth of the
+// message rules of the fields, through the redactDepth() methods
+func TestMessageDepth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	for _, opts := range [][]string{nil, {"fallible=true"}} {
+		generateFixture(t, opts, "testdata/depth/depth.proto")
+		content := readGenerated(t, "testdata/depth/depth.pb.redact.go")
+
+		assert.Contains(t, content, "func (x *Customer) redactDepth(depth int)")
+		assert.Contains(t, content, "x.redactDepth(0)", "Should redact without limit by Redact()")
+		assert.Contains(t, content, "x.Customer.redactDepth(redact.NestedDepth(depth, 1))")
+		assert.Contains(t, content, "x.Full.redactDepth(redact.NestedDepth(depth, 0))")
+		assert.Contains(t, content, "if depth != 1 && x.Address != nil {")
+		testFixture(t, "testdata/depth")
+	}
+
+	generateFixture(t, nil, "testdata/recursive/recursive.proto")
+	content := readGenerated(t, "testdata/recursive/recursive.pb.redact.go")
+	assert.NotContains(t, content, "redactDepth", "Should not change the files without depth")
+
+	output, err := runFixture(t, nil, "testdata/depth/invalid/negative.proto")
+	require.Error(t, err, "Should reject the negative depths")
+	assert.Contains(t, output, "positive (redact.custom).message.depth")
+
+	output, err = runFixture(t, nil, "testdata/depth/depth.proto", "testdata/depth/invalid/imported.proto")
+	require.Error(t, err, "Should reject the depths of the messages of other files")
+	assert.Contains(t, output, "(redact.custom).message.depth of a message of the same file")
+}
+
+// TestInvalidStatusCode tests the invalid status codes are reported on the
+// methods using them, with whether these are set on the method or inherited
+func TestInvalidStatusCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output, err := runFixture(t, nil, "testdata/statuscode/invalid/inherited.proto")
+	require.Error(t, err, "Should reject the invalid code inherited from the service")
+	assert.Contains(t, output, "status code of method .statuscode.Admin.Purge")
+	assert.Contains(t, output, "42 from (redact.v3.internal_service_code) inherited from .statuscode.Admin")
+	assert.NotContains(t, output, "statuscode.Admin.Audit", "Should accept the method overriding the code")
+
+	output, err = runFixture(t, nil, "testdata/statuscode/invalid/explicit.proto")
+	require.Error(t, err, "Should reject the invalid code of the method")
+	assert.Contains(t, output, "99 from (redact.v3.internal_method_code) of the method")
+}
+
+// TestRequireRedaction tests require_redaction fails the generation for the
+// messages without redacted fields, unless ignored or redacted as a whole
+func TestRequireRedaction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"require_redaction=true"}, "testdata/requireredaction/requireredaction.proto")
+	buildFixture(t, "testdata/requireredaction")
+
+	output, err := runFixture(t, []string{"require_redaction=true"}, "testdata/requireredaction/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the messages without redacted fields")
+	assert.Contains(t, output, ".requireredaction.Status, .requireredaction.Profile redact no field")
+	assert.NotContains(t, output, ".requireredaction.Account")
+
+	generateFixture(t, nil, "testdata/requireredaction/invalid/invalid.proto")
+}
+
+// TestDefaultExpressions tests the default_<type>_expr parameters redact to the
+// expressions, importing their packages
+func TestDefaultExpressions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	opts := []string{
+		"default_string_expr=github.com/menta2k/protoc-gen-redact/v3/testdata/defaultexpr/mask.Placeholder()",
+		"default_int64_expr=math.MinInt64",
+		`default_bytes_expr=[]byte(strings.ToUpper("redacted"))`,
+	}
+	generateFixture(t, opts, "testdata/defaultexpr/defaultexpr.proto")
+	content := readGenerated(t, "testdata/defaultexpr/defaultexpr.pb.redact.go")
+
+	assert.Contains(t, content, "x.Holder = mask.Placeholder()")
+	assert.Contains(t, content, "x.Notes[k] = mask.Placeholder()")
+	assert.Contains(t, content, "x.Amount = math.MinInt64")
+	assert.Contains(t, content, `x.Signature = []byte(strings.ToUpper("redacted"))`)
+	assert.Contains(t, content, `x.Card = "hidden"`, "Should keep the explicit values")
+	assert.Contains(t, content, `mask "github.com/menta2k/protoc-gen-redact/v3/testdata/defaultexpr/mask"`)
+	assert.Contains(t, content, "_ = func() int64 { return math.MinInt64 }", "Should type-check the expressions")
+	testFixture(t, "testdata/defaultexpr")
+
+	t.Run("invalid_expression", func(t *testing.T) {
+		output, err := runFixture(t, []string{"default_string_expr=mask.Placeholder("}, "testdata/defaultexpr/defaultexpr.proto")
+		require.Error(t, err, "Should fail with an unbalanced expression")
+		assert.Contains(t, output, "default_string_expr")
+	})
+
+	t.Run("with_literal", func(t *testing.T) {
+		output, err := runFixture(t, []string{"default_string=x", "default_string_expr=mask.Placeholder()"}, "testdata/defaultexpr/defaultexpr.proto")
+		require.Error(t, err, "Should fail with both a literal and an expression")
+		assert.Contains(t, output, "mutually exclusive")
+	})
+}
+
+// TestRedactDeprecated tests the deprecated fields are redacted to their
+// defaults with redact_deprecated, and kept without it
+func TestRedactDeprecated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/deprecated/deprecated.proto")
+	content := readGenerated(t, "testdata/deprecated/deprecated.pb.redact.go")
+	assert.NotContains(t, content, "x.Ssn =", "Should keep the deprecated fields by default")
+	assert.Contains(t, content, `x.LegacyToken = "hidden"`, "Should apply the rules of the deprecated fields")
+
+	generateFixture(t, []string{"redact_deprecated=true"}, "testdata/deprecated/deprecated.proto")
+	content = readGenerated(t, "testdata/deprecated/deprecated.pb.redact.go")
+	assert.Contains(t, content, `x.Ssn = "REDACTED"`)
+	assert.Contains(t, content, `x.LegacyToken = "hidden"`)
+	assert.Contains(t, content, "x.LegacyEmails = nil")
+	assert.NotContains(t, content, "x.LegacyId =", "Should keep the allowed deprecated fields")
+	assert.NotContains(t, content, "x.Name =")
+	testFixture(t, "testdata/deprecated")
+}
+
+// TestRedactedJSON tests the redacted clones are marshalled with the JSON names
+// of the fields with redacted_json
+func TestRedactedJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	for _, opts := range [][]string{{"redacted_json=true"}, {"redacted_json=true", "fallible=true"}} {
+		generateFixture(t, opts, "testdata/redactedjson/redactedjson.proto")
+		content := readGenerated(t, "testdata/redactedjson/redactedjson.pb.redact.go")
+
+		assert.Contains(t, content, "func (x *Account) RedactedJSON() ([]byte, error) {")
+		assert.Contains(t, content, `protojson "google.golang.org/protobuf/encoding/protojson"`)
+		assert.NotContains(t, content, "RedactedString", "Should not generate the stringer methods")
+		testFixture(t, "testdata/redactedjson")
+	}
+
+	generateFixture(t, nil, "testdata/redactedjson/redactedjson.proto")
+	content := readGenerated(t, "testdata/redactedjson/redactedjson.pb.redact.go")
+	assert.NotContains(t, content, "RedactedJSON", "Should not generate the method by default")
+	assert.NotContains(t, content, "protojson", "Should not import protojson by default")
+}
+
+// TestMagnitude tests the integer fields are redacted to their order of
+// magnitude
+func TestMagnitude(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/magnitude/magnitude.proto")
+	content := readGenerated(t, "testdata/magnitude/magnitude.pb.redact.go")
+
+	assert.Contains(t, content, "x.Requests = redact.Magnitude(x.Requests)")
+	assert.Contains(t, content, "*x.BytesSent = redact.Magnitude(*x.BytesSent)")
+	assert.Contains(t, content, "x.Latencies[k] = redact.Magnitude(x.Latencies[k])")
+	assert.Contains(t, content, "v.Daily = redact.Magnitude(v.Daily)")
+	testFixture(t, "testdata/magnitude")
+
+	generateFixture(t, []string{"emit_metadata"}, "testdata/magnitude/magnitude.proto")
+	meta := &fileMetadata{}
+	require.NoError(t, json.Unmarshal([]byte(readGenerated(t, "testdata/magnitude/magnitude.pb.redact.json")), meta))
+	require.Len(t, meta.Messages, 1)
+	assert.Contains(t, meta.Messages[0].Fields,
+		&fieldMetadata{Name: "Requests", Type: "int64", Redact: true, Strategy: strategyMagnitude})
+
+	output, err := runFixture(t, nil, "testdata/magnitude/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the magnitude of a double")
+	assert.Contains(t, output, "integer field for (redact.custom).magnitude")
+}
+
+// TestSeparatePackage tests separate_package generates the redaction as
+// functions of a sibling package, importing the package of the messages
+func TestSeparatePackage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// the generated files of the sibling packages are not removed by runFixture
+	t.Cleanup(func() {
+		os.RemoveAll("testdata/crosspkg/common/redactgen")
+		matches, _ := filepath.Glob("testdata/separatepkg/redactgen/*.pb.redact.go")
+		for _, file := range matches {
+			os.Remove(file)
+		}
+	})
+
+	generateFixture(t, []string{"separate_package=redactgen"},
+		"testdata/separatepkg/separatepkg.proto",
+		"testdata/crosspkg/common/common.proto",
+	)
+	content := readGenerated(t, "testdata/separatepkg/redactgen/separatepkg.pb.redact.go")
+
+	assert.Contains(t, content, "package redactgen")
+	assert.Contains(t, content, `separatepkg "github.com/menta2k/protoc-gen-redact/v3/testdata/separatepkg"`,
+		"Should import the package of the messages")
+	assert.Contains(t, content, "func RedactAccount(x *separatepkg.Account) string {", "Should generate functions")
+	assert.Contains(t, content, "RedactAccount_Card(x.Card)", "Should call the functions of the nested messages")
+	assert.Contains(t, content, "x.Secret.(*separatepkg.Account_Backup)", "Should reference the oneof wrappers")
+	assert.Contains(t, content, "redact.ApplyReflect(x.Profile)", "Should redact the messages of other packages by reflection")
+	assert.Contains(t, content, "separatepkg.RegisterAccountsServer(s, RedactedAccountsServer(srv, bypass))")
+	assert.Contains(t, content, "RedactGetAccountRequest(in)", "Should redact the requests")
+	assert.Contains(t, content, "redactgrpc.RedactedClientConnFunc(cc, redactAccountsClientReply,")
+	assert.NotContains(t, content, "var _ redact.Redactor", "Should not assert Redact() methods")
+	assert.NoFileExists(t, "testdata/separatepkg/separatepkg.pb.redact.go", "Should not generate next to the messages")
+	testFixture(t, "testdata/separatepkg/redactgen")
+
+	output, err := runFixture(t, []string{"separate_package=redactgen", "stringer=true"}, "testdata/separatepkg/separatepkg.proto")
+	require.Error(t, err, "Should reject the methods of the messages")
+	assert.Contains(t, output, "separate_package")
+
+	output, err = runFixture(t, []string{"separate_package=redactgen"}, "testdata/depth/depth.proto")
+	require.Error(t, err, "Should reject the depth limits, redacted by methods")
+	assert.Contains(t, output, "separate_package")
+}
+
+// TestGroupFields tests the proto2 groups are rejected by protoc-gen-star
+// when building the files, before any rule is read
+func TestGroupFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	output, err := runFixture(t, nil, "testdata/groups/invalid/groups.proto")
+	require.Error(t, err, "Should reject the groups")
+	assert.Contains(t, output, "group types are deprecated and unsupported")
+}
+
+// TestFileDefaultString tests the strings are redacted to the default of the
+// file, and the empty default is rejected
+func TestFileDefaultString(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"default_string=ignored"}, "testdata/filedefault/filedefault.proto")
+	content := readGenerated(t, "testdata/filedefault/filedefault.pb.redact.go")
+
+	assert.Contains(t, content, `x.Name = "[MASKED]"`, "Should win over default_string")
+	assert.Contains(t, content, `x.Hints[k] = "[MASKED]"`)
+	assert.Contains(t, content, `x.User = "anonymous"`, "Should keep the explicit values")
+	assert.NotContains(t, content, "ignored")
+	testFixture(t, "testdata/filedefault")
+
+	output, err := runFixture(t, nil, "testdata/filedefault/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the empty default")
+	assert.Contains(t, output, "a non-empty (redact.v3.file_default_string)")
+}
+
+// TestRegisterRequests tests the requests of the services are registered for
+// redact.RedactRequest with register_requests
+func TestRegisterRequests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"register_requests=true"}, "testdata/registerrequests/registerrequests.proto")
+	content := readGenerated(t, "testdata/registerrequests/registerrequests.pb.redact.go")
+
+	assert.Equal(t, 1, strings.Count(content, "redact.RegisterRequest((*LoginRequest)(nil)"), "Should register the requests once")
+	assert.NotContains(t, content, "redact.RegisterRequest((*emptypb.Empty)(nil)", "Should not register the imported requests")
+	testFixture(t, "testdata/registerrequests")
+
+	generateFixture(t, nil, "testdata/registerrequests/registerrequests.proto")
+	content = readGenerated(t, "testdata/registerrequests/registerrequests.pb.redact.go")
+	assert.NotContains(t, content, "redact.RegisterRequest", "Should not register the requests by default")
+}
+
+// TestEmptyFactory tests the messages with empty_factory are redacted to empty
+// by calling their factory, in their own package and in the importing ones
+func TestEmptyFactory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/emptyfactory/emptyfactory.proto",
+		"testdata/emptyfactory/consumer/consumer.proto",
+	)
+	content := readGenerated(t, "testdata/emptyfactory/emptyfactory.pb.redact.go")
+	consumer := readGenerated(t, "testdata/emptyfactory/consumer/consumer.pb.redact.go")
+
+	assert.Contains(t, content, "x.Config = NewRedactedConfig()")
+	assert.Contains(t, content, "x.History[k] = NewRedactedConfig()")
+	assert.Contains(t, content, "res = NewRedactedSettings()")
+	assert.Regexp(t, `_ func\(\) \*Config\s+= NewRedactedConfig`, content, "Should assert the factory signature")
+	assert.Contains(t, consumer, "x.Config = emptyfactory.NewRedactedConfig()")
+	testFixture(t, "testdata/emptyfactory")
+	testFixture(t, "testdata/emptyfactory/consumer")
+
+	output, err := runFixture(t, nil, "testdata/emptyfactory/invalid/invalid.proto")
+	require.Error(t, err, "Should reject the factories without import path")
+	assert.Contains(t, output, "(redact.v3.empty_factory) formatted as <import-path>.<Name>")
+}
+
+// TestOptionalEnum tests the proto3 optional enum fields are redacted through
+// a temporary variable, keeping their presence
+func TestOptionalEnum(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/optionalenum/optionalenum.proto",
+		"testdata/optionalenum/common/common.proto",
+	)
+	content := readGenerated(t, "testdata/optionalenum/optionalenum.pb.redact.go")
+
+	assert.Contains(t, content, "StatusTmp := Status(2)\n\tx.Status = &StatusTmp")
+	assert.Contains(t, content, "LevelTmp := common.Level(0)\n\tx.Level = &LevelTmp", "Should use the alias of the imported enums")
+	assert.Contains(t, content, "x.Current = 2", "Should assign the non-optional enums directly")
+	testFixture(t, "testdata/optionalenum")
+}
+
+// TestIgnoredEmbed tests the nested calls of the ignored messages are skipped
+func TestIgnoredEmbed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/ignoredembed/ignoredembed.proto")
+	content := readGenerated(t, "testdata/ignoredembed/ignoredembed.pb.redact.go")
+
+	assert.NotContains(t, content, "redact.Apply(", "Should not call the redaction of the ignored messages")
+	for _, name := range []string{"Audit", "History", "Audits", "Origin"} {
+		assert.Contains(t, content, "// "+name+" redaction is skipped")
+	}
+	testFixture(t, "testdata/ignoredembed")
+}
+
+// TestAutoNested tests the fields embedding an auto_nested message, without
+// rules of their own, are nested-redacted
+func TestAutoNested(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/autonested/autonested.proto")
+	content := readGenerated(t, "testdata/autonested/autonested.pb.redact.go")
+
+	for _, call := range []string{"x.Contact", "x.Aliases[k]", "x.Relatives[k]", "v.Referrer", "x.Billing"} {
+		assert.Contains(t, content, "redact.Apply("+call+")")
+	}
+	assert.Contains(t, content, "// Safe field: Public", "Should keep the allowed fields")
+	assert.Contains(t, content, "x.Shipping = nil", "Should keep the explicit rules")
+	testFixture(t, "testdata/autonested")
+}
+
+// TestMapKeyTypes tests the empty map literals render the Go types of all the
+// map key types
+func TestMapKeyTypes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/mapkeytypes/mapkeytypes.proto")
+	content := readGenerated(t, "testdata/mapkeytypes/mapkeytypes.pb.redact.go")
+
+	for _, literal := range []string{
+		"x.ByName = map[string]*Item{}",
+		"x.ByInt32 = map[int32]*Item{}",
+		"x.ByInt64 = map[int64]*Item{}",
+		"x.ByUint64 = map[uint64]*Item{}",
+		"x.BySint32 = map[int32]*Item{}",
+		"x.ByFixed64 = map[uint64]*Item{}",
+		"x.BySfixed64 = map[int64]*Item{}",
+		"x.ByFlag = map[bool]*Item{}",
+		"x.Labels = map[int64]string{}",
+	} {
+		assert.Contains(t, content, literal)
+	}
+	testFixture(t, "testdata/mapkeytypes")
+}
+
+// TestAudit tests the redacted fields are reported through redact.Audit with
+// audit
+func TestAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"audit=true"}, "testdata/audit/audit.proto")
+	content := readGenerated(t, "testdata/audit/audit.pb.redact.go")
+
+	assert.Contains(t, content, `redact.Audit("audit.Account", "password")`)
+	assert.NotContains(t, content, `redact.Audit("audit.Account", "username")`, "Should not report the safe fields")
+	assert.NotContains(t, content, `redact.Audit("audit.Account", "backup")`, "Should not report the skipped fields")
+	testFixture(t, "testdata/audit")
+
+	generateFixture(t, nil, "testdata/audit/audit.proto")
+	content = readGenerated(t, "testdata/audit/audit.pb.redact.go")
+	assert.NotContains(t, content, "redact.Audit(", "Should not report the fields by default")
+}
+
+// TestRedactedFields tests the RedactedFields() methods list the fields
+// redacted by the messages with redacted_fields
+func TestRedactedFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"redacted_fields=true"}, "testdata/redactedfields/redactedfields.proto")
+	content := readGenerated(t, "testdata/redactedfields/redactedfields.pb.redact.go")
+
+	assert.Contains(t, content, `var redactedFieldsAccount = []string{"password", "profile", "pin"}`)
+	testFixture(t, "testdata/redactedfields")
+
+	generateFixture(t, nil, "testdata/redactedfields/redactedfields.proto")
+	content = readGenerated(t, "testdata/redactedfields/redactedfields.pb.redact.go")
+	assert.NotContains(t, content, "RedactedFields()", "Should not generate the methods by default")
+}
+
+// TestTemplateFallback tests the template parameter falls back to the
+// embedded template when the template is invalid
+func TestTemplateFallback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.tmpl")
+	require.NoError(t, os.WriteFile(custom, []byte("package {{ .Package }}\n\n// custom layout\n"), 0o600))
+	generateFixture(t, []string{"template=" + custom}, "testdata/messagesonly/messagesonly.proto")
+	content := readGenerated(t, "testdata/messagesonly/messagesonly.pb.redact.go")
+	assert.Contains(t, content, "// custom layout", "Should use the custom template")
+
+	for name, tpl := range map[string]string{
+		"unparsable.tmpl":    "package {{ .Package ",
+		"unknown_field.tmpl": "package {{ .PackageName }}\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(tpl), 0o600))
+		output := generateFixture(t, []string{"template=" + path}, "testdata/messagesonly/messagesonly.proto")
+		assert.Contains(t, output, "using the embedded template", "Should warn about %s", name)
+		content := readGenerated(t, "testdata/messagesonly/messagesonly.pb.redact.go")
+		assert.Contains(t, content, "func (x *Credentials) Redact() string", "Should fall back for %s", name)
+	}
+	generateFixture(t, []string{"template=" + filepath.Join(dir, "missing.tmpl")}, "testdata/messagesonly/messagesonly.proto")
+	testFixture(t, "testdata/messagesonly")
+
+	output, err := runFixture(t, []string{"template=" + custom, "template_file=" + custom}, "testdata/messagesonly/messagesonly.proto")
+	require.Error(t, err)
+	assert.Contains(t, output, "mutually exclusive")
+}
+
+// TestGroupImports tests the imports of the generated files are sorted in
+// goimports-style groups
+func TestGroupImports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	predicate := "ctx_predicate=github.com/menta2k/protoc-gen-redact/v3/testdata/ctxpredicate/predicate.ShouldRedact"
+	generateFixture(t, []string{predicate, "proof=true", "group_imports=true"}, "testdata/ctxpredicate/ctxpredicate.proto")
+	content := readGenerated(t, "testdata/ctxpredicate/ctxpredicate.pb.redact.go")
+
+	assert.Contains(t, content, "\ttime \"time\"\n\n\tgrpc \"google.golang.org/grpc\"\n",
+		"Should separate the standard library from the external packages")
+	assert.Contains(t, content, "\tstatus \"google.golang.org/grpc/status\"\n\n\tredact \"github.com/menta2k/protoc-gen-redact/v3/redact/v3\"\n",
+		"Should separate the local packages of the module")
+	testFixture(t, "testdata/ctxpredicate")
+
+	generateFixture(t, []string{predicate, "group_imports=true", "local_prefix=google.golang.org/grpc"}, "testdata/ctxpredicate/ctxpredicate.proto")
+	content = readGenerated(t, "testdata/ctxpredicate/ctxpredicate.pb.redact.go")
+	assert.Contains(t, content, "\tpredicate \"github.com/menta2k/protoc-gen-redact/v3/testdata/ctxpredicate/predicate\"\n\n\tgrpc \"google.golang.org/grpc\"\n",
+		"Should use local_prefix for the local packages")
+
+	generateFixture(t, []string{predicate}, "testdata/ctxpredicate/ctxpredicate.proto")
+	content = readGenerated(t, "testdata/ctxpredicate/ctxpredicate.pb.redact.go")
+	assert.NotContains(t, content, "\"context\"\n\n", "Should not group the imports by default")
+}
+
+// TestImportReferences tests the imports are referenced by their top-level
+// message types, and service only imports are skipped
+func TestImportReferences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/refs/svconly/svconly.proto",
+		"testdata/refs/nested/nested.proto",
+		"testdata/refs/refs.proto",
+	)
+	content := readGenerated(t, "testdata/refs/refs.pb.redact.go")
+
+	assert.Contains(t, content, "_ *nested.Outer", "Should reference the top-level message")
+	assert.NotContains(t, content, "svconly", "Should skip service only imports")
+	buildFixture(t, "testdata/refs")
+}
+
+// TestImportChainReferences tests a package imported through a chain of
+// imports, and through several of its files, is referenced once
+func TestImportChainReferences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil,
+		"testdata/importchain/leaf/leaf.proto",
+		"testdata/importchain/leaf/kind.proto",
+		"testdata/importchain/branch/branch.proto",
+		"testdata/importchain/importchain.proto",
+	)
+	content := readGenerated(t, "testdata/importchain/importchain.pb.redact.go")
+
+	assert.Contains(t, content, "_ *branch.Branch")
+	assert.Contains(t, content, "_ leaf.Kind", "Should reference the first imported file of the package")
+	assert.NotContains(t, content, "_ *leaf.Leaf", "Should reference the package once")
+	buildFixture(t, "testdata/importchain")
+}
+
+// TestReproducibleOutput tests generating the same proto files twice gives
+// byte-identical files
+func TestReproducibleOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	files := []string{
+		"testdata/crosspkg/common/common.proto",
+		"testdata/crosspkg/crosspkg.proto",
+		"testdata/wellknown/wellknown.proto",
+	}
+	generated := []string{
+		"testdata/crosspkg/crosspkg.pb.redact.go",
+		"testdata/wellknown/wellknown.pb.redact.go",
+	}
+
+	generateFixture(t, nil, files...)
+	first := make([]string, 0, len(generated))
+	for _, path := range generated {
+		first = append(first, readGenerated(t, path))
+	}
+
+	for i := 0; i < 3; i++ {
+		generateFixture(t, nil, files...)
+		for j, path := range generated {
+			assert.Equal(t, first[j], readGenerated(t, path), "Generated %s should be identical", path)
+		}
+	}
+}
+
+// TestRedactorAssertions tests a compile-time redact.Redactor assertion is
+// generated for each message, except the ignored ones
+func TestRedactorAssertions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/assertions/assertions.proto")
+	content := readGenerated(t, "testdata/assertions/assertions.pb.redact.go")
+
+	assert.Contains(t, content, "var _ redact.Redactor = (*Login)(nil)", "Should assert redacted messages")
+	assert.NotContains(t, content, "(*Audit)(nil)", "Should skip ignored messages")
+	buildFixture(t, "testdata/assertions")
+}
+
+// TestFallible tests the Redact() methods return an error with fallible, the
+// errors of the nested calls being propagated
+func TestFallible(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, []string{"fallible=true"}, "testdata/fallible/fallible.proto")
+	content := readGenerated(t, "testdata/fallible/fallible.pb.redact.go")
+
+	assert.Contains(t, content, "func (x *Wallet) Redact() error {", "Should return an error")
+	assert.Contains(t, content, "var _ redact.FallibleRedactor = (*Wallet)(nil)", "Should assert the fallible interface")
+	assert.Contains(t, content, "if err := redact.Apply(x.Primary); err != nil {", "Should propagate nested errors")
+	assert.Contains(t, content, "if err := redact.Apply(res); err != nil {", "Should fail the response on errors")
+	testFixture(t, "testdata/fallible")
+}
+
+// TestRedactedClient tests the generated clients redact the replies of the
+// unary methods, but the skipped ones
+func TestRedactedClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t, nil, "testdata/clientredact/clientredact.proto")
+	content := readGenerated(t, "testdata/clientredact/clientredact.pb.redact.go")
+
+	assert.Contains(t, content, `NewTokensClient(redactgrpc.RedactedClientConn(cc, "/clientredact.Tokens/GetRawToken"))`)
+	testFixture(t, "testdata/clientredact")
+
+	generateFixture(t, []string{"build_tag=redact"}, "testdata/clientredact/clientredact.proto")
+	content = readGenerated(t, "testdata/clientredact/clientredact.pb.redact.noredact.go")
+	assert.Contains(t, content, "return NewTokensClient(cc)", "Should return the plain client without the build tag")
+}
+
+// TestContextPredicate tests the redacted servers only redact the responses
+// when the ctx_predicate returns true
+func TestContextPredicate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	generateFixture(t,
+		[]string{"ctx_predicate=github.com/menta2k/protoc-gen-redact/v3/testdata/ctxpredicate/predicate.ShouldRedact"},
+		"testdata/ctxpredicate/ctxpredicate.proto",
+	)
+	content := readGenerated(t, "testdata/ctxpredicate/ctxpredicate.pb.redact.go")
+
+	assert.Contains(t, content, `predicate "github.com/menta2k/protoc-gen-redact/v3/testdata/ctxpredicate/predicate"`,
+		"Should import the predicate package")
+	assert.Contains(t, content, "if !s.bypass.CheckInternal(ctx) && predicate.ShouldRedact(ctx) {",
+		"Should check the predicate")
+	testFixture(t, "testdata/ctxpredicate")
+
+	for _, invalid := range []string{"ShouldRedact", "github.com/acme/authz.shouldRedact", "github.com/acme.v2/authz"} {
+		output, err := runFixture(t, []string{"ctx_predicate=" + invalid}, "testdata/ctxpredicate/ctxpredicate.proto")
+		assert.Error(t, err, "Should reject %s", invalid)
+		assert.Contains(t, output, "ctx_predicate")
+	}
+}
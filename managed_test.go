@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsGo "github.com/lyft/protoc-gen-star/v2/lang/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// managedRequest builds a CodeGeneratorRequest as sent by buf in managed mode,
+// where the go_package options are injected and absent from the proto files
+func managedRequest(t *testing.T) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	common := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("acme/common/v1/common.proto"),
+		Package: proto.String("acme.common.v1"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("buf.build/gen/go/acme/common/v1;commonv1"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Profile"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+	}
+
+	secretOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(secretOpts, redact.E_Value, &redact.FieldRules{
+		Values: &redact.FieldRules_String_{String_: "hidden"},
+	})
+	profileOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(profileOpts, redact.E_Value, &redact.FieldRules{
+		Values: &redact.FieldRules_Message{Message: &redact.MessageRules{Empty: true}},
+	})
+	target := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("acme/account/v1/account.proto"),
+		Package:    proto.String("acme.account.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto", "acme/common/v1/common.proto"},
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("buf.build/gen/go/acme/account/v1;accountv1"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Account"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("secret"),
+				JsonName: proto.String("secret"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options:  secretOpts,
+			}, {
+				Name:     proto.String("profile"),
+				JsonName: proto.String("profile"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".acme.common.v1.Profile"),
+				Options:  profileOpts,
+			}},
+		}},
+	}
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{target.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(redact.File_redact_v3_redact_proto),
+			common,
+			target,
+		},
+	}
+}
+
+// generatedFiles returns the content of the generated files by name
+func generatedFiles(res *pluginpb.CodeGeneratorResponse) map[string]string {
+	files := make(map[string]string, len(res.GetFile()))
+	for _, f := range res.GetFile() {
+		files[f.GetName()] = f.GetContent()
+	}
+	return files
+}
+
+// TestBufManagedMode tests the generated code follows the go_package injected
+// in the request, the same way protoc-gen-go does
+func TestBufManagedMode(t *testing.T) {
+	req := managedRequest(t)
+	raw, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	pgs.Init(pgs.ProtocInput(bytes.NewReader(raw)), pgs.ProtocOutput(out)).
+		RegisterModule(Redactor()).
+		RegisterPostProcessor(pgsGo.GoFmt()).
+		Render()
+	res := &pluginpb.CodeGeneratorResponse{}
+	require.NoError(t, proto.Unmarshal(out.Bytes(), res))
+	require.Empty(t, res.GetError())
+
+	gen, err := protogen.Options{}.New(req)
+	require.NoError(t, err)
+	for _, f := range gen.Files {
+		if f.Generate {
+			internal_gengo.GenerateFile(gen, f)
+		}
+	}
+	goFiles := generatedFiles(gen.Response())
+	redactFiles := generatedFiles(res)
+
+	const pbName = "buf.build/gen/go/acme/account/v1/account.pb.go"
+	require.Contains(t, goFiles, pbName)
+	redactName := strings.TrimSuffix(pbName, ".go") + ".redact.go"
+	require.Contains(t, redactFiles, redactName, "Should be generated next to the .pb.go file")
+
+	pbGo, redacted := goFiles[pbName], redactFiles[redactName]
+	assert.Contains(t, pbGo, "package accountv1")
+	assert.Contains(t, redacted, "package accountv1", "Should use the injected package name")
+	assert.Contains(t, pbGo, `"buf.build/gen/go/acme/common/v1"`)
+	assert.Contains(t, redacted, `commonv1 "buf.build/gen/go/acme/common/v1"`, "Should use the injected import path")
+	assert.Contains(t, pbGo, "*v1.Profile")
+	assert.Contains(t, redacted, "x.Profile = &commonv1.Profile{}", "Should reference the imported type by its alias")
+}
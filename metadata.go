@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Redaction strategies of the messages and fields described in the metadata
+const (
+	strategyIgnore    = "ignore"
+	strategyNil       = "nil"
+	strategyEmpty     = "empty"
+	strategyFields    = "fields"
+	strategySafe      = "safe"
+	strategySkip      = "skip"
+	strategyNested    = "nested"
+	strategyPANMask   = "pan_mask"
+	strategyRegex     = "regex_replace"
+	strategyRound     = "round"
+	strategyMagnitude = "magnitude"
+	strategyCopy      = "copy"
+	strategyZero      = "zero_fill"
+	strategyCollapse  = "collapse"
+	strategyItems     = "items"
+	strategyKeys      = "keys"
+	strategyValue     = "value"
+)
+
+// fileMetadata is the JSON sidecar describing the redaction of a proto file
+type fileMetadata struct {
+	Source   string             `json:"source"`
+	Messages []*messageMetadata `json:"messages"`
+}
+
+// messageMetadata describes the redaction of a message
+type messageMetadata struct {
+	Name     string           `json:"name"`
+	Strategy string           `json:"strategy"`
+	Fields   []*fieldMetadata `json:"fields,omitempty"`
+}
+
+// fieldMetadata describes the redaction of a field
+type fieldMetadata struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Redact   bool   `json:"redact"`
+	Strategy string `json:"strategy"`
+	Value    string `json:"value,omitempty"`
+	Key      string `json:"key,omitempty"`
+}
+
+// metadata builds the JSON sidecar of the generated file from its template
+// data, the messages and their fields are sorted by name for a stable output
+func metadata(data *ProtoFileData) ([]byte, error) {
+	meta := &fileMetadata{
+		Source:   data.Source,
+		Messages: make([]*messageMetadata, 0, len(data.Messages)),
+	}
+	for _, msg := range data.Messages {
+		if msg == nil {
+			continue
+		}
+		msgMeta := &messageMetadata{Name: msg.Name, Strategy: strategyFields}
+		switch {
+		case msg.Ignore:
+			msgMeta.Strategy = strategyIgnore
+		case msg.ToNil:
+			msgMeta.Strategy = strategyNil
+		case msg.ToEmpty:
+			msgMeta.Strategy = strategyEmpty
+		default:
+			for _, field := range msg.Fields {
+				msgMeta.Fields = append(msgMeta.Fields, fieldMeta(field))
+			}
+			sort.Slice(msgMeta.Fields, func(i, j int) bool {
+				return msgMeta.Fields[i].Name < msgMeta.Fields[j].Name
+			})
+		}
+		meta.Messages = append(meta.Messages, msgMeta)
+	}
+	sort.Slice(meta.Messages, func(i, j int) bool {
+		return meta.Messages[i].Name < meta.Messages[j].Name
+	})
+	return json.MarshalIndent(meta, "", "  ")
+}
+
+// fieldMeta describes the redaction of the field, the value is only set for
+// the strategies replacing the field or its items by a value, and the key for
+// the maps whose keys are redacted
+func fieldMeta(field *FieldData) *fieldMetadata {
+	meta := &fieldMetadata{
+		Name:   field.Name,
+		Type:   field.FieldGoType,
+		Redact: field.Redact,
+	}
+	if field.EmbedMessageNameWithAlias != "" {
+		meta.Type = field.EmbedMessageNameWithAlias
+	}
+	switch {
+	case !field.Redact:
+		meta.Strategy = strategySafe
+	case field.EmbedSkip:
+		meta.Strategy = strategySkip
+	case field.NestedEmbedCall:
+		meta.Strategy = strategyNested
+		if field.Depth > 0 {
+			// the levels of the nested redaction
+			meta.Value = strconv.Itoa(field.Depth)
+		}
+	case field.PANMask:
+		meta.Strategy = strategyPANMask
+	case field.RegexVar != "":
+		// the Go literal of the pattern
+		meta.Strategy = strategyRegex
+		meta.Value = field.RegexPattern
+	case field.BytesZeroFill:
+		meta.Strategy = strategyZero
+	case field.CopyFrom != "":
+		// the Go name of the copied sibling field
+		meta.Strategy = strategyCopy
+		meta.Value = field.CopyFrom
+	case field.Magnitude:
+		meta.Strategy = strategyMagnitude
+	case field.RoundTo != "":
+		// the rounding factor, of the field or of its items
+		meta.Strategy = strategyRound
+		meta.Value = field.RoundTo
+	case field.Collapse:
+		// the list of the sentinel item
+		meta.Strategy = strategyCollapse
+		meta.Value = field.RedactionValue
+	case field.Iterate:
+		meta.Strategy = strategyItems
+		meta.Value = field.RedactionValue
+	case field.KeyRedact:
+		meta.Strategy = strategyKeys
+	default:
+		meta.Strategy = strategyValue
+		meta.Value = field.RedactionValue
+	}
+	if field.KeyRedact {
+		meta.Key = field.KeyRedactionValue
+		if field.KeyPANMask {
+			meta.Key = strategyPANMask
+		}
+	}
+	return meta
+}
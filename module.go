@@ -1,8 +1,11 @@
 package main
 
 import (
+	"go/token"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
@@ -18,6 +21,149 @@ type Module struct {
 	*pgs.ModuleBase
 	ctx  pgsGo.Context
 	tmpl *template.Template
+
+	// respectValidate checks redaction values against the field's validation
+	// rules (protoc-gen-validate/protovalidate)
+	respectValidate bool
+
+	// paramDefaults: per type redaction defaults, overridable by parameters
+	paramDefaults map[pgs.ProtoType]string
+
+	// defaults: redaction defaults of the processed file, the paramDefaults
+	// with the resolved defaultExprs
+	defaults map[pgs.ProtoType]string
+
+	// defaultExprs: per type Go expressions replacing the redaction defaults,
+	// resolved in the defaults with the import aliases of each file
+	defaultExprs map[pgs.ProtoType]*GoExpr
+
+	// varPlaceholders emits the redaction defaults as package-level vars,
+	// declared by the files in placeholderFiles (one per Go package)
+	varPlaceholders  bool
+	placeholderFiles map[string]bool
+
+	// runtimeMarker emits the string redaction default only as the
+	// RedactedStringValue package-level var, settable at runtime, declared as
+	// the placeholders by the files in placeholderFiles
+	runtimeMarker bool
+
+	// versionConst emits the RedactGenVersion constant, declared as the
+	// placeholders by the files in placeholderFiles
+	versionConst bool
+
+	// resetAndCopy resets the messages on redaction, copying back only the
+	// allowed and redacted fields
+	resetAndCopy bool
+
+	// warnNoopNested warns about nested redaction calls into messages without
+	// any redactable fields
+	warnNoopNested bool
+
+	// workers: number of messages processed concurrently, set by the workers
+	// parameter, 0 defaults to GOMAXPROCS, 1 processes the messages
+	// sequentially
+	workers int
+
+	// maxFieldLen caps the length of the string, bytes, repeated and map fields
+	// on redaction, 0 disables the caps
+	maxFieldLen int
+
+	// maxDepth bounds the nesting of the redaction calls, the nested messages
+	// beyond it are redacted to nil, 0 for unlimited
+	maxDepth int
+
+	// fallible generates Redact() methods returning an error instead of the
+	// string representation of the redacted message
+	fallible bool
+
+	// proof records the redacted fields through redact.RecordProof on
+	// redaction
+	proof bool
+
+	// audit reports each redacted field through redact.Audit on redaction
+	audit bool
+
+	// emitMetadata emits a JSON sidecar describing the redaction of each
+	// generated file
+	emitMetadata bool
+
+	// reportOnly writes a report of the redaction of each file instead of
+	// generating the code
+	reportOnly bool
+
+	// warnPII warns about the unredacted fields whose name contains one of the
+	// piiKeywords, strictPII fails the generation instead
+	warnPII     bool
+	strictPII   bool
+	piiKeywords []string
+
+	// redactDeprecated redacts the deprecated fields without rules to their
+	// defaults, as the fields of the all_fields messages
+	redactDeprecated bool
+
+	// requireRedaction fails the generation for the messages without redacted
+	// fields which are neither ignored nor redacted to nil or empty
+	requireRedaction bool
+
+	// stringer generates RedactedString() and GoString() methods, printing the
+	// redacted clones of the messages
+	stringer bool
+
+	// redactedJSON generates RedactedJSON() methods, marshalling the redacted
+	// clones of the messages with protojson, using the JSON names of the fields
+	redactedJSON bool
+
+	// redactedFields generates RedactedFields() methods, listing the fields
+	// redacted by the Redact() methods
+	redactedFields bool
+
+	// buildTag constrains the generated files to the builds with the tag, a
+	// stub file without redaction is generated for the builds without it
+	buildTag string
+
+	// sharedEmpty redacts the message fields to empty with package-level empty
+	// values shared by the fields, instead of allocating new ones
+	sharedEmpty bool
+
+	// resetEmpty resets the set message fields redacted to empty in place,
+	// keeping their pointers, only the nil ones are allocated
+	resetEmpty bool
+
+	// clearUnknown clears the unknown fields of the messages on redaction
+	clearUnknown bool
+
+	// registerRequests registers the redaction of the requests of the services
+	// for redact.RedactRequest
+	registerRequests bool
+
+	// messagesOnly generates the Redact() methods of the messages only, without
+	// the redacted server wrappers of the services
+	messagesOnly bool
+
+	// separatePackage generates the redaction in the sibling package of the
+	// messages with this name, as Redact<Message>() functions instead of
+	// methods, empty to generate it next to the messages
+	separatePackage string
+
+	// groupImports sorts the imports of the generated files in goimports-style
+	// groups, localPrefixes are the paths of the local group, by default the
+	// module of each file
+	groupImports  bool
+	localPrefixes []string
+
+	// ctxPredicate: function deciding from the context whether the responses
+	// of the redacted servers are redacted, nil to always redact
+	ctxPredicate *GoRef
+
+	// emptyFactoryCalls: calls of the empty_factory functions of the messages
+	// referenced by the processed file, by message, set before its messages are
+	// processed
+	emptyFactoryCalls map[string]string
+
+	// fileDefaults: redaction defaults set by the options of the processed
+	// file, e.g. file_default_string, overriding the plugin parameters, set
+	// before its messages are processed
+	fileDefaults map[pgs.ProtoType]string
 }
 
 // Name returns the name of this protoc-gen-star module
@@ -41,30 +187,164 @@ func (m *Module) InitContext(c pgs.BuildContext) {
 		return
 	}
 
-	// Check for custom template file parameter
+	// plugin options
+	var err error
+	m.respectValidate = m.boolParam(c.Parameters(), "respect_validate")
+	m.varPlaceholders = m.boolParam(c.Parameters(), "var_placeholders")
+	m.runtimeMarker = m.boolParam(c.Parameters(), "runtime_marker")
+	m.versionConst = m.boolParam(c.Parameters(), "version_const")
+	if m.varPlaceholders && m.runtimeMarker {
+		m.Fail("var_placeholders and runtime_marker are mutually exclusive, var_placeholders already emits RedactedString")
+		return
+	}
+	m.resetAndCopy = m.boolParam(c.Parameters(), "reset_and_copy")
+	m.warnNoopNested = m.boolParam(c.Parameters(), "warn_noop_nested")
+	m.fallible = m.boolParam(c.Parameters(), "fallible")
+	m.proof = m.boolParam(c.Parameters(), "proof")
+	m.audit = m.boolParam(c.Parameters(), "audit")
+	m.emitMetadata = m.boolParam(c.Parameters(), "emit_metadata")
+	m.reportOnly = m.boolParam(c.Parameters(), "report_only")
+	m.stringer = m.boolParam(c.Parameters(), "stringer")
+	m.redactedJSON = m.boolParam(c.Parameters(), "redacted_json")
+	m.redactedFields = m.boolParam(c.Parameters(), "redacted_fields")
+	m.warnPII = m.boolParam(c.Parameters(), "warn_pii")
+	m.strictPII = m.boolParam(c.Parameters(), "strict_pii")
+	m.requireRedaction = m.boolParam(c.Parameters(), "require_redaction")
+	m.redactDeprecated = m.boolParam(c.Parameters(), "redact_deprecated")
+	m.messagesOnly = m.boolParam(c.Parameters(), "messages_only")
+	m.clearUnknown = m.boolParam(c.Parameters(), "clear_unknown")
+	m.registerRequests = m.boolParam(c.Parameters(), "register_requests")
+	m.sharedEmpty = m.boolParam(c.Parameters(), "shared_empty")
+	m.resetEmpty = m.boolParam(c.Parameters(), "reset_empty")
+	if m.sharedEmpty && m.resetEmpty {
+		m.Fail("shared_empty and reset_empty are mutually exclusive, the shared empty values must not be reset")
+		return
+	}
+	m.groupImports = m.boolParam(c.Parameters(), "group_imports")
+	if val := c.Parameters().Str("local_prefix"); val != "" {
+		m.localPrefixes = strings.Split(val, ":")
+	}
+	m.piiKeywords = piiKeywords
+	if val := c.Parameters().Str("pii_keywords"); val != "" {
+		m.piiKeywords = strings.Split(val, ":")
+	}
+	if val := c.Parameters().Str("ctx_predicate"); val != "" {
+		m.ctxPredicate, err = parseGoRef(val)
+		if err != nil {
+			m.Failf("Invalid value for ctx_predicate parameter: %v", err)
+			return
+		}
+	}
+	if val := c.Parameters().Str("separate_package"); val != "" {
+		if err := m.validatePackageName(val); err != nil || token.IsKeyword(val) {
+			m.Failf("Invalid value for separate_package parameter: %q is not a valid package name", val)
+			return
+		}
+		if m.stringer || m.redactedJSON || m.redactedFields {
+			m.Fail("The separate_package parameter cannot be combined with stringer, redacted_json or redacted_fields, " +
+				"these generate methods of the messages")
+			return
+		}
+		m.separatePackage = val
+	}
+	m.buildTag = c.Parameters().Str("build_tag")
+	if err := m.validateBuildTag(m.buildTag); err != nil {
+		m.Failf("Invalid value for build_tag parameter: %v", err)
+		return
+	}
+	m.maxFieldLen, err = c.Parameters().Int("max_field_len")
+	if err != nil || m.maxFieldLen < 0 {
+		m.Failf("Invalid value for max_field_len parameter: must be a non-negative integer")
+		return
+	}
+	m.maxDepth, err = c.Parameters().Int("max_depth")
+	if err != nil || m.maxDepth < 0 {
+		m.Failf("Invalid value for max_depth parameter: must be a non-negative integer")
+		return
+	}
+	m.workers, err = c.Parameters().Int("workers")
+	if err != nil || m.workers < 0 {
+		m.Failf("Invalid value for workers parameter: must be a non-negative integer")
+		return
+	}
+	m.paramDefaults = defaultRegistry()
+	m.defaults = m.paramDefaults
+	for param, typ := range defaultParams {
+		val, ok := c.Parameters()[param]
+		if !ok {
+			continue
+		}
+		lit, err := parseDefault(typ, val)
+		if err != nil {
+			m.Failf("Invalid value for %s parameter: %v", param, err)
+			return
+		}
+		m.paramDefaults[typ] = lit
+	}
+	m.defaultExprs = map[pgs.ProtoType]*GoExpr{}
+	for param, typ := range defaultExprParams {
+		val, ok := c.Parameters()[param]
+		if !ok {
+			continue
+		}
+		if _, ok := c.Parameters()[strings.TrimSuffix(param, "_expr")]; ok {
+			m.Failf("The %s and %s parameters are mutually exclusive", strings.TrimSuffix(param, "_expr"), param)
+			return
+		}
+		expr, err := parseGoExpr(val)
+		if err != nil {
+			m.Failf("Invalid value for %s parameter: %v", param, err)
+			return
+		}
+		m.defaultExprs[typ] = expr
+	}
+
+	// Check for custom template file parameters, template_file fails on an
+	// invalid template while template falls back to the embedded one
 	templateFile := c.Parameters().Str("template_file")
+	fallbackFile := c.Parameters().Str("template")
+	if templateFile != "" && fallbackFile != "" {
+		m.Fail("The template and template_file parameters are mutually exclusive")
+		return
+	}
 
-	tpl := template.New("redact").Funcs(map[string]interface{}{
-		"package": m.ctx.PackageName,
-		"name":    m.ctx.Name,
-	})
+	newTemplate := func() *template.Template {
+		return template.New("redact").Funcs(map[string]interface{}{
+			"package": m.ctx.PackageName,
+			"name":    m.ctx.Name,
+		})
+	}
 
 	var parsedTpl *template.Template
-	var err error
 
-	if templateFile != "" {
+	switch {
+	case templateFile != "":
 		// Load template from external file
 		m.Debug("Loading template from file: " + templateFile)
-		parsedTpl, err = m.loadTemplateFromFile(tpl, templateFile)
+		parsedTpl, err = m.loadTemplateFromFile(newTemplate(), templateFile)
 		if err != nil {
 			m.Failf("Failed to load template from file %s: %v", templateFile, err)
 			return
 		}
 		m.Debug("Successfully loaded external template")
-	} else {
+	case fallbackFile != "":
+		// Load the template from the external file, if it parses and matches
+		// the ProtoFileData fields
+		m.Debug("Loading template from file: " + fallbackFile)
+		parsedTpl, err = m.loadTemplateFromFile(newTemplate(), fallbackFile)
+		if err == nil {
+			err = checkTemplate(parsedTpl)
+		}
+		if err != nil {
+			m.Logf("Warning: using the embedded template, failed to load template from file %s: %v", fallbackFile, err)
+			parsedTpl = nil
+		}
+	}
+
+	if parsedTpl == nil {
 		// Use embedded template
 		m.Debug("Using embedded template")
-		parsedTpl, err = tpl.Parse(redactTpl)
+		parsedTpl, err = newTemplate().Parse(redactTpl)
 		if err != nil {
 			m.Failf("Failed to parse embedded template: %v", err)
 			return
@@ -78,6 +358,10 @@ func (m *Module) InitContext(c pgs.BuildContext) {
 // Execute satisfies the pgs.Module interface & generates the redactor file
 // for the targeted files
 func (m *Module) Execute(targets map[string]pgs.File, _ map[string]pgs.Package) []pgs.Artifact {
+	if m.varPlaceholders || m.runtimeMarker || m.versionConst {
+		m.placeholderFiles = m.packageOwners(targets)
+	}
+
 	// process all the target files
 	for _, file := range targets {
 		m.Process(file)
@@ -85,6 +369,15 @@ func (m *Module) Execute(targets map[string]pgs.File, _ map[string]pgs.Package)
 	return m.Artifacts()
 }
 
+// boolParam reads a boolean plugin parameter, failing on an invalid value
+func (m *Module) boolParam(params pgs.Parameters, name string) bool {
+	val, err := params.Bool(name)
+	if err != nil {
+		m.Failf("Invalid value for %s parameter: %v", name, err)
+	}
+	return val
+}
+
 // loadTemplateFromFile loads a template from an external file
 func (m *Module) loadTemplateFromFile(tpl *template.Template, templatePath string) (*template.Template, error) {
 	// Validate the file path
@@ -158,16 +451,45 @@ func (m *Module) loadTemplateFromFile(tpl *template.Template, templatePath strin
 	return parsedTpl, nil
 }
 
+// checkTemplate executes the template against an empty ProtoFileData, catching
+// the references to unknown fields before any file is generated
+func checkTemplate(tpl *template.Template) error {
+	if err := tpl.Execute(io.Discard, &ProtoFileData{}); err != nil {
+		return ErrorContext{
+			Location: "template: " + tpl.Name(),
+			Reason:   "template does not match the ProtoFileData fields: " + err.Error(),
+		}
+	}
+	return nil
+}
+
 const redactTpl = `{{ $data := . }}
 // Code generated by protoc-gen-redact. DO NOT EDIT.
+// versions:
+// 	protoc-gen-redact {{ $data.Version }}
 // source: {{ $data.Source }}
-
+{{- if $data.AnnotationsHash }}
+// annotations: sha256:{{ $data.AnnotationsHash }}
+{{- end }}
+{{ if $data.BuildTag }}
+//go:build {{ if $data.NoRedact }}!{{ end }}{{ $data.BuildTag }}
+{{ end }}
 package {{ $data.Package }}
 
 import (
+	{{- if $data.ImportGroups }}
+	{{- range $i, $group := $data.ImportGroups }}
+	{{- if $i }}
+{{ end }}
+	{{- range $imp := $group }}
+	{{ $imp.Alias }} "{{ $imp.Path }}"
+	{{- end }}
+	{{- end }}
+	{{- else }}
 	{{- range $alias, $path := $data.Imports }}
 	{{ $alias }} "{{ $path }}"
 	{{- end }}
+	{{- end }}
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -177,17 +499,107 @@ var (
 	{{- end }}
 )
 
+{{ if $data.ImportedRedactors }}
+// Imported messages called for redaction must have a generated Redact() method,
+// otherwise their values would be kept
+var (
+	{{- range $name := $data.ImportedRedactors }}
+	_ = (*{{ $name }}).Redact
+	{{- end }}
+)
+{{ end }}
+
+{{ if $data.VersionConst }}
+// RedactGenVersion is the version of protoc-gen-redact which generated the
+// files of the package, to detect the files generated by other versions
+const RedactGenVersion = "{{ $data.Version }}"
+{{ end }}
+
+{{ if $data.EmptyFactories }}
+// Functions returning the empty values of the messages redacted to empty, these
+// must return new values, as the redacted fields and responses are not copied
+var (
+	{{- range $f := $data.EmptyFactories }}
+	_ func() *{{ $f.Message }} = {{ $f.Func }}
+	{{- end }}
+)
+{{ end }}
+
+{{ if $data.DefaultExprs }}
+// Expressions of the redaction defaults, type-checked against the redacted types
+// without being evaluated
+var (
+	{{- range $e := $data.DefaultExprs }}
+	_ = func() {{ $e.GoType }} { return {{ $e.Value }} }
+	{{- end }}
+)
+{{ end }}
+
+{{ if $data.Placeholders }}
+// Redaction placeholders used as default redaction values, these can be
+// reassigned (e.g. in tests) to change the redacted values without regenerating
+var (
+	{{- range $p := $data.Placeholders }}
+	{{ $p.Name }} {{ $p.GoType }} = {{ $p.Value }}
+	{{- end }}
+)
+{{ end }}
+
+{{ if and $data.Regexps (not $data.NoRedact) }}
+// Compiled patterns of the regex_replace rules, the matches are replaced in the
+// redacted string fields
+var (
+	{{- range $p := $data.Regexps }}
+	{{ $p.Name }} {{ $p.GoType }} = {{ $p.Value }}
+	{{- end }}
+)
+{{ end }}
+
+{{ if and $data.EmptyValues (not $data.NoRedact) }}
+// Shared empty values of the messages redacted to empty, these must not be
+// mutated as all the redacted fields reference them
+var (
+	{{- range $p := $data.EmptyValues }}
+	{{ $p.Name }} {{ $p.GoType }} = {{ $p.Value }}
+	{{- end }}
+)
+{{ end }}
+
+{{ if $data.CtxPredicate }}
+// {{ $data.CtxPredicate }} decides whether the responses of the redacted servers are redacted
+var _ func(context.Context) bool = {{ $data.CtxPredicate }}
+{{ end }}
+
+{{ if not $data.MessagesOnly }}
 {{ range $srv := $data.Services }}
 	{{- if $srv.Skip }}
 		// Redacted server wrapper for {{ $srv.Name }} is skipped
-		var RegisterRedacted{{ $srv.Name }} = Register{{ $srv.Name }}
+		var RegisterRedacted{{ $srv.Name }} = {{ $data.MessagePackage }}Register{{ $srv.Name }}
+
+		// Redacted client for {{ $srv.Name }} is skipped
+		var NewRedacted{{ $srv.ClientName }} = {{ $data.MessagePackage }}New{{ $srv.ClientName }}
+	{{- else if $data.NoRedact }}
+		// RegisterRedacted{{ $srv.Name }} registers the {{ $srv.Name }} in GRPC, the redaction is disabled without the {{ $data.BuildTag }} build tag
+		func RegisterRedacted{{ $srv.Name }}(s grpc.ServiceRegistrar, srv {{ $data.MessagePackage }}{{ $srv.Name }}, _ redact.Bypass) {
+			{{ $data.MessagePackage }}Register{{ $srv.Name }}(s, srv)
+		}
+
+		// Redacted{{ $srv.Name }} returns the srv as is, the redaction is disabled without the {{ $data.BuildTag }} build tag
+		func Redacted{{ $srv.Name }}(srv {{ $data.MessagePackage }}{{ $srv.Name }}, _ redact.Bypass) {{ $data.MessagePackage }}{{ $srv.Name }} {
+			return srv
+		}
+
+		// NewRedacted{{ $srv.ClientName }} returns the {{ $srv.ClientName }} as is, the redaction is disabled without the {{ $data.BuildTag }} build tag
+		func NewRedacted{{ $srv.ClientName }}(cc grpc.ClientConnInterface) {{ $data.MessagePackage }}{{ $srv.ClientName }} {
+			return {{ $data.MessagePackage }}New{{ $srv.ClientName }}(cc)
+		}
 	{{- else }}
 		// RegisterRedacted{{ $srv.Name }} wraps the {{ $srv.Name }} with the redacted server and registers the service in GRPC
-		func RegisterRedacted{{ $srv.Name }}(s grpc.ServiceRegistrar, srv {{ $srv.Name }}, bypass redact.Bypass) {
-			Register{{ $srv.Name }}(s, Redacted{{ $srv.Name }}(srv, bypass))
+		func RegisterRedacted{{ $srv.Name }}(s grpc.ServiceRegistrar, srv {{ $data.MessagePackage }}{{ $srv.Name }}, bypass redact.Bypass) {
+			{{ $data.MessagePackage }}Register{{ $srv.Name }}(s, Redacted{{ $srv.Name }}(srv, bypass))
 		}
 
-		func Redacted{{ $srv.Name }}(srv {{ $srv.Name }}, bypass redact.Bypass) {{ $srv.Name }} {
+		func Redacted{{ $srv.Name }}(srv {{ $data.MessagePackage }}{{ $srv.Name }}, bypass redact.Bypass) {{ $data.MessagePackage }}{{ $srv.Name }} {
 			if bypass == nil {
 				bypass = redact.Falsy
 			}
@@ -195,11 +607,33 @@ var (
 		}
 
 		type redacted{{ $srv.Name }} struct {
-			Unsafe{{ $srv.Name }}
-			srv    {{ $srv.Name }}
+			{{ $data.MessagePackage }}Unsafe{{ $srv.Name }}
+			srv    {{ $data.MessagePackage }}{{ $srv.Name }}
 			bypass redact.Bypass
 		}
 
+		// NewRedacted{{ $srv.ClientName }} returns a {{ $srv.ClientName }} redacting the replies of the unary
+		// methods, but the skipped ones, with redactgrpc.UnaryClientInterceptor. The replies are redacted in place,
+		// the callers needing the original values must clone them.
+		func NewRedacted{{ $srv.ClientName }}(cc grpc.ClientConnInterface) {{ $data.MessagePackage }}{{ $srv.ClientName }} {
+			return {{ $data.MessagePackage }}New{{ $srv.ClientName }}(redactgrpc.RedactedClientConn{{ if $srv.Replies }}Func{{ end }}(cc
+				{{- if $srv.Replies }}, redact{{ $srv.ClientName }}Reply{{ end }}
+				{{- range $meth := $srv.Methods }}{{ if $meth.Skip }}, "{{ $meth.FullMethod }}"{{ end }}{{ end }}))
+		}
+		{{- if $srv.Replies }}
+
+		// redact{{ $srv.ClientName }}Reply redacts the replies of the unary methods of {{ $srv.ClientName }}
+		func redact{{ $srv.ClientName }}Reply(reply interface{}) error {
+			switch x := reply.(type) {
+			{{- range $reply := $srv.Replies }}
+			case *{{ $reply.Type }}:
+				{{ if $data.Fallible }}return {{ end }}{{ $reply.Redactor }}(x)
+			{{- end }}
+			}
+			return nil
+		}
+		{{- end }}
+
 		{{ range $meth := $srv.Methods }}
 			// {{ $meth.Name }} is the redacted wrapper for the actual {{ $srv.Name }}.{{ $meth.Name }} method
 			{{- if and $meth.ClientStreaming $meth.ServerStreaming }}
@@ -233,6 +667,16 @@ var (
 						// Redaction skipped
 						return s.srv.{{ $meth.Name }}(in, stream)
 					{{- else }}
+						{{- if $meth.RedactInput }}
+							// Redact the request before forwarding it to the handler
+							{{- if $data.Fallible }}
+								if err := {{ $meth.InputRedactor }}(in); err != nil {
+									return status.Errorf(codes.Internal, "redaction failed: %v", err)
+								}
+							{{- else }}
+								{{ $meth.InputRedactor }}(in)
+							{{- end }}
+						{{- end }}
 						// Note: Redaction for server streaming is not fully implemented
 						// Streaming methods pass through without redaction
 						return s.srv.{{ $meth.Name }}(in, stream)
@@ -246,15 +690,38 @@ var (
 						return s.srv.{{ $meth.Name }}(ctx, in)
 					{{- else if $meth.Internal }}
 						if s.bypass.CheckInternal(ctx) {
+							{{- if $meth.RedactInput }}
+								// Redact the request before forwarding it to the handler
+								{{- if $data.Fallible }}
+									if err := {{ $meth.InputRedactor }}(in); err != nil {
+										return nil, status.Errorf(codes.Internal, "redaction failed: %v", err)
+									}
+								{{- else }}
+									{{ $meth.InputRedactor }}(in)
+								{{- end }}
+							{{- end }}
 							return s.srv.{{ $meth.Name }}(ctx, in)
 						}
 						return nil, status.Error(codes.{{ $meth.StatusCode }}, {{ $meth.ErrMessage }})
 					{{- else }}
+						{{- if $meth.RedactInput }}
+							// Redact the request before forwarding it to the handler
+							{{- if $data.Fallible }}
+								if err := {{ $meth.InputRedactor }}(in); err != nil {
+									return nil, status.Errorf(codes.Internal, "redaction failed: %v", err)
+								}
+							{{- else }}
+								{{ $meth.InputRedactor }}(in)
+							{{- end }}
+						{{- end }}
 						res, err := s.srv.{{ $meth.Name }}(ctx, in)
-						if !s.bypass.CheckInternal(ctx) {
+						if !s.bypass.CheckInternal(ctx){{ if $data.CtxPredicate }} && {{ $data.CtxPredicate }}(ctx){{ end }} {
 							{{- if $meth.Output.ToNil }}
 								// Response message is set to nil, ignoring all field level rules
 								res = nil
+							{{- else if and $meth.Output.ToEmpty $meth.Output.EmptyFactory }}
+								// Response message is set to empty by its factory, ignoring all field level rules
+								res = {{ $meth.Output.EmptyFactory }}
 							{{- else if $meth.Output.ToEmpty }}
 								// Response message is set to empty, ignoring all field level rules
 								res = &{{ $meth.Output.WithAlias }}{}
@@ -262,7 +729,13 @@ var (
 								// Response message is set to be ignored from any redaction
 							{{- else }}
 								// Apply redaction to the response
-								redact.Apply(res)
+								{{- if $data.Fallible }}
+									if err := {{ $meth.OutputRedactor }}(res); err != nil {
+										return nil, status.Errorf(codes.Internal, "redaction failed: %v", err)
+									}
+								{{- else }}
+									{{ $meth.OutputRedactor }}(res)
+								{{- end }}
 							{{- end }}
 						}
 						return res, err
@@ -273,42 +746,295 @@ var (
 	{{ end }}
 {{ end }}
 
+{{ if and $data.Requests (not $data.NoRedact) }}
+// init registers the redaction of the requests of the services, used by redact.RedactRequest
+func init() {
+	{{- range $req := $data.Requests }}
+	redact.RegisterRequest((*{{ $req.Type }})(nil), func(ctx context.Context, req interface{}) error {
+		{{- if $data.CtxPredicate }}
+		if !{{ $data.CtxPredicate }}(ctx) {
+			return nil
+		}
+		{{- end }}
+		{{- if $data.Fallible }}
+		return {{ $req.Redactor }}(req.(*{{ $req.Type }}))
+		{{- else }}
+		{{ $req.Redactor }}(req.(*{{ $req.Type }}))
+		return nil
+		{{- end }}
+	})
+	{{- end }}
+}
+{{ end }}
+{{ end }}
+
+{{ $depth := and $data.DepthLimited (not $data.NoRedact) }}
 {{ range $msg := $data.Messages }}
+	{{- if and (not $msg.Ignore) (not $data.MessagePackage) }}
+		{{- if $data.Fallible }}
+			// {{ $msg.Name }} must implement redact.FallibleRedactor, to be redacted by redact.Apply
+			var _ redact.FallibleRedactor = (*{{ $msg.Name }})(nil)
+		{{- else }}
+			// {{ $msg.Name }} must implement redact.Redactor, to be redacted by redact.Apply
+			var _ redact.Redactor = (*{{ $msg.Name }})(nil)
+		{{- end }}
+	{{ end }}
+	{{- if $data.MessagePackage }}
+	// Redact{{ $msg.Name }} function implementation for {{ $msg.Name }}
+	{{- else }}
 	// Redact method implementation for {{ $msg.Name }}
-	func (x *{{ $msg.Name }}) Redact() string {
+	{{- end }}
+	{{- if not $data.NoRedact }}
+		{{- range $line := $msg.RedactSummary }}
+	// {{ $line }}
+		{{- end }}
+	{{- end }}
+	{{- if $data.MessagePackage }}
+	func Redact{{ $msg.Name }}(x *{{ $msg.WithAlias }}) {{ if $data.Fallible }}error{{ else }}string{{ end }} {
+	{{- else }}
+	func (x *{{ $msg.Name }}) Redact() {{ if $data.Fallible }}error{{ else }}string{{ end }} {
+	{{- end }}
+	{{- if $depth }}
+		{{- if $data.Fallible }}
+			return x.redactDepth(0)
+		{{- else }}
+			x.redactDepth(0)
+			return x.String()
+		{{- end }}
+	}
+
+	// redactDepth redacts {{ $msg.Name }} and its nested messages down to depth
+	// levels, 0 for unlimited
+	func (x *{{ $msg.Name }}) redactDepth(depth int) {{ if $data.Fallible }}error {{ end }}{
+	{{- end }}
 		{{- if $msg.Ignore }}
 			// Ignoring message
+		{{- else if $data.NoRedact }}
+			// Redaction is disabled without the {{ $data.BuildTag }} build tag
 		{{- else if $msg.ToEmpty }}
 			// Message will be set to empty, ignoring all field level rules
 		{{- else if $msg.ToNil }}
 			// Message will be set to nil, ignoring all field level rules
 		{{- else }}
-			if x == nil { return "" }
+			if x == nil { return {{ if $data.Fallible }}nil{{ else if not $depth }}""{{ end }} }
+			{{- if $msg.GatedBy }}
+				// Redaction is gated by {{ $msg.GatedBy }}, the message is kept unless it is true
+				if !x.Get{{ $msg.GatedBy }}() { return {{ if $data.Fallible }}nil{{ else if not $depth }}x.String(){{ end }} }
+			{{- end }}
+			{{- if $data.ClearUnknown }}
+				// Clear the unknown fields, these could carry data through the redaction
+				x.ProtoReflect().SetUnknown(nil)
+			{{- end }}
+			{{- if $msg.ResetAndCopy }}
+				// Reset the message, only the allowed and redacted fields are copied back
+				{{- range $field := $msg.Fields }}
+					{{- if and $field.Keep (not $field.InOneOf) }}
+						keep{{ $field.Name }} := x.{{ $field.Name }}
+					{{- end }}
+				{{- end }}
+				{{- range $oneof := $msg.KeptOneofs }}
+					keep{{ $oneof.Name }} := x.{{ $oneof.Name }}
+				{{- end }}
+				x.Reset()
+				{{- range $field := $msg.Fields }}
+					{{- if and $field.Keep (not $field.InOneOf) }}
+						x.{{ $field.Name }} = keep{{ $field.Name }}
+					{{- end }}
+				{{- end }}
+				{{- range $oneof := $msg.KeptOneofs }}
+					switch keep{{ $oneof.Name }}.(type) {
+					case {{ range $i, $wrapper := $oneof.Wrappers }}{{ if $i }}, {{ end }}*{{ $data.MessagePackage }}{{ $wrapper }}{{ end }}:
+						x.{{ $oneof.Name }} = keep{{ $oneof.Name }}
+					}
+				{{- end }}
+			{{- end }}
 			{{- range $field := $msg.Fields }}
 				{{ if $field.Redact }}
 					// Redacting field: {{ $field.Name }}
+					{{- if $field.KeyRedact }}
+						{{- if $field.KeyPANMask }}
+							x.{{ $field.Name }} = redact.RedactMapKeys(x.{{ $field.Name }}, redact.MaskPAN)
+						{{- else }}
+							x.{{ $field.Name }} = redact.RedactMapKeys(x.{{ $field.Name }}, func(string) string { return {{ $field.KeyRedactionValue }} })
+						{{- end }}
+					{{- end }}
 					{{- if $field.Iterate }}
 						{{- if $field.NestedEmbedCall }}
 							for k := range x.{{ $field.Name }} {
-								redact.Apply(x.{{$field.Name}}[k])
+								if {{ if $depth }}depth != 1 && {{ end }}x.{{$field.Name}}[k] != nil {
+									{{- if and $depth $field.LocalRedactor $data.Fallible }}
+										if err := x.{{$field.Name}}[k].redactDepth(redact.NestedDepth(depth, {{ $field.Depth }})); err != nil {
+											return err
+										}
+									{{- else if and $depth $field.LocalRedactor }}
+										x.{{$field.Name}}[k].redactDepth(redact.NestedDepth(depth, {{ $field.Depth }}))
+									{{- else if $data.Fallible }}
+										if err := {{ $field.Redactor }}(x.{{$field.Name}}[k]); err != nil {
+											return err
+										}
+									{{- else }}
+										{{ $field.Redactor }}(x.{{$field.Name}}[k])
+									{{- end }}
+								}
 							}
 						{{- else if $field.EmbedSkip }}
 							// {{$field.Name}} redaction is skipped
+						{{- else if $field.PANMask }}
+							for k := range x.{{ $field.Name }} {
+								x.{{ $field.Name }}[k] = redact.MaskPAN(x.{{ $field.Name }}[k])
+							}
+						{{- else if $field.RegexVar }}
+							for k := range x.{{ $field.Name }} {
+								x.{{ $field.Name }}[k] = {{ $field.RegexVar }}.ReplaceAllString(x.{{ $field.Name }}[k], {{ $field.RegexReplacement }})
+							}
+						{{- else if $field.BytesZeroFill }}
+							for k := range x.{{ $field.Name }} {
+								if x.{{ $field.Name }}[k] != nil {
+									x.{{ $field.Name }}[k] = make([]byte, len(x.{{ $field.Name }}[k]))
+								}
+							}
+						{{- else if $field.Magnitude }}
+							for k := range x.{{ $field.Name }} {
+								x.{{ $field.Name }}[k] = redact.Magnitude(x.{{ $field.Name }}[k])
+							}
+						{{- else if $field.RoundFloat }}
+							for k := range x.{{ $field.Name }} {
+								x.{{ $field.Name }}[k] = redact.RoundTo(x.{{ $field.Name }}[k], {{ $field.RoundTo }})
+							}
+						{{- else if $field.RoundTo }}
+							for k := range x.{{ $field.Name }} {
+								x.{{ $field.Name }}[k] = (x.{{ $field.Name }}[k] / {{ $field.RoundTo }}) * {{ $field.RoundTo }}
+							}
                         {{- else }}
 							for k := range x.{{ $field.Name }} {
 								x.{{ $field.Name }}[k] = {{ $field.RedactionValue }}
 							}
 						{{- end }}
+					{{- else if $field.Collapse }}
+						if len(x.{{ $field.Name }}) > 0 {
+							x.{{ $field.Name }} = {{ $field.RedactionValue }}
+						}
+					{{- else if $field.KeyRedact }}
+					{{- else if and $field.InOneOf $field.EmbedSkip }}
+						// {{$field.Name}} redaction is skipped
+					{{- else if $field.InOneOf }}
+						if v, ok := x.{{ $field.OneOf }}.(*{{ $data.MessagePackage }}{{ $field.OneOfWrapper }}); ok {
+							{{- if $field.NestedEmbedCall }}
+								if {{ if $depth }}depth != 1 && {{ end }}v.{{ $field.Name }} != nil {
+									{{- if and $depth $field.LocalRedactor $data.Fallible }}
+										if err := v.{{ $field.Name }}.redactDepth(redact.NestedDepth(depth, {{ $field.Depth }})); err != nil {
+											return err
+										}
+									{{- else if and $depth $field.LocalRedactor }}
+										v.{{ $field.Name }}.redactDepth(redact.NestedDepth(depth, {{ $field.Depth }}))
+									{{- else if $data.Fallible }}
+										if err := {{ $field.Redactor }}(v.{{ $field.Name }}); err != nil {
+											return err
+										}
+									{{- else }}
+										{{ $field.Redactor }}(v.{{ $field.Name }})
+									{{- end }}
+								}
+							{{- else if $field.ResetEmpty }}
+								if v.{{ $field.Name }} != nil {
+									v.{{ $field.Name }}.Reset()
+								} else {
+									v.{{ $field.Name }} = {{ $field.RedactionValue }}
+								}
+							{{- else if $field.PANMask }}
+								v.{{ $field.Name }} = redact.MaskPAN(v.{{ $field.Name }})
+							{{- else if $field.RegexVar }}
+								v.{{ $field.Name }} = {{ $field.RegexVar }}.ReplaceAllString(v.{{ $field.Name }}, {{ $field.RegexReplacement }})
+							{{- else if $field.BytesZeroFill }}
+								if v.{{ $field.Name }} != nil {
+									v.{{ $field.Name }} = make([]byte, len(v.{{ $field.Name }}))
+								}
+							{{- else if $field.Magnitude }}
+								v.{{ $field.Name }} = redact.Magnitude(v.{{ $field.Name }})
+							{{- else if $field.RoundFloat }}
+								v.{{ $field.Name }} = redact.RoundTo(v.{{ $field.Name }}, {{ $field.RoundTo }})
+							{{- else if $field.RoundTo }}
+								v.{{ $field.Name }} = (v.{{ $field.Name }} / {{ $field.RoundTo }}) * {{ $field.RoundTo }}
+							{{- else }}
+								v.{{ $field.Name }} = {{ $field.RedactionValue }}
+							{{- end }}
+							{{- if $field.AuditName }}
+								redact.Audit("{{ $msg.AuditName }}", "{{ $field.AuditName }}")
+							{{- end }}
+						}
                     {{- else if $field.IsMessage }}
 						{{- if $field.NestedEmbedCall }}
-							redact.Apply(x.{{$field.Name}})
+							if {{ if $depth }}depth != 1 && {{ end }}x.{{$field.Name}} != nil {
+								{{- if and $depth $field.LocalRedactor $data.Fallible }}
+									if err := x.{{$field.Name}}.redactDepth(redact.NestedDepth(depth, {{ $field.Depth }})); err != nil {
+										return err
+									}
+								{{- else if and $depth $field.LocalRedactor }}
+									x.{{$field.Name}}.redactDepth(redact.NestedDepth(depth, {{ $field.Depth }}))
+								{{- else if $data.Fallible }}
+									if err := {{ $field.Redactor }}(x.{{$field.Name}}); err != nil {
+										return err
+									}
+								{{- else }}
+									{{ $field.Redactor }}(x.{{$field.Name}})
+								{{- end }}
+							}
 						{{- else if $field.EmbedSkip }}
 							// {{$field.Name}} redaction is skipped
+						{{- else if $field.ResetEmpty }}
+							if x.{{ $field.Name }} != nil {
+								x.{{ $field.Name }}.Reset()
+							} else {
+								x.{{ $field.Name }} = {{ $field.RedactionValue }}
+							}
                         {{- else }}
 							x.{{ $field.Name }} = {{ $field.RedactionValue }}
 						{{- end }}
                     {{- else }}
-						{{- if $field.IsOptional }}
+						{{- if and $field.CopyFrom $field.IsOptional }}
+							if x.{{ $field.CopyFrom }} != nil {
+								{{ $field.Name }}Tmp := *x.{{ $field.CopyFrom }}
+								x.{{ $field.Name }} = &{{ $field.Name }}Tmp
+							} else {
+								x.{{ $field.Name }} = nil
+							}
+						{{- else if $field.CopyFrom }}
+							x.{{ $field.Name }} = x.{{ $field.CopyFrom }}
+						{{- else if and $field.PANMask $field.IsOptional }}
+							if x.{{ $field.Name }} != nil {
+								*x.{{ $field.Name }} = redact.MaskPAN(*x.{{ $field.Name }})
+							}
+						{{- else if $field.PANMask }}
+							x.{{ $field.Name }} = redact.MaskPAN(x.{{ $field.Name }})
+						{{- else if and $field.RegexVar $field.IsOptional }}
+							if x.{{ $field.Name }} != nil {
+								*x.{{ $field.Name }} = {{ $field.RegexVar }}.ReplaceAllString(*x.{{ $field.Name }}, {{ $field.RegexReplacement }})
+							}
+						{{- else if $field.RegexVar }}
+							x.{{ $field.Name }} = {{ $field.RegexVar }}.ReplaceAllString(x.{{ $field.Name }}, {{ $field.RegexReplacement }})
+						{{- else if $field.BytesZeroFill }}
+							if x.{{ $field.Name }} != nil {
+								x.{{ $field.Name }} = make([]byte, len(x.{{ $field.Name }}))
+							}
+						{{- else if and $field.Magnitude $field.IsOptional }}
+							if x.{{ $field.Name }} != nil {
+								*x.{{ $field.Name }} = redact.Magnitude(*x.{{ $field.Name }})
+							}
+						{{- else if $field.Magnitude }}
+							x.{{ $field.Name }} = redact.Magnitude(x.{{ $field.Name }})
+						{{- else if and $field.RoundFloat $field.IsOptional }}
+							if x.{{ $field.Name }} != nil {
+								*x.{{ $field.Name }} = redact.RoundTo(*x.{{ $field.Name }}, {{ $field.RoundTo }})
+							}
+						{{- else if and $field.RoundTo $field.IsOptional }}
+							if x.{{ $field.Name }} != nil {
+								*x.{{ $field.Name }} = (*x.{{ $field.Name }} / {{ $field.RoundTo }}) * {{ $field.RoundTo }}
+							}
+						{{- else if $field.RoundFloat }}
+							x.{{ $field.Name }} = redact.RoundTo(x.{{ $field.Name }}, {{ $field.RoundTo }})
+						{{- else if $field.RoundTo }}
+							x.{{ $field.Name }} = (x.{{ $field.Name }} / {{ $field.RoundTo }}) * {{ $field.RoundTo }}
+						{{- else if $field.IsOptional }}
 							{{- if eq $field.FieldGoType "string" }}
 								{{ $field.Name }}Tmp := {{ $field.RedactionValue }}
 								x.{{ $field.Name }} = &{{ $field.Name }}Tmp
@@ -316,16 +1042,138 @@ var (
 								{{ $field.Name }}Tmp := {{ $field.FieldGoType }}({{ $field.RedactionValue }})
 								x.{{ $field.Name }} = &{{ $field.Name }}Tmp
 							{{- end }}
+						{{- else if $field.IsOptionalBytes }}
+							if x.{{ $field.Name }} != nil {
+								x.{{ $field.Name }} = {{ $field.RedactionValue }}
+							}
 						{{- else }}
 							x.{{ $field.Name }} = {{ $field.RedactionValue }}
 						{{- end }}
 					{{- end }}
+					{{- if and $field.AuditName (not $field.InOneOf) }}
+						redact.Audit("{{ $msg.AuditName }}", "{{ $field.AuditName }}")
+					{{- end }}
+				{{- else if and $msg.ResetAndCopy (not $field.Keep) }}
+					// Dropped field: {{ $field.Name }}
 				{{- else }}
 					// Safe field: {{ $field.Name }}
 				{{- end }}
 			{{- end }}
+			{{- if $msg.MaxFieldLen }}
+				// Cap the length of the fields
+				{{- range $field := $msg.Fields }}
+					{{- if $field.InOneOf }}
+					{{- else if $field.IsMap }}
+						redact.TruncateMap(x.{{ $field.Name }}, {{ $msg.MaxFieldLen }})
+					{{- else if $field.IsRepeated }}
+						x.{{ $field.Name }} = redact.TruncateSlice(x.{{ $field.Name }}, {{ $msg.MaxFieldLen }})
+					{{- else if and $field.IsOptional (eq $field.FieldGoType "string") }}
+						if x.{{ $field.Name }} != nil {
+							*x.{{ $field.Name }} = redact.TruncateString(*x.{{ $field.Name }}, {{ $msg.MaxFieldLen }})
+						}
+					{{- else if eq $field.FieldGoType "string" }}
+						x.{{ $field.Name }} = redact.TruncateString(x.{{ $field.Name }}, {{ $msg.MaxFieldLen }})
+					{{- else if eq $field.FieldGoType "[]byte" }}
+						x.{{ $field.Name }} = redact.TruncateBytes(x.{{ $field.Name }}, {{ $msg.MaxFieldLen }})
+					{{- end }}
+				{{- end }}
+			{{- end }}
+			{{- if $msg.CustomRedactor }}
+				// Route the fields through the registered redact.FieldRedactor
+				redactor := redact.GetRedactor()
+				{{- range $field := $msg.CustomFields }}
+					x.{{ $field.GoName }} = redact.RedactFieldAs(redactor, "{{ $field.Name }}", x.{{ $field.GoName }})
+				{{- end }}
+			{{- end }}
+			{{- if $msg.ProofPaths }}
+				// Record the proof of redaction
+				redact.RecordProof("{{ $msg.ProofName }}", []string{
+					{{- range $path := $msg.ProofPaths }}"{{ $path }}", {{ end -}}
+				}, time.Now())
+			{{- end }}
+		{{- end }}
+	{{- if $data.Fallible }}
+		return nil
+	{{- else if not $depth }}
+		return x.String()
+	{{- end }}
+	}
+	{{- if $data.Stringer }}
+
+	// RedactedString returns the JSON representation of the redacted clone of
+	// {{ $msg.Name }}, the message itself is not modified
+	func (x *{{ $msg.Name }}) RedactedString() string {
+		if x == nil {
+			return "<nil>"
+		}
+		{{- if $msg.ToNil }}
+			// Message is redacted to nil
+			return "<nil>"
+		{{- else if $msg.ToEmpty }}
+			// Message is redacted to empty
+			return protojson.Format(&{{ $msg.Name }}{})
+		{{- else }}
+			{{- if $data.Fallible }}
+				clone := proto.Clone(x).(*{{ $msg.Name }})
+				if err := clone.Redact(); err != nil {
+					return "<redaction failed>"
+				}
+				return protojson.Format(clone)
+			{{- else }}
+				return protojson.Format(redact.ApplyCopy(x))
+			{{- end }}
 		{{- end }}
-    return x.String()
 	}
+
+	// GoString returns the RedactedString of {{ $msg.Name }}, for the %#v verb
+	func (x *{{ $msg.Name }}) GoString() string {
+		return x.RedactedString()
+	}
+	{{- end }}
+	{{- if $data.RedactedJSON }}
+
+	// RedactedJSON returns the protojson encoding of the redacted clone of
+	// {{ $msg.Name }}, with the JSON names of the fields, the message itself is
+	// not modified
+	func (x *{{ $msg.Name }}) RedactedJSON() ([]byte, error) {
+		if x == nil {
+			return []byte("null"), nil
+		}
+		{{- if $msg.ToNil }}
+			// Message is redacted to nil
+			return []byte("null"), nil
+		{{- else if $msg.ToEmpty }}
+			// Message is redacted to empty
+			return protojson.Marshal(&{{ $msg.Name }}{})
+		{{- else }}
+			{{- if $data.Fallible }}
+				clone := proto.Clone(x).(*{{ $msg.Name }})
+				if err := clone.Redact(); err != nil {
+					return nil, err
+				}
+				return protojson.Marshal(clone)
+			{{- else }}
+				return protojson.Marshal(redact.ApplyCopy(x))
+			{{- end }}
+		{{- end }}
+	}
+	{{- end }}
+	{{- if $data.RedactedFieldsMethod }}
+
+	// redactedFields{{ $msg.Name }} lists the proto names of the fields redacted
+	// by {{ $msg.Name }}.Redact()
+	var redactedFields{{ $msg.Name }} = []string{
+		{{- if not $data.NoRedact }}
+			{{- range $name := $msg.RedactedFields }}"{{ $name }}", {{ end -}}
+		{{- end -}}
+	}
+
+	// RedactedFields returns the proto names of the fields redacted by Redact(),
+	// the nested messages list their own fields. The returned slice is shared
+	// and must not be modified.
+	func (x *{{ $msg.Name }}) RedactedFields() []string {
+		return redactedFields{{ $msg.Name }}
+	}
+	{{- end }}
 {{ end }}
 `
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
 	"google.golang.org/grpc/codes"
@@ -16,6 +19,8 @@ const (
 	// error message format specifiers
 	specifierMethod  = "%method%"
 	specifierService = "%service%"
+	specifierPackage = "%package%"
+	specifierFQN     = "%fqn%"
 )
 
 // Process processes the file and adds its generated code into Module.Artifacts
@@ -49,27 +54,381 @@ func (m *Module) Process(file pgs.File) {
 	}
 
 	data := &ProtoFileData{
-		Source:     file.Name().String(),
-		Package:    m.ctx.PackageName(file).String(),
-		Imports:    alias2Path,
-		References: m.references(file, nameWithAlias),
-		Services:   make([]*ServiceData, 0, len(file.Services())),
-		Messages:   make([]*MessageData, 0, len(file.AllMessages())),
+		Source:          file.Name().String(),
+		Version:         pluginVersion(),
+		AnnotationsHash: annotationsHash(file),
+		Package:         m.ctx.PackageName(file).String(),
+		Imports:         alias2Path,
+		References:      m.references(file, path2Alias, nameWithAlias),
+		Services:        make([]*ServiceData, 0, len(file.Services())),
+		Messages:        make([]*MessageData, 0, len(file.AllMessages())),
+		Fallible:        m.fallible,
+		BuildTag:        m.buildTag,
+		Stringer:        m.stringer,
+		RedactedJSON:    m.redactedJSON,
+
+		MessagesOnly:         m.messagesOnly,
+		ClearUnknown:         m.clearUnknown,
+		RedactedFieldsMethod: m.redactedFields,
 	}
 
-	// all services
+	if m.separatePackage != "" {
+		data.Package = m.separatePackage
+		data.MessagePackage = path2Alias[m.ctx.ImportPath(file).String()] + "."
+	}
+
+	if m.groupImports {
+		data.ImportGroups = m.importGroups(file, alias2Path)
+	}
+
+	if ref := m.ctxPredicate; ref != nil && m.generatesServices(file) {
+		data.CtxPredicate = ref.Name
+		if alias := path2Alias[ref.ImportPath]; alias != "" {
+			data.CtxPredicate = alias + "." + ref.Name
+		}
+	}
+
+	// the empty factories are resolved before the messages are processed
+	m.emptyFactoryCalls = map[string]string{}
+	for _, factory := range m.emptyFactories(file) {
+		fn := factory.ref.Name
+		if alias := path2Alias[factory.ref.ImportPath]; alias != "" {
+			fn = alias + "." + fn
+		}
+		m.emptyFactoryCalls[factory.msg.FullyQualifiedName()] = fn + "()"
+		data.EmptyFactories = append(data.EmptyFactories, &FactoryData{Func: fn, Message: nameWithAlias(factory.msg)})
+	}
+
+	// the default expressions are resolved with the aliases of the file
+	data.DefaultExprs = m.resolveDefaultExprs(path2Alias)
+	if !m.resolveFileDefaults(file) {
+		return
+	}
+
+	if m.placeholderFiles[file.Name().String()] {
+		if m.varPlaceholders || m.runtimeMarker {
+			data.Placeholders = m.placeholders()
+		}
+		data.VersionConst = m.versionConst
+	}
+
+	// all services, unless only the messages are generated
 	for _, srv := range file.Services() {
+		if m.messagesOnly {
+			break
+		}
 		data.Services = append(data.Services, m.processService(srv, nameWithAlias))
 	}
+	if m.registerRequests && m.generatesServices(file) {
+		data.Requests = m.requests(file, data.Services, nameWithAlias)
+	}
 
 	// all messages
-	for _, msg := range file.AllMessages() {
-		data.Messages = append(data.Messages, m.processMessage(msg, nameWithAlias, true))
+	data.Messages = append(data.Messages, m.processMessages(file.AllMessages(), nameWithAlias)...)
+	if m.maxDepth > 0 {
+		m.limitDepth(file.AllMessages(), data.Messages)
 	}
+	if m.requireRedaction {
+		m.checkRedaction(file.AllMessages(), data.Messages)
+	}
+	if m.separatePackage == "" {
+		// the Redact() methods are not generated with separate_package
+		data.ImportedRedactors = importedRedactors(data.Messages)
+	}
+	data.EmptyValues = emptyValues(data.Messages)
+	data.Regexps = regexps(data.Messages)
+	data.DepthLimited = depthLimited(data.Messages)
+	if data.DepthLimited && m.separatePackage != "" {
+		m.Fail(ValidationError{
+			Entity:   file.Name().String(),
+			Expected: "no depth limit with separate_package",
+			Got:      "fields limiting the depth of their nested redaction",
+			Hint:     "remove (redact.v3.value).message.depth, or separate_package",
+		})
+		return
+	}
+	redactSummaries(file.AllMessages(), data.Messages)
+
+	if m.reportOnly {
+		// dry-run: report what would be redacted, without generating the code
+		m.AddGeneratorFile(m.ctx.OutputPath(file).SetExt(".redact.report.txt").String(), m.report(data))
+	} else {
+		// render file in the template
+		name := m.goOutputPath(file, ".redact.go")
+		m.AddGeneratorTemplateFile(name.String(), m.tmpl, data)
+		if m.buildTag != "" {
+			// stub without redaction for the builds without the tag
+			stub := *data
+			stub.NoRedact = true
+			name = m.goOutputPath(file, ".redact.noredact.go")
+			m.AddGeneratorTemplateFile(name.String(), m.tmpl, &stub)
+		}
+	}
+
+	if m.emitMetadata {
+		meta, err := metadata(data)
+		if err != nil {
+			m.Failf("Cannot marshal redaction metadata of %s: %v", file.Name(), err)
+			return
+		}
+		m.AddGeneratorFile(m.ctx.OutputPath(file).SetExt(".redact.json").String(), string(meta)+"\n")
+	}
+}
 
-	// render file in the template
-	name := m.ctx.OutputPath(file).SetExt(".redact.go")
-	m.AddGeneratorTemplateFile(name.String(), m.tmpl, data)
+// goOutputPath returns the path of the Go file generated for the file with the
+// extension, in the directory of the sibling package with separate_package
+func (m *Module) goOutputPath(file pgs.File, ext string) pgs.FilePath {
+	name := m.ctx.OutputPath(file).SetExt(ext)
+	if m.separatePackage != "" {
+		name = name.Dir().Push(m.separatePackage).Push(name.Base())
+	}
+	return name
+}
+
+// processMessages processes the messages concurrently using a pool of workers,
+// the results keep the order of the input messages for a deterministic output
+func (m *Module) processMessages(
+	msgs []pgs.Message,
+	nameWithAlias func(n pgs.Entity) string,
+) []*MessageData {
+	res := make([]*MessageData, len(msgs))
+	workers := m.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(msgs) {
+		workers = len(msgs)
+	}
+	if workers <= 1 {
+		for i, msg := range msgs {
+			res[i] = m.processMessage(msg, nameWithAlias, true)
+		}
+		return res
+	}
+
+	// processMessage only reads the shared state of the Module and the
+	// message descriptors, each worker writes to its own result indexes
+	idx := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				res[i] = m.processMessage(msgs[i], nameWithAlias, true)
+			}
+		}()
+	}
+	for i := range msgs {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	return res
+}
+
+// emptyValues lists the shared empty values of the messages referenced by the
+// fields, once per message
+func emptyValues(msgs []*MessageData) []*PlaceholderData {
+	var list []*PlaceholderData
+	seen := make(map[string]bool)
+	for _, msg := range msgs {
+		for _, field := range msg.Fields {
+			if !field.SharedEmpty || seen[field.RedactionValue] {
+				continue
+			}
+			seen[field.RedactionValue] = true
+			list = append(list, &PlaceholderData{
+				Name:   field.RedactionValue,
+				GoType: "*" + field.EmbedMessageNameWithAlias,
+				Value:  fmt.Sprintf("&%s{}", field.EmbedMessageNameWithAlias),
+			})
+		}
+	}
+	return list
+}
+
+// resolveDefaultExprs resolves the default_<type>_expr expressions into the
+// redaction defaults of the processed file, a copy of the parameter defaults,
+// with its import aliases, and lists them sorted with their Go types
+func (m *Module) resolveDefaultExprs(path2Alias map[string]string) []*PlaceholderData {
+	m.defaults = make(map[pgs.ProtoType]string, len(m.paramDefaults))
+	for typ, value := range m.paramDefaults {
+		m.defaults[typ] = value
+	}
+	list := make([]*PlaceholderData, 0, len(m.defaultExprs))
+	for typ, expr := range m.defaultExprs {
+		value := expr.resolve(path2Alias)
+		m.defaults[typ] = value
+		list = append(list, &PlaceholderData{GoType: goTypeName(typ), Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Value != list[j].Value {
+			return list[i].Value < list[j].Value
+		}
+		return list[i].GoType < list[j].GoType
+	})
+	return list
+}
+
+// resolveFileDefaults sets the redaction defaults of the options of the file,
+// failing on an empty file_default_string
+func (m *Module) resolveFileDefaults(file pgs.File) bool {
+	m.fileDefaults = map[pgs.ProtoType]string{}
+	var str string
+	if !m.must(file.Extension(redact.E_FileDefaultString, &str)) {
+		return true
+	}
+	if str == "" {
+		m.Fail(ValidationError{
+			Entity:   file.Name().String(),
+			Expected: "a non-empty (redact.v3.file_default_string)",
+			Got:      "an empty string",
+			Hint:     "remove the option to redact the strings to the default value",
+		})
+		return false
+	}
+	// quoted as the default_string parameter
+	m.fileDefaults[pgs.StringT], _ = parseDefault(pgs.StringT, str)
+	return true
+}
+
+// regexps lists the compiled patterns of the regex_replace rules of the
+// fields, in their order of declaration
+func regexps(msgs []*MessageData) []*PlaceholderData {
+	var list []*PlaceholderData
+	for _, msg := range msgs {
+		for _, field := range msg.Fields {
+			if field.RegexVar == "" {
+				continue
+			}
+			list = append(list, &PlaceholderData{
+				Name:   field.RegexVar,
+				GoType: "*regexp.Regexp",
+				Value:  fmt.Sprintf("regexp.MustCompile(%s)", field.RegexPattern),
+			})
+		}
+	}
+	return list
+}
+
+// redactSummaries sets the summaries of the redacted fields of the messages,
+// documenting their Redact() methods. The data of the messages and of their
+// fields follow the order of the messages and of their fields.
+func redactSummaries(msgs []pgs.Message, data []*MessageData) {
+	for i, msg := range msgs {
+		if data[i] == nil || data[i].Ignore || data[i].ToNil || data[i].ToEmpty {
+			continue
+		}
+		var entries []string
+		for j, field := range msg.Fields() {
+			if j >= len(data[i].Fields) || !data[i].Fields[j].Redact {
+				continue
+			}
+			entries = append(entries, field.Name().String()+"->"+redactDescription(data[i].Fields[j]))
+		}
+		sort.Strings(entries)
+		data[i].RedactSummary = wrapSummary("Redact: ", entries, summaryWidth)
+	}
+}
+
+// summaryWidth is the width the summaries of the redacted fields are wrapped at
+const summaryWidth = 76
+
+// redactDescription describes how the field is redacted: the Go literal of its
+// redaction value, or its strategy, with its parameter if any
+func redactDescription(field *FieldData) string {
+	meta := fieldMeta(field)
+	switch {
+	case meta.Strategy == strategyValue:
+		return meta.Value
+	case meta.Value != "":
+		return meta.Strategy + "(" + meta.Value + ")"
+	}
+	return meta.Strategy
+}
+
+// wrapSummary joins the entries after the prefix, separated by commas, in lines
+// of at most width characters unless an entry is longer. The continuation lines
+// are not indented, gofmt would reformat them as code blocks.
+func wrapSummary(prefix string, entries []string, width int) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	var lines []string
+	line := prefix
+	for i, entry := range entries {
+		if i < len(entries)-1 {
+			entry += ","
+		}
+		switch {
+		case line == prefix:
+			line += entry
+		case len(line)+1+len(entry) > width:
+			lines = append(lines, line)
+			line = entry
+		default:
+			line += " " + entry
+		}
+	}
+	return append(lines, line)
+}
+
+// depthLimited checks if any nested call of the fields limits the depth of the
+// redaction
+func depthLimited(msgs []*MessageData) bool {
+	for _, msg := range msgs {
+		for _, field := range msg.Fields {
+			if field.NestedEmbedCall && field.Depth > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// importedRedactors lists the messages of other files called for redaction by
+// the fields of the messages, in the order of their first call
+func importedRedactors(msgs []*MessageData) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, msg := range msgs {
+		for _, field := range msg.Fields {
+			name := field.EmbedMessageNameWithAlias
+			if !field.ImportedRedactor || seen[name] {
+				continue
+			}
+			seen[name] = true
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// customFields lists the fields of the message routed through the registered
+// redact.FieldRedactor, the fields of a oneof are routed once by the oneof
+func (m *Module) customFields(msg pgs.Message) []*CustomFieldData {
+	fields := make([]*CustomFieldData, 0, len(msg.Fields()))
+	oneofs := make(map[string]bool)
+	for _, field := range msg.Fields() {
+		if !field.InRealOneOf() {
+			fields = append(fields, &CustomFieldData{
+				Name:   field.Name().String(),
+				GoName: m.ctx.Name(field).String(),
+			})
+			continue
+		}
+		oneof := field.OneOf()
+		if oneofs[oneof.Name().String()] {
+			continue
+		}
+		oneofs[oneof.Name().String()] = true
+		fields = append(fields, &CustomFieldData{
+			Name:   oneof.Name().String(),
+			GoName: m.ctx.Name(oneof).String(),
+		})
+	}
+	return fields
 }
 
 // processService extracts all pgs.Service and their pgs.Method(s) information and
@@ -87,8 +446,9 @@ func (m *Module) processService(
 	defer m.recoverFromPanic(fmt.Sprintf("processing service %s", srv.FullyQualifiedName()))
 
 	srvData := &ServiceData{
-		Name:    m.ctx.Name(srv).String(),
-		Methods: make([]*MethodData, 0, len(srv.Methods())),
+		Name:       m.ctx.Name(srv).String(),
+		ClientName: m.ctx.ClientName(srv).String(),
+		Methods:    make([]*MethodData, 0, len(srv.Methods())),
 	}
 
 	// check service option: ServiceSkip
@@ -107,12 +467,6 @@ func (m *Module) processService(
 	if !m.must(srv.Extension(redact.E_InternalServiceCode, &srvCode)) {
 		srvCode = uint32(codes.PermissionDenied)
 	}
-
-	// Validate status code with better error message
-	if err := m.validateStatusCode(srvCode, srv.FullyQualifiedName()); err != nil {
-		m.Fail(err)
-		return nil
-	}
 	srvErrMsg := ""
 	if !m.must(srv.Extension(redact.E_InternalServiceErrMessage, &srvErrMsg)) {
 		srvErrMsg = defaultErrMsg
@@ -139,12 +493,17 @@ func (m *Module) processService(
 			continue
 		}
 
+		// only the options of the output are read, an imported output is
+		// redacted by the Redact() generated in its own package
 		methData := &MethodData{
 			Name:            m.ctx.Name(meth).String(),
+			FullMethod:      fmt.Sprintf("/%s/%s", strings.TrimPrefix(srv.FullyQualifiedName(), "."), meth.Name()),
 			Input:           nameWithAlias(in),
 			Output:          m.processMessage(out, nameWithAlias),
 			ClientStreaming: meth.ClientStreaming(),
 			ServerStreaming: meth.ServerStreaming(),
+			InputRedactor:   m.redactor(srv.File(), in),
+			OutputRedactor:  m.redactor(srv.File(), out),
 		}
 		srvData.Methods = append(srvData.Methods, methData)
 
@@ -159,15 +518,27 @@ func (m *Module) processService(
 			continue
 		}
 
+		m.must(meth.Extension(redact.E_RedactInput, &methData.RedactInput))
+		if methData.RedactInput && methData.ClientStreaming {
+			m.Fail(ValidationError{
+				Entity:   fmt.Sprintf("method %s", meth.FullyQualifiedName()),
+				Expected: "unary or server streaming method with (redact.v3.redact_input)",
+				Got:      "client streaming method",
+				Hint:     "the streamed requests cannot be redacted",
+			})
+			continue
+		}
+
 		methInternal := false
 		m.must(meth.Extension(redact.E_InternalMethod, &methInternal))
 		methCode := srvCode // serviceCode
-		if !m.must(meth.Extension(redact.E_InternalMethodCode, &methCode)) {
+		inherited := !m.must(meth.Extension(redact.E_InternalMethodCode, &methCode))
+		if inherited {
 			methCode = srvCode
 		}
 
-		// Validate method status code with better error message
-		if err := m.validateStatusCode(methCode, meth.FullyQualifiedName()); err != nil {
+		// Validate the method status code, reporting where it is set
+		if err := m.validateMethodCode(meth, methCode, inherited); err != nil {
 			m.Fail(err)
 			continue
 		}
@@ -176,17 +547,98 @@ func (m *Module) processService(
 			methErrMsg = srvErrMsg
 		}
 
-		// apply format specifiers
-		methErrMsg = strings.ReplaceAll(methErrMsg, specifierMethod, methData.Name)
-		methErrMsg = strings.ReplaceAll(methErrMsg, specifierService, srvData.Name)
+		// apply format specifiers, an empty message falls back to the default
+		specifiers := errSpecifiers{
+			pkg:     srv.Package().ProtoName().String(),
+			service: srvData.Name,
+			method:  methData.Name,
+			fqn:     strings.TrimPrefix(meth.FullyQualifiedName(), "."),
+		}
+		methErrMsg = specifiers.format(methErrMsg)
+		if strings.TrimSpace(methErrMsg) == "" {
+			m.Debug(fmt.Sprintf("Method %s has an empty error message, using the default", meth.FullyQualifiedName()))
+			methErrMsg = specifiers.format(defaultErrMsg)
+		}
 
-		methData.ErrMessage = "`" + methErrMsg + "`"
+		methData.ErrMessage = goStringLiteral(methErrMsg)
 		methData.StatusCode = codes.Code(methCode).String()
 		methData.Internal = srvInternal || methInternal
 	}
+
+	// the replies have no Redact() methods to be called by the redacted
+	// client with separate_package
+	if m.separatePackage != "" {
+		srvData.Replies = replies(srvData.Methods)
+	}
 	return srvData
 }
 
+// replies lists the replies of the unary methods redacted by the redacted
+// client, once per type
+func replies(meths []*MethodData) []*ReplyData {
+	var list []*ReplyData
+	seen := make(map[string]bool)
+	for _, meth := range meths {
+		if meth.Skip || meth.ClientStreaming || meth.ServerStreaming || seen[meth.Output.WithAlias] {
+			continue
+		}
+		seen[meth.Output.WithAlias] = true
+		list = append(list, &ReplyData{Type: meth.Output.WithAlias, Redactor: meth.OutputRedactor})
+	}
+	return list
+}
+
+// requests lists the requests of the methods of the services, but the skipped
+// services, once per type. Only the requests of the Go package of the file are
+// registered, those of the other packages are registered by their own files,
+// or redacted by redact.ApplyReflect.
+func (m *Module) requests(file pgs.File, srvs []*ServiceData, nameWithAlias func(n pgs.Entity) string) []*RequestData {
+	local := make(map[string]bool)
+	for _, srv := range file.Services() {
+		for _, meth := range srv.Methods() {
+			if m.ctx.ImportPath(meth.Input().File()) == m.ctx.ImportPath(file) {
+				local[nameWithAlias(meth.Input())] = true
+			}
+		}
+	}
+
+	var list []*RequestData
+	seen := make(map[string]bool)
+	for _, srv := range srvs {
+		if srv == nil || srv.Skip {
+			continue
+		}
+		for _, meth := range srv.Methods {
+			if !local[meth.Input] || seen[meth.Input] {
+				continue
+			}
+			seen[meth.Input] = true
+			list = append(list, &RequestData{Type: meth.Input, Redactor: meth.InputRedactor})
+		}
+	}
+	return list
+}
+
+// errSpecifiers holds the values of the format specifiers of the internal
+// method error messages
+type errSpecifiers struct {
+	pkg     string // proto package
+	service string // Go name of the service server
+	method  string // Go name of the method
+	fqn     string // fully qualified proto name of the method
+}
+
+// format applies the format specifiers to the error message, in a single pass
+// and keeping the unknown specifiers as is
+func (s errSpecifiers) format(msg string) string {
+	return strings.NewReplacer(
+		specifierPackage, s.pkg,
+		specifierService, s.service,
+		specifierMethod, s.method,
+		specifierFQN, s.fqn,
+	).Replace(msg)
+}
+
 // processMessage extracts all pgs.Message and their pgs.Field(s) information and
 // structures them into MessageData
 func (m *Module) processMessage(
@@ -206,6 +658,8 @@ func (m *Module) processMessage(
 		Name:      m.ctx.Name(msg).String(),
 		WithAlias: nameWithAlias(msg),
 		Fields:    make([]*FieldData, 0, len(msg.Fields())*2),
+
+		EmptyFactory: m.emptyFactoryCalls[msg.FullyQualifiedName()],
 	}
 
 	// check message ignore options
@@ -229,10 +683,70 @@ func (m *Module) processMessage(
 		m.Debug(fmt.Sprintf("Warning: Message %s has both nil and empty options - this is invalid", msg.FullyQualifiedName()))
 	}
 
+	if gate := m.gateField(msg); gate != nil {
+		msgData.GatedBy = m.ctx.Name(gate).String()
+	}
+
 	if len(wantFields) > 0 {
+		msgData.ResetAndCopy = m.resetAndCopy
+		msgData.MaxFieldLen = m.maxFieldLen
+		allFields := false
+		m.must(msg.Extension(redact.E_AllFields, &allFields))
+		// the except option redacts all the fields but the listed ones
+		allFields = allFields || m.exceptFields(msg) != nil
 		for _, field := range msg.Fields() {
-			msgData.Fields = append(msgData.Fields, m.processFields(field, nameWithAlias))
+			flData := m.processFields(field, nameWithAlias, allFields)
+			// fields of real oneofs have no struct field of their own, these are
+			// copied back with their oneof, when set
+			flData.Keep = flData.Allow || flData.Redact
+			if flData.Redact && m.redactsDeprecated(field) {
+				m.Debug(fmt.Sprintf("Redacting deprecated field %s", field.FullyQualifiedName()))
+			}
+			if !msgData.ToNil && !msgData.ToEmpty {
+				m.checkPII(field, flData)
+			}
+			msgData.Fields = append(msgData.Fields, flData)
+			if m.proof && flData.Redact {
+				msgData.ProofPaths = append(msgData.ProofPaths, field.Name().String())
+			}
+			if m.redactedFields && flData.Redact && !flData.EmbedSkip && !msgData.ToNil && !msgData.ToEmpty {
+				msgData.RedactedFields = append(msgData.RedactedFields, field.Name().String())
+			}
+			if m.audit && flData.Redact && !flData.EmbedSkip {
+				flData.AuditName = field.Name().String()
+				msgData.AuditName = strings.TrimPrefix(msg.FullyQualifiedName(), ".")
+			}
+		}
+		m.must(msg.Extension(redact.E_UseCustomRedactor, &msgData.CustomRedactor))
+		if msgData.CustomRedactor {
+			msgData.CustomFields = m.customFields(msg)
+		}
+		if len(msgData.ProofPaths) > 0 {
+			msgData.ProofName = strings.TrimPrefix(msg.FullyQualifiedName(), ".")
+		}
+		if msgData.ResetAndCopy {
+			msgData.KeptOneofs = keptOneofs(msgData.Fields)
 		}
 	}
 	return msgData
 }
+
+// keptOneofs lists the oneofs with kept fields, copied back after the reset
+// when one of these is set, in their order of declaration
+func keptOneofs(fields []*FieldData) []*OneofData {
+	var list []*OneofData
+	byName := make(map[string]*OneofData)
+	for _, field := range fields {
+		if !field.InOneOf || !field.Keep {
+			continue
+		}
+		oneof, ok := byName[field.OneOf]
+		if !ok {
+			oneof = &OneofData{Name: field.OneOf}
+			byName[field.OneOf] = oneof
+			list = append(list, oneof)
+		}
+		oneof.Wrappers = append(oneof.Wrappers, field.OneOfWrapper)
+	}
+	return list
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// syntheticFile builds a proto file with n messages, each having redacted and
+// safe fields
+func syntheticFile(t testing.TB, n int) pgs.File {
+	t.Helper()
+
+	rules := &descriptorpb.FieldOptions{}
+	proto.SetExtension(rules, redact.E_Value, &redact.FieldRules{
+		Values: &redact.FieldRules_String_{String_: "hidden"},
+	})
+	msgs := make([]*descriptorpb.DescriptorProto, 0, n)
+	for i := 0; i < n; i++ {
+		msg := &descriptorpb.DescriptorProto{Name: proto.String(fmt.Sprintf("Message%03d", i))}
+		for j := int32(1); j <= 10; j++ {
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(fmt.Sprintf("field%d", j)),
+				JsonName: proto.String(fmt.Sprintf("field%d", j)),
+				Number:   proto.Int32(j),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}
+			if j%2 == 0 {
+				field.Options = rules
+			}
+			msg.Field = append(msg.Field, field)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("synthetic/synthetic.proto"),
+		Package:     proto.String("synthetic"),
+		Syntax:      proto.String("proto3"),
+		Dependency:  []string{"redact/v3/redact.proto"},
+		Options:     &descriptorpb.FileOptions{GoPackage: proto.String("example.com/synthetic;synthetic")},
+		MessageType: msgs,
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(redact.File_redact_v3_redact_proto),
+			file,
+		},
+	}
+	ast := pgs.ProcessCodeGeneratorRequest(pgs.InitMockDebugger(), req)
+	return ast.Targets()[file.GetName()]
+}
+
+// syntheticModule returns an initialized Module processing the messages with
+// the number of workers
+func syntheticModule(workers int) *Module {
+	m := &Module{ModuleBase: &pgs.ModuleBase{}}
+	m.InitContext(pgs.Context(pgs.InitMockDebugger(), pgs.Parameters{}, "."))
+	m.workers = workers
+	return m
+}
+
+// TestProcessMessagesOrder tests the concurrent processing keeps the order
+// of the messages
+func TestProcessMessagesOrder(t *testing.T) {
+	file := syntheticFile(t, 50)
+	msgs := file.AllMessages()
+
+	sequential := syntheticModule(1)
+	parallel := syntheticModule(8)
+	want := sequential.processMessages(msgs, func(n pgs.Entity) string { return sequential.ctx.Name(n).String() })
+	got := parallel.processMessages(msgs, func(n pgs.Entity) string { return parallel.ctx.Name(n).String() })
+
+	require.Len(t, got, len(msgs))
+	assert.Equal(t, want, got)
+	for i, msg := range msgs {
+		assert.Equal(t, msg.Name().String(), got[i].Name)
+	}
+}
+
+// TestRedactSummaries tests the summaries of the Redact() methods list the
+// redacted fields sorted by name, wrapped at the summary width
+func TestRedactSummaries(t *testing.T) {
+	file := syntheticFile(t, 1)
+	msgs := file.AllMessages()
+	m := syntheticModule(1)
+	data := m.processMessages(msgs, func(n pgs.Entity) string { return m.ctx.Name(n).String() })
+
+	redactSummaries(msgs, data)
+	assert.Equal(t, []string{
+		`Redact: field10->"hidden", field2->"hidden", field4->"hidden",`,
+		`field6->"hidden", field8->"hidden"`,
+	}, data[0].RedactSummary)
+}
+
+func TestWrapSummary(t *testing.T) {
+	assert.Nil(t, wrapSummary("Redact: ", nil, 20))
+	assert.Equal(t, []string{"Redact: a->0, b->nil,", "c->nested"},
+		wrapSummary("Redact: ", []string{"a->0", "b->nil", "c->nested"}, 22))
+	assert.Equal(t, []string{"Redact: a_very_long_entry"},
+		wrapSummary("Redact: ", []string{"a_very_long_entry"}, 10), "Should keep the entries longer than the width")
+}
+
+// TestMessageFieldsCallRedaction tests the message fields with the default
+// rules call the redaction of their message, without a redaction value
+func TestMessageFieldsCallRedaction(t *testing.T) {
+	allFields := &descriptorpb.MessageOptions{}
+	proto.SetExtension(allFields, redact.E_AllFields, true)
+	nested := &descriptorpb.FieldOptions{}
+	proto.SetExtension(nested, redact.E_Value, &redact.FieldRules{
+		Values: &redact.FieldRules_Element{Element: &redact.ElementRules{Nested: true}},
+	})
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(".synthetic.Inner"),
+		}
+	}
+	items := field("items", 2, descriptorpb.FieldDescriptorProto_LABEL_REPEATED)
+	items.Options = nested
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("synthetic/synthetic.proto"),
+		Package:    proto.String("synthetic"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/synthetic;synthetic")},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Inner")},
+			{
+				Name:    proto.String("Outer"),
+				Options: allFields,
+				Field:   []*descriptorpb.FieldDescriptorProto{field("inner", 1, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL), items},
+			},
+		},
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(redact.File_redact_v3_redact_proto),
+			file,
+		},
+	}
+	ast := pgs.ProcessCodeGeneratorRequest(pgs.InitMockDebugger(), req)
+	msgs := ast.Targets()[file.GetName()].AllMessages()
+	m := syntheticModule(1)
+	data := m.processMessages(msgs, func(n pgs.Entity) string { return m.ctx.Name(n).String() })
+
+	require.Len(t, data, 2)
+	single, list := data[1].Fields[0], data[1].Fields[1]
+	assert.True(t, single.NestedEmbedCall, "Should call the redaction of the message")
+	assert.Empty(t, single.RedactionValue, "Should have no redaction value")
+	assert.True(t, list.NestedEmbedCall, "Should call the redaction of the items")
+	assert.True(t, list.Iterate)
+}
+
+// BenchmarkProcessMessages compares the sequential and concurrent processing
+// of a file with 200 messages
+func BenchmarkProcessMessages(b *testing.B) {
+	file := syntheticFile(b, 200)
+	msgs := file.AllMessages()
+
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{
+		{name: "sequential", workers: 1},
+		{name: "parallel", workers: 0},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			m := syntheticModule(bench.workers)
+			nameWithAlias := func(n pgs.Entity) string { return m.ctx.Name(n).String() }
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.processMessages(msgs, nameWithAlias)
+			}
+		})
+	}
+}
+
+// BenchmarkProcessFields processes a message with many annotated fields
+func BenchmarkProcessFields(b *testing.B) {
+	msg := syntheticFile(b, 1).AllMessages()[0]
+	m := syntheticModule(1)
+	nameWithAlias := func(n pgs.Entity) string { return m.ctx.Name(n).String() }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, field := range msg.Fields() {
+			m.processFields(field, nameWithAlias, false)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package redact
+
+import "sync/atomic"
+
+// nopAuditHook is the default audit hook, discarding the redacted fields
+func nopAuditHook(string, string) {}
+
+// auditHook holds the current audit hook
+var auditHook atomic.Value
+
+// SetAuditHook sets the hook called for each field redacted by the Redact()
+// methods, e.g. to verify the coverage of the redaction in production. It must
+// be safe for concurrent use, nil restores the default hook discarding the
+// fields.
+func SetAuditHook(hook func(msg, field string)) {
+	if hook == nil {
+		hook = nopAuditHook
+	}
+	auditHook.Store(hook)
+}
+
+// Audit reports that the field of the message, by their proto names, was
+// redacted. It is called by the `Redact()` methods generated with the `audit`
+// option.
+func Audit(msg, field string) {
+	if hook, ok := auditHook.Load().(func(msg, field string)); ok {
+		hook(msg, field)
+	}
+}
@@ -0,0 +1,19 @@
+package redact
+
+import "google.golang.org/protobuf/proto"
+
+// ApplyCopy returns a clone of the message redacted by Apply, the message itself
+// is not modified, e.g. for the pipelines treating the messages as immutable.
+// A nil message is returned as nil. When FallibleRedactor fails, nil is
+// returned rather than a partially redacted clone, as by RedactRequest, the
+// callers needing the error must clone the message and call Apply.
+func ApplyCopy(m proto.Message) proto.Message {
+	if m == nil {
+		return nil
+	}
+	clone := proto.Clone(m)
+	if err := Apply(clone); err != nil {
+		return nil
+	}
+	return clone
+}
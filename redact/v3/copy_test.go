@@ -0,0 +1,39 @@
+package redact
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestApplyCopy(t *testing.T) {
+	if got := ApplyCopy(nil); got != nil {
+		t.Errorf("ApplyCopy(nil) = %v, want nil", got)
+	}
+
+	desc := dynamicAccount(t)
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(desc.Fields().ByName("password"), protoreflect.ValueOfString("secret"))
+
+	// the dynamic messages have no Redact() method, the copy is kept as is
+	got := ApplyCopy(msg)
+	if got == proto.Message(msg) {
+		t.Fatal("ApplyCopy should return a clone")
+	}
+	if !proto.Equal(got, msg) {
+		t.Errorf("ApplyCopy should keep the messages without Redact(), got %v", got)
+	}
+}
+
+func TestApplyCopyFailure(t *testing.T) {
+	msg := FailingMessage{StringValue: wrapperspb.String("secret")}
+	if got := ApplyCopy(msg); got != nil {
+		t.Errorf("ApplyCopy should not return a partially redacted clone, got %v", got)
+	}
+	if msg.Value != "secret" {
+		t.Errorf("the message should be kept, got %q", msg.Value)
+	}
+}
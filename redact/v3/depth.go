@@ -0,0 +1,17 @@
+package redact
+
+// NestedDepth returns the depth of the redaction of a nested message, from the
+// remaining depth of the message embedding it and the limit of its field, e.g.
+// `message.depth`. The depths and limits lower than 1 are unlimited, the limit
+// applies when it is lower than the remaining depth. The nested messages of a
+// message redacted at depth 1 are left intact, hence it is not called.
+func NestedDepth(depth, limit int) int {
+	next := 0
+	if depth > 1 {
+		next = depth - 1
+	}
+	if limit > 0 && (next == 0 || limit < next) {
+		next = limit
+	}
+	return next
+}
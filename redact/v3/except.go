@@ -0,0 +1,15 @@
+package redact
+
+import "strings"
+
+// ExceptFields splits the `except` message option into the proto names of the
+// kept fields, the blank names are ignored
+func ExceptFields(except string) []string {
+	var names []string
+	for _, name := range strings.Split(except, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
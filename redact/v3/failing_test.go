@@ -0,0 +1,46 @@
+package redact
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrRedaction is the error of the FailingMessage redaction, exported for the
+// external tests
+var ErrRedaction = errors.New("redaction failed")
+
+// FailingMessage is a message whose FallibleRedactor fails after redacting its
+// value, its clones are FailingMessages too. It is exported for the external
+// tests of the package.
+type FailingMessage struct {
+	*wrapperspb.StringValue
+}
+
+// Redact partially redacts the message, and fails
+func (m FailingMessage) Redact() error {
+	m.Value = "partial"
+	return ErrRedaction
+}
+
+// ProtoReflect returns the reflection of the message, keeping its type
+func (m FailingMessage) ProtoReflect() protoreflect.Message {
+	return failingReflect{m.StringValue.ProtoReflect()}
+}
+
+// failingReflect is the reflection of FailingMessage
+type failingReflect struct {
+	protoreflect.Message
+}
+
+func (r failingReflect) New() protoreflect.Message { return failingReflect{r.Message.New()} }
+
+func (r failingReflect) Interface() protoreflect.ProtoMessage {
+	return FailingMessage{r.Message.Interface().(*wrapperspb.StringValue)}
+}
+
+// ProtoMethods disables the fast paths of the wrapped message, which cannot
+// handle the wrapper
+func (r failingReflect) ProtoMethods() *protoiface.Methods { return nil }
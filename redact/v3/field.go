@@ -0,0 +1,52 @@
+package redact
+
+import "sync/atomic"
+
+// FieldRedactor redacts the field values at runtime, centralizing the
+// redaction policy. The fields of the messages with the `use_custom_redactor`
+// option are routed through the registered FieldRedactor by their proto name,
+// the fields of a oneof are routed as a whole by the name of the oneof. It
+// must be safe for concurrent use.
+type FieldRedactor interface {
+	RedactField(name string, v any) any
+}
+
+// FieldRedactorFunc helps to implement FieldRedactor
+type FieldRedactorFunc func(name string, v any) any
+
+// RedactField for FieldRedactorFunc
+func (f FieldRedactorFunc) RedactField(name string, v any) any { return f(name, v) }
+
+// NopRedactor is the default FieldRedactor, keeping the values as is
+var NopRedactor = FieldRedactorFunc(func(_ string, v any) any { return v })
+
+// redactor holds the registered FieldRedactor
+var redactor atomic.Value
+
+// SetRedactor registers the FieldRedactor, nil restores the NopRedactor
+func SetRedactor(r FieldRedactor) {
+	if r == nil {
+		r = NopRedactor
+	}
+	redactor.Store(&r)
+}
+
+// GetRedactor returns the registered FieldRedactor, the NopRedactor by default
+func GetRedactor() FieldRedactor {
+	if r, ok := redactor.Load().(*FieldRedactor); ok {
+		return *r
+	}
+	return NopRedactor
+}
+
+// RedactFieldAs routes the field value through the FieldRedactor, keeping its
+// type. A value of another type returned by the redactor is replaced by the
+// zero value, never by the unredacted one.
+func RedactFieldAs[T any](r FieldRedactor, name string, v T) T {
+	out, ok := r.RedactField(name, v).(T)
+	if !ok {
+		var zero T
+		return zero
+	}
+	return out
+}
@@ -3,17 +3,29 @@ package redact
 
 import "context"
 
-// Redactor provides the method to be used to Redact
+// Redactor provides the method to be used to Redact, it is implemented by the
+// generated code which returns the string representation of the redacted message
 type Redactor interface {
-	Redact()
+	Redact() string
 }
 
-// Apply will apply redaction on the input, if it implements Redactor.
-// It will do nothing if the object does not implement the interface.
-func Apply(in interface{}) {
-	if red, ok := in.(Redactor); ok {
+// FallibleRedactor provides the method to be used to Redact, it is implemented
+// by the code generated with the `fallible` option, for redactions which can fail
+type FallibleRedactor interface {
+	Redact() error
+}
+
+// Apply will apply redaction on the input, if it implements Redactor or
+// FallibleRedactor, returning the error of the latter.
+// It will do nothing if the object does not implement the interfaces.
+func Apply(in interface{}) error {
+	switch red := in.(type) {
+	case Redactor:
 		red.Redact()
+	case FallibleRedactor:
+		return red.Redact()
 	}
+	return nil
 }
 
 // Bypass provides a way to bypass the internal marked methods to be ran
@@ -0,0 +1,70 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// LogRecord is the log record handled by LogProcessor, it is implemented by an
+// adapter of the records of the logging pipeline, e.g. of the OpenTelemetry
+// logs SDK, keeping the OpenTelemetry dependency out of this package
+type LogRecord interface {
+	// WalkAttributes calls f for each attribute of the record, until it
+	// returns false
+	WalkAttributes(f func(key string, value interface{}) bool)
+	// SetAttribute replaces the value of the attribute of the record
+	SetAttribute(key string, value interface{})
+}
+
+// LogRecordProcessor processes the log records, e.g. the next processor of
+// the pipeline, or its exporter
+type LogRecordProcessor interface {
+	OnEmit(ctx context.Context, record LogRecord) error
+}
+
+// LogProcessor redacts the proto messages found in the attributes of the log
+// records before passing them to the next processor, plugging the redaction
+// in the logging pipeline centrally. Only the values of the attributes which
+// are proto messages are redacted.
+type LogProcessor struct {
+	next LogRecordProcessor
+}
+
+// NewLogProcessor returns a LogProcessor passing the redacted records to next,
+// a nil next drops them
+func NewLogProcessor(next LogRecordProcessor) *LogProcessor {
+	return &LogProcessor{next: next}
+}
+
+// OnEmit replaces the proto messages of the attributes of the record by their
+// clones redacted by Apply, the messages themselves are not modified, then
+// passes the record to the next processor. The attributes whose redaction
+// fails are set to nil rather than exported partially redacted, and the
+// errors of the redaction are returned.
+func (p *LogProcessor) OnEmit(ctx context.Context, record LogRecord) error {
+	redacted := make(map[string]interface{})
+	var errs []error
+	record.WalkAttributes(func(key string, value interface{}) bool {
+		if msg, ok := value.(proto.Message); ok && msg != nil && msg.ProtoReflect().IsValid() {
+			clone := proto.Clone(msg)
+			if err := Apply(clone); err != nil {
+				errs = append(errs, fmt.Errorf("redacting attribute %s: %w", key, err))
+				redacted[key] = nil
+				return true
+			}
+			redacted[key] = clone
+		}
+		return true
+	})
+	// the attributes are replaced once walked, not while walking them
+	for key, value := range redacted {
+		record.SetAttribute(key, value)
+	}
+	if p.next != nil {
+		errs = append(errs, p.next.OnEmit(ctx, record))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,93 @@
+package redact_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/menta2k/protoc-gen-redact/v3/examples/tests"
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// logRecord is a log record holding its attributes in order
+type logRecord struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (r *logRecord) WalkAttributes(f func(key string, value interface{}) bool) {
+	for _, key := range r.keys {
+		if !f(key, r.values[key]) {
+			return
+		}
+	}
+}
+
+func (r *logRecord) SetAttribute(key string, value interface{}) {
+	r.values[key] = value
+}
+
+// recordingProcessor records the emitted records
+type recordingProcessor struct {
+	records []redact.LogRecord
+}
+
+func (p *recordingProcessor) OnEmit(_ context.Context, record redact.LogRecord) error {
+	p.records = append(p.records, record)
+	return nil
+}
+
+func TestLogProcessor(t *testing.T) {
+	msg := &tests.TestMessage{StringValue: "secret"}
+	record := &logRecord{
+		keys:   []string{"request", "user"},
+		values: map[string]interface{}{"request": msg, "user": "john"},
+	}
+	next := &recordingProcessor{}
+
+	if err := redact.NewLogProcessor(next).OnEmit(context.Background(), record); err != nil {
+		t.Fatalf("OnEmit failed: %v", err)
+	}
+
+	if len(next.records) != 1 {
+		t.Fatalf("the record should be passed to the next processor, got %d records", len(next.records))
+	}
+	logged, ok := record.values["request"].(*tests.TestMessage)
+	if !ok || logged == msg {
+		t.Fatalf("the message should be replaced by a clone, got %T", record.values["request"])
+	}
+	if logged.StringValue != "redacted-value-value" {
+		t.Errorf("the logged message should be redacted, got %q", logged.StringValue)
+	}
+	if msg.StringValue != "secret" {
+		t.Errorf("the message should be kept, got %q", msg.StringValue)
+	}
+	if record.values["user"] != "john" {
+		t.Errorf("the other attributes should be kept, got %v", record.values["user"])
+	}
+}
+
+func TestLogProcessorFailure(t *testing.T) {
+	msg := redact.FailingMessage{StringValue: wrapperspb.String("secret")}
+	record := &logRecord{
+		keys:   []string{"request", "user"},
+		values: map[string]interface{}{"request": msg, "user": "john"},
+	}
+	next := &recordingProcessor{}
+
+	err := redact.NewLogProcessor(next).OnEmit(context.Background(), record)
+	if err == nil || !errors.Is(err, redact.ErrRedaction) {
+		t.Errorf("OnEmit should return the redaction error, got %v", err)
+	}
+	if record.values["request"] != nil {
+		t.Errorf("the attribute failing the redaction should be dropped, got %v", record.values["request"])
+	}
+	if len(next.records) != 1 || record.values["user"] != "john" {
+		t.Errorf("the record should be passed to the next processor with its other attributes")
+	}
+	if msg.Value != "secret" {
+		t.Errorf("the message should be kept, got %q", msg.Value)
+	}
+}
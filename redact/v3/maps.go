@@ -0,0 +1,15 @@
+package redact
+
+// RedactMapKeys rebuilds the map with its keys redacted by key, the entries
+// whose keys are redacted to the same value are merged, keeping an arbitrary
+// one of their values. A nil map is kept nil.
+func RedactMapKeys[V any](m map[string]V, key func(string) string) map[string]V {
+	if m == nil {
+		return nil
+	}
+	res := make(map[string]V, len(m))
+	for k, v := range m {
+		res[key(k)] = v
+	}
+	return res
+}
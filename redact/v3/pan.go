@@ -0,0 +1,63 @@
+package redact
+
+import "unicode/utf8"
+
+// panMask is the character replacing the masked digits of card numbers
+const panMask = '*'
+
+// MaskPAN masks a card number (PAN) but its last four digits, keeping the
+// separators in place e.g. `4111-1111-1111-1111` is masked to
+// `****-****-****-1111`. Values which are not valid card numbers, having 12 to
+// 19 digits and passing the Luhn check, are fully masked. An empty value is
+// kept empty.
+func MaskPAN(s string) string {
+	digits := 0
+	for _, c := range s {
+		if isDigit(c) {
+			digits++
+		}
+	}
+	if digits < 12 || digits > 19 || !luhn(s) {
+		out := make([]rune, utf8.RuneCountInString(s))
+		for i := range out {
+			out[i] = panMask
+		}
+		return string(out)
+	}
+
+	out := []rune(s)
+	keep := 4
+	for i := len(out) - 1; i >= 0; i-- {
+		if !isDigit(out[i]) {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		out[i] = panMask
+	}
+	return string(out)
+}
+
+// luhn checks the digits of the value against the Luhn checksum
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if !isDigit(rune(s[i])) {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isDigit(c rune) bool { return c >= '0' && c <= '9' }
@@ -0,0 +1,44 @@
+package redact
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ProofSink records the proofs of redaction, e.g. appending them to a hash
+// chain used as tamper-evidence by audits. It must be safe for concurrent use.
+type ProofSink interface {
+	RecordProof(typeName string, fieldPaths []string, at time.Time)
+}
+
+// ProofSinkFunc helps to implement ProofSink
+type ProofSinkFunc func(typeName string, fieldPaths []string, at time.Time)
+
+// RecordProof for ProofSinkFunc
+func (f ProofSinkFunc) RecordProof(typeName string, fieldPaths []string, at time.Time) {
+	f(typeName, fieldPaths, at)
+}
+
+// NopProofSink is the default ProofSink, discarding the proofs
+var NopProofSink = ProofSinkFunc(func(string, []string, time.Time) {})
+
+// proofSink holds the current ProofSink
+var proofSink atomic.Value
+
+// SetProofSink sets the sink recording the proofs of redaction, nil restores
+// the NopProofSink
+func SetProofSink(sink ProofSink) {
+	if sink == nil {
+		sink = NopProofSink
+	}
+	proofSink.Store(&sink)
+}
+
+// RecordProof records that the fields of the message, by their proto paths,
+// were redacted at the given time. It is called by the `Redact()` methods
+// generated with the `proof` option.
+func RecordProof(typeName string, fieldPaths []string, at time.Time) {
+	if sink, ok := proofSink.Load().(*ProofSink); ok {
+		(*sink).RecordProof(typeName, fieldPaths, at)
+	}
+}
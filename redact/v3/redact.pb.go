@@ -52,6 +52,14 @@ type FieldRules struct {
 	//	*FieldRules_Enum
 	//	*FieldRules_Message
 	//	*FieldRules_Element
+	//	*FieldRules_PanMask
+	//	*FieldRules_EnumLast
+	//	*FieldRules_Round
+	//	*FieldRules_RoundTo
+	//	*FieldRules_CopyFrom
+	//	*FieldRules_ZeroFill
+	//	*FieldRules_RegexReplace
+	//	*FieldRules_Magnitude
 	Values isFieldRules_Values `protobuf_oneof:"values"`
 }
 
@@ -220,6 +228,62 @@ func (x *FieldRules) GetElement() *ElementRules {
 	return nil
 }
 
+func (x *FieldRules) GetPanMask() bool {
+	if x, ok := x.GetValues().(*FieldRules_PanMask); ok {
+		return x.PanMask
+	}
+	return false
+}
+
+func (x *FieldRules) GetEnumLast() bool {
+	if x, ok := x.GetValues().(*FieldRules_EnumLast); ok {
+		return x.EnumLast
+	}
+	return false
+}
+
+func (x *FieldRules) GetRound() int64 {
+	if x, ok := x.GetValues().(*FieldRules_Round); ok {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *FieldRules) GetRoundTo() float64 {
+	if x, ok := x.GetValues().(*FieldRules_RoundTo); ok {
+		return x.RoundTo
+	}
+	return 0
+}
+
+func (x *FieldRules) GetCopyFrom() string {
+	if x, ok := x.GetValues().(*FieldRules_CopyFrom); ok {
+		return x.CopyFrom
+	}
+	return ""
+}
+
+func (x *FieldRules) GetZeroFill() bool {
+	if x, ok := x.GetValues().(*FieldRules_ZeroFill); ok {
+		return x.ZeroFill
+	}
+	return false
+}
+
+func (x *FieldRules) GetRegexReplace() *RegexReplace {
+	if x, ok := x.GetValues().(*FieldRules_RegexReplace); ok {
+		return x.RegexReplace
+	}
+	return nil
+}
+
+func (x *FieldRules) GetMagnitude() bool {
+	if x, ok := x.GetValues().(*FieldRules_Magnitude); ok {
+		return x.Magnitude
+	}
+	return false
+}
+
 type isFieldRules_Values interface {
 	isFieldRules_Values()
 }
@@ -299,6 +363,61 @@ type FieldRules_Element struct {
 	Element *ElementRules `protobuf:"bytes,20,opt,name=element,proto3,oneof"`
 }
 
+type FieldRules_PanMask struct {
+	// PanMask masks the digits of a card number (PAN) in a string field but the
+	// last four, separators are kept e.g. `**** **** **** 1234`. Values which are
+	// not valid card numbers (Luhn check) are fully masked.
+	PanMask bool `protobuf:"varint,21,opt,name=pan_mask,json=panMask,proto3,oneof"`
+}
+
+type FieldRules_EnumLast struct {
+	// EnumLast redacts an enum field to its highest-numbered value, e.g. a
+	// sentinel `REDACTED` value declared last. The `enum` rule sets a number
+	// instead, as `enum` is a scalar it cannot hold this option.
+	EnumLast bool `protobuf:"varint,22,opt,name=enum_last,json=enumLast,proto3,oneof"`
+}
+
+type FieldRules_Round struct {
+	// Round truncates an integer field toward zero to a multiple of the factor,
+	// e.g. 1234 is redacted to 1000 with `round = 1000`, keeping approximate
+	// values for analytics. The factor must be nonzero and fit the field type.
+	Round int64 `protobuf:"varint,23,opt,name=round,proto3,oneof"`
+}
+
+type FieldRules_RoundTo struct {
+	// RoundTo truncates a float or double field toward zero to a multiple of the
+	// factor, e.g. 12.34 is redacted to 12 with `round_to = 0.5`
+	RoundTo float64 `protobuf:"fixed64,24,opt,name=round_to,json=roundTo,proto3,oneof"`
+}
+
+type FieldRules_CopyFrom struct {
+	// CopyFrom sets the field to the value of a sibling field of the same type,
+	// given by its proto name, e.g. a hashed `user_id` as a proxy of the
+	// `display_name`. The fields are redacted in their order of declaration,
+	// hence a redacted sibling declared before is copied redacted.
+	CopyFrom string `protobuf:"bytes,25,opt,name=copy_from,json=copyFrom,proto3,oneof"`
+}
+
+type FieldRules_ZeroFill struct {
+	// ZeroFill replaces a bytes field by a zeroed slice of the same length, for
+	// the downstream systems validating the length, nil values are kept nil
+	ZeroFill bool `protobuf:"varint,26,opt,name=zero_fill,json=zeroFill,proto3,oneof"`
+}
+
+type FieldRules_RegexReplace struct {
+	// RegexReplace replaces the matches of a regular expression in a string
+	// field, e.g. the digits of free-form text, keeping the rest of the value.
+	// The pattern is compiled once per package, it must be a valid RE2 syntax.
+	RegexReplace *RegexReplace `protobuf:"bytes,27,opt,name=regex_replace,json=regexReplace,proto3,oneof"`
+}
+
+type FieldRules_Magnitude struct {
+	// Magnitude redacts an integer field to its order of magnitude, the largest
+	// power of ten not above its absolute value with its sign, e.g. 12345 is
+	// redacted to 10000 and -42 to -10, zero is kept
+	Magnitude bool `protobuf:"varint,28,opt,name=magnitude,proto3,oneof"`
+}
+
 func (*FieldRules_Float) isFieldRules_Values() {}
 
 func (*FieldRules_Double) isFieldRules_Values() {}
@@ -335,6 +454,82 @@ func (*FieldRules_Message) isFieldRules_Values() {}
 
 func (*FieldRules_Element) isFieldRules_Values() {}
 
+func (*FieldRules_PanMask) isFieldRules_Values() {}
+
+func (*FieldRules_EnumLast) isFieldRules_Values() {}
+
+func (*FieldRules_Round) isFieldRules_Values() {}
+
+func (*FieldRules_RoundTo) isFieldRules_Values() {}
+
+func (*FieldRules_CopyFrom) isFieldRules_Values() {}
+
+func (*FieldRules_ZeroFill) isFieldRules_Values() {}
+
+func (*FieldRules_RegexReplace) isFieldRules_Values() {}
+
+func (*FieldRules_Magnitude) isFieldRules_Values() {}
+
+// RegexReplace describes the replacement of the matches of a pattern in a string
+// field, the replacement may reference the submatches as `$1` or `${name}`, as
+// in regexp.Regexp.ReplaceAllString
+type RegexReplace struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Pattern is the regular expression, in RE2 syntax
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// Replacement is the text the matches are replaced by
+	Replacement string `protobuf:"bytes,2,opt,name=replacement,proto3" json:"replacement,omitempty"`
+}
+
+func (x *RegexReplace) Reset() {
+	*x = RegexReplace{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redact_v3_redact_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegexReplace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegexReplace) ProtoMessage() {}
+
+func (x *RegexReplace) ProtoReflect() protoreflect.Message {
+	mi := &file_redact_v3_redact_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegexReplace.ProtoReflect.Descriptor instead.
+func (*RegexReplace) Descriptor() ([]byte, []int) {
+	return file_redact_v3_redact_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegexReplace) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *RegexReplace) GetReplacement() string {
+	if x != nil {
+		return x.Replacement
+	}
+	return ""
+}
+
 // MessageRules describe the constraints applied to embedded message for redaction.
 // For message-type fields, rules are performed recursively.
 type MessageRules struct {
@@ -350,12 +545,18 @@ type MessageRules struct {
 	Nil bool `protobuf:"varint,3,opt,name=nil,proto3" json:"nil,omitempty"`
 	// Apply specifies that redaction is to be called for the message type
 	Apply bool `protobuf:"varint,4,opt,name=apply,proto3" json:"apply,omitempty"`
+	// Depth limits the nested redaction to the given number of levels, e.g. with
+	// `depth = 1` the message is redacted by its own rules but the messages it
+	// embeds are left intact. The message must be defined in the same file, the
+	// messages of the other files are redacted by their Redact() method as a
+	// whole. 0 is unlimited.
+	Depth int32 `protobuf:"varint,5,opt,name=depth,proto3" json:"depth,omitempty"`
 }
 
 func (x *MessageRules) Reset() {
 	*x = MessageRules{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_redact_v3_redact_proto_msgTypes[1]
+		mi := &file_redact_v3_redact_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -368,7 +569,7 @@ func (x *MessageRules) String() string {
 func (*MessageRules) ProtoMessage() {}
 
 func (x *MessageRules) ProtoReflect() protoreflect.Message {
-	mi := &file_redact_v3_redact_proto_msgTypes[1]
+	mi := &file_redact_v3_redact_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +582,7 @@ func (x *MessageRules) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MessageRules.ProtoReflect.Descriptor instead.
 func (*MessageRules) Descriptor() ([]byte, []int) {
-	return file_redact_v3_redact_proto_rawDescGZIP(), []int{1}
+	return file_redact_v3_redact_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *MessageRules) GetSkip() bool {
@@ -412,6 +613,13 @@ func (x *MessageRules) GetApply() bool {
 	return false
 }
 
+func (x *MessageRules) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
 // ElementRules describe the constraints applied to `repeated` or `map` values
 type ElementRules struct {
 	state         protoimpl.MessageState
@@ -426,12 +634,26 @@ type ElementRules struct {
 	// Item specifies that some custom redaction rules to be applied `recursively`
 	// on each item in map/list.
 	Item *FieldRules `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`
+	// ClearElements specifies that the count of items is kept but each item of
+	// the list/map is cleared: messages are replaced by empty messages and
+	// scalars by their zero value
+	ClearElements bool `protobuf:"varint,4,opt,name=clear_elements,json=clearElements,proto3" json:"clear_elements,omitempty"`
+	// Key specifies the redaction rules of the keys of a map with string keys,
+	// either `string` or `pan_mask`. The map is rebuilt with the redacted keys,
+	// and the values redacted by the `item` rules if any, hence the entries
+	// whose keys are redacted to the same value are merged.
+	Key *FieldRules `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	// Collapse specifies that a non-empty list of scalars is replaced by a list
+	// holding a single sentinel item, signaling that data existed but was
+	// removed: the value of the `item` rules, or the redaction default of the
+	// type. Empty lists are kept empty.
+	Collapse bool `protobuf:"varint,6,opt,name=collapse,proto3" json:"collapse,omitempty"`
 }
 
 func (x *ElementRules) Reset() {
 	*x = ElementRules{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_redact_v3_redact_proto_msgTypes[2]
+		mi := &file_redact_v3_redact_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -444,7 +666,7 @@ func (x *ElementRules) String() string {
 func (*ElementRules) ProtoMessage() {}
 
 func (x *ElementRules) ProtoReflect() protoreflect.Message {
-	mi := &file_redact_v3_redact_proto_msgTypes[2]
+	mi := &file_redact_v3_redact_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,7 +679,7 @@ func (x *ElementRules) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ElementRules.ProtoReflect.Descriptor instead.
 func (*ElementRules) Descriptor() ([]byte, []int) {
-	return file_redact_v3_redact_proto_rawDescGZIP(), []int{2}
+	return file_redact_v3_redact_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ElementRules) GetEmpty() bool {
@@ -481,6 +703,27 @@ func (x *ElementRules) GetItem() *FieldRules {
 	return nil
 }
 
+func (x *ElementRules) GetClearElements() bool {
+	if x != nil {
+		return x.ClearElements
+	}
+	return false
+}
+
+func (x *ElementRules) GetKey() *FieldRules {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *ElementRules) GetCollapse() bool {
+	if x != nil {
+		return x.Collapse
+	}
+	return false
+}
+
 var file_redact_v3_redact_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.FileOptions)(nil),
@@ -490,6 +733,14 @@ var file_redact_v3_redact_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "varint,90102,opt,name=file_skip",
 		Filename:      "redact/v3/redact.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         90103,
+		Name:          "redact.v3.file_default_string",
+		Tag:           "bytes,90103,opt,name=file_default_string",
+		Filename:      "redact/v3/redact.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
 		ExtensionType: (*bool)(nil),
@@ -554,6 +805,14 @@ var file_redact_v3_redact_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,54126,opt,name=internal_method_err_message",
 		Filename:      "redact/v3/redact.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         54127,
+		Name:          "redact.v3.redact_input",
+		Tag:           "varint,54127,opt,name=redact_input",
+		Filename:      "redact/v3/redact.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
 		ExtensionType: (*bool)(nil),
@@ -578,6 +837,54 @@ var file_redact_v3_redact_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "varint,54125,opt,name=ignored",
 		Filename:      "redact/v3/redact.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         54126,
+		Name:          "redact.v3.use_custom_redactor",
+		Tag:           "varint,54126,opt,name=use_custom_redactor",
+		Filename:      "redact/v3/redact.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         54128,
+		Name:          "redact.v3.all_fields",
+		Tag:           "varint,54128,opt,name=all_fields",
+		Filename:      "redact/v3/redact.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         54129,
+		Name:          "redact.v3.auto_nested",
+		Tag:           "varint,54129,opt,name=auto_nested",
+		Filename:      "redact/v3/redact.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         54130,
+		Name:          "redact.v3.except",
+		Tag:           "bytes,54130,opt,name=except",
+		Filename:      "redact/v3/redact.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         54131,
+		Name:          "redact.v3.empty_factory",
+		Tag:           "bytes,54131,opt,name=empty_factory",
+		Filename:      "redact/v3/redact.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         54132,
+		Name:          "redact.v3.gated_by",
+		Tag:           "bytes,54132,opt,name=gated_by",
+		Filename:      "redact/v3/redact.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
 		ExtensionType: (*FieldRules)(nil),
@@ -586,6 +893,14 @@ var file_redact_v3_redact_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,54123,opt,name=value",
 		Filename:      "redact/v3/redact.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         54124,
+		Name:          "redact.v3.allow",
+		Tag:           "varint,54124,opt,name=allow",
+		Filename:      "redact/v3/redact.proto",
+	},
 }
 
 // Extension fields to descriptorpb.FileOptions.
@@ -594,6 +909,12 @@ var (
 	//
 	// optional bool file_skip = 90102;
 	E_FileSkip = &file_redact_v3_redact_proto_extTypes[0]
+	// FileDefaultString replaces the default redaction value of the string
+	// fields of the file, e.g. "[MASKED]", for the fields without a value of
+	// their own, e.g. of the all_fields messages. It must not be empty.
+	//
+	// optional string file_default_string = 90103;
+	E_FileDefaultString = &file_redact_v3_redact_proto_extTypes[1]
 )
 
 // Extension fields to descriptorpb.ServiceOptions.
@@ -601,21 +922,23 @@ var (
 	// ServiceSkip is used to skip the redaction in grpc service in the server
 	//
 	// optional bool service_skip = 54123;
-	E_ServiceSkip = &file_redact_v3_redact_proto_extTypes[1]
+	E_ServiceSkip = &file_redact_v3_redact_proto_extTypes[2]
 	// InternalService will make this service private and client will not be
 	// able to receive any response for any of it's method, (unless skipped
 	// explicitly) and will get PermissionDenied(7) error by default, to set
 	// any other code set it in InternalServiceCode, it should be one of the
 	// defined GRPC status code, and InternalServiceErrMessage for error
 	// message, in which, one can use `%service%` or `%method%` tags to include
-	// corresponding service name or method name, respectively.
+	// corresponding service name or method name, respectively, `%package%` for
+	// the proto package and `%fqn%` for the fully qualified method name. Other
+	// tags are kept as is. An empty message falls back to the default one.
 	//
 	// optional bool internal_service = 54124;
-	E_InternalService = &file_redact_v3_redact_proto_extTypes[2]
+	E_InternalService = &file_redact_v3_redact_proto_extTypes[3]
 	// optional uint32 internal_service_code = 54125;
-	E_InternalServiceCode = &file_redact_v3_redact_proto_extTypes[3]
+	E_InternalServiceCode = &file_redact_v3_redact_proto_extTypes[4]
 	// optional string internal_service_err_message = 54126;
-	E_InternalServiceErrMessage = &file_redact_v3_redact_proto_extTypes[4]
+	E_InternalServiceErrMessage = &file_redact_v3_redact_proto_extTypes[5]
 )
 
 // Extension fields to descriptorpb.MethodOptions.
@@ -623,7 +946,7 @@ var (
 	// MethodSkip is used to skip the redactions for this method in the grpc server
 	//
 	// optional bool method_skip = 54123;
-	E_MethodSkip = &file_redact_v3_redact_proto_extTypes[5]
+	E_MethodSkip = &file_redact_v3_redact_proto_extTypes[6]
 	// InternalMethod, InternalMethodCode and InternalMethodErrMessage works same
 	// as that of service level options: InternalService, InternalServiceCode and
 	// InternalServiceErrMessage, but at Method level. All the validations and
@@ -631,11 +954,17 @@ var (
 	// whenever both are specified.
 	//
 	// optional bool internal_method = 54124;
-	E_InternalMethod = &file_redact_v3_redact_proto_extTypes[6]
+	E_InternalMethod = &file_redact_v3_redact_proto_extTypes[7]
 	// optional uint32 internal_method_code = 54125;
-	E_InternalMethodCode = &file_redact_v3_redact_proto_extTypes[7]
+	E_InternalMethodCode = &file_redact_v3_redact_proto_extTypes[8]
 	// optional string internal_method_err_message = 54126;
-	E_InternalMethodErrMessage = &file_redact_v3_redact_proto_extTypes[8]
+	E_InternalMethodErrMessage = &file_redact_v3_redact_proto_extTypes[9]
+	// RedactInput redacts the request in the grpc server before forwarding it to
+	// the method handler, e.g. when the handler logs the requests. It is only
+	// supported by the unary and server streaming methods.
+	//
+	// optional bool redact_input = 54127;
+	E_RedactInput = &file_redact_v3_redact_proto_extTypes[10]
 )
 
 // Extension fields to descriptorpb.MessageOptions.
@@ -643,15 +972,55 @@ var (
 	// Nil will redact message to nil (can be override by field level, `empty` option)
 	//
 	// optional bool nil = 54123;
-	E_Nil = &file_redact_v3_redact_proto_extTypes[9]
+	E_Nil = &file_redact_v3_redact_proto_extTypes[11]
 	// Empty will redact message to it's empty object
 	//
 	// optional bool empty = 54124;
-	E_Empty = &file_redact_v3_redact_proto_extTypes[10]
+	E_Empty = &file_redact_v3_redact_proto_extTypes[12]
 	// Ignored skips generation of any redaction for this message.
 	//
 	// optional bool ignored = 54125;
-	E_Ignored = &file_redact_v3_redact_proto_extTypes[11]
+	E_Ignored = &file_redact_v3_redact_proto_extTypes[13]
+	// UseCustomRedactor routes all the fields of the message through the
+	// `FieldRedactor` registered with `redact.SetRedactor`, after their field
+	// level rules are applied.
+	//
+	// optional bool use_custom_redactor = 54126;
+	E_UseCustomRedactor = &file_redact_v3_redact_proto_extTypes[14]
+	// AllFields redacts all the fields of the message with the default redaction
+	// values of their types, without per-field rules, e.g. for blanket-sensitive
+	// messages. The rules of the fields override the defaults, and the fields
+	// marked with `allow` are kept.
+	//
+	// optional bool all_fields = 54128;
+	E_AllFields = &file_redact_v3_redact_proto_extTypes[15]
+	// AutoNested redacts the fields embedding the message, without rules of
+	// their own, by its own redaction as with `(redact.v3.value).message = {}`,
+	// or `element.nested` for the repeated and map fields, e.g. for a `PII`
+	// message embedded in many places. The fields marked with `allow` and the
+	// fields of the `all_fields` messages keep their redaction.
+	//
+	// optional bool auto_nested = 54129;
+	E_AutoNested = &file_redact_v3_redact_proto_extTypes[16]
+	// Except redacts all the fields of the message, as `all_fields`, except the
+	// comma-separated fields, by their proto names, e.g. "id,created_at", which
+	// are kept as the fields marked with `allow`.
+	//
+	// optional string except = 54130;
+	E_Except = &file_redact_v3_redact_proto_extTypes[17]
+	// EmptyFactory is the function returning the empty value of the message,
+	// formatted as `<import-path>.<Name>`, e.g. "github.com/acme/config.NewRedactedConfig",
+	// called instead of `&Message{}` by the fields and the servers redacting the
+	// message to empty, e.g. to centralize what a safe empty value looks like.
+	//
+	// optional string empty_factory = 54131;
+	E_EmptyFactory = &file_redact_v3_redact_proto_extTypes[18]
+	// GatedBy redacts the message only when the bool field, given by its proto
+	// name, is true, e.g. a per-record "is_sensitive" flag. The gate field is
+	// kept, and the messages whose gate is false are left intact.
+	//
+	// optional string gated_by = 54132;
+	E_GatedBy = &file_redact_v3_redact_proto_extTypes[19]
 )
 
 // Extension fields to descriptorpb.FieldOptions.
@@ -671,7 +1040,14 @@ var (
 	// And if Custom value is to be assigned, one can skip the Redact field.
 	//
 	// optional redact.v3.FieldRules value = 54123;
-	E_Value = &file_redact_v3_redact_proto_extTypes[12]
+	E_Value = &file_redact_v3_redact_proto_extTypes[20]
+	// Allow explicitly marks the field as safe, it is never redacted and kept as
+	// is, regardless of the message level options. It cannot be combined with a
+	// `value` rule. With the `reset_and_copy` plugin option any field that is
+	// neither allowed nor redacted is dropped on redaction.
+	//
+	// optional bool allow = 54124;
+	E_Allow = &file_redact_v3_redact_proto_extTypes[21]
 )
 
 var File_redact_v3_redact_proto protoreflect.FileDescriptor
@@ -681,7 +1057,7 @@ var file_redact_v3_redact_proto_rawDesc = []byte{
 	0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74,
 	0x2e, 0x76, 0x33, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9c, 0x04, 0x0a, 0x0a, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xab, 0x06, 0x0a, 0x0a, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52,
 	0x75, 0x6c, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x05, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x18, 0x02, 0x20,
 	0x01, 0x28, 0x02, 0x48, 0x00, 0x52, 0x05, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x12, 0x18, 0x0a, 0x06,
 	0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x06,
@@ -714,86 +1090,153 @@ var file_redact_v3_redact_proto_rawDesc = []byte{
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x33, 0x0a, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74,
 	0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e,
 	0x76, 0x33, 0x2e, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x48,
-	0x00, 0x52, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x08, 0x0a, 0x06, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x73, 0x22, 0x60, 0x0a, 0x0c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
-	0x75, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74,
-	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x10,
-	0x0a, 0x03, 0x6e, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x6e, 0x69, 0x6c,
-	0x12, 0x14, 0x0a, 0x05, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x05, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x22, 0x67, 0x0a, 0x0c, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06,
-	0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6e, 0x65,
-	0x73, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e, 0x76, 0x33, 0x2e, 0x46,
-	0x69, 0x65, 0x6c, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x3a,
-	0x3b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1c, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
-	0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xf6, 0xbf, 0x05, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x6b, 0x69, 0x70, 0x3a, 0x44, 0x0a, 0x0c,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1f, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6,
-	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x6b,
-	0x69, 0x70, 0x3a, 0x4c, 0x0a, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x73,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xec, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x3a, 0x55, 0x0a, 0x15, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xed, 0xa6, 0x03, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x13, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x3a, 0x62, 0x0a, 0x1c, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x5f,
-	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x00, 0x52, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x08, 0x70, 0x61,
+	0x6e, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07,
+	0x70, 0x61, 0x6e, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x1d, 0x0a, 0x09, 0x65, 0x6e, 0x75, 0x6d, 0x5f,
+	0x6c, 0x61, 0x73, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x08, 0x65, 0x6e,
+	0x75, 0x6d, 0x4c, 0x61, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x17, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1b,
+	0x0a, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x6f, 0x18, 0x18, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x07, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x6f, 0x12, 0x1d, 0x0a, 0x09, 0x63,
+	0x6f, 0x70, 0x79, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x08, 0x63, 0x6f, 0x70, 0x79, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x1d, 0x0a, 0x09, 0x7a, 0x65,
+	0x72, 0x6f, 0x5f, 0x66, 0x69, 0x6c, 0x6c, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52,
+	0x08, 0x7a, 0x65, 0x72, 0x6f, 0x46, 0x69, 0x6c, 0x6c, 0x12, 0x3e, 0x0a, 0x0d, 0x72, 0x65, 0x67,
+	0x65, 0x78, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e, 0x76, 0x33, 0x2e, 0x52, 0x65, 0x67,
+	0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x72, 0x65, 0x67,
+	0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x09, 0x6d, 0x61, 0x67,
+	0x6e, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09,
+	0x6d, 0x61, 0x67, 0x6e, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x22, 0x4a, 0x0a, 0x0c, 0x52, 0x65, 0x67, 0x65, 0x78, 0x52, 0x65, 0x70, 0x6c,
+	0x61, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x20, 0x0a,
+	0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x22,
+	0x76, 0x0a, 0x0c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73,
+	0x6b, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x69, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x6e, 0x69, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x61,
+	0x70, 0x70, 0x6c, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x70, 0x70, 0x6c,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x22, 0xd3, 0x01, 0x0a, 0x0c, 0x45, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e, 0x76, 0x33,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x04, 0x69, 0x74, 0x65,
+	0x6d, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x5f, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6c, 0x65, 0x61, 0x72,
+	0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e, 0x76,
+	0x33, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x3a, 0x3b, 0x0a,
+	0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xf6, 0xbf, 0x05, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x6b, 0x69, 0x70, 0x3a, 0x4e, 0x0a, 0x13, 0x66, 0x69,
+	0x6c, 0x65, 0x5f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xf7, 0xbf, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x3a, 0x44, 0x0a, 0x0c, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x6b, 0x69, 0x70,
+	0x3a, 0x4c, 0x0a, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xec, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x3a, 0x55,
+	0x0a, 0x15, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xee, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x19, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x45, 0x72, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x3a, 0x41, 0x0a, 0x0b, 0x6d,
-	0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xed, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x13, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x43, 0x6f, 0x64, 0x65, 0x3a, 0x62, 0x0a, 0x1c, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x5f, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xee, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x19,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x45,
+	0x72, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x3a, 0x41, 0x0a, 0x0b, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0a, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x53, 0x6b, 0x69, 0x70, 0x3a, 0x49, 0x0a, 0x0f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12,
+	0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xec, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x3a, 0x52, 0x0a, 0x14, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x12,
+	0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xed, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x3a, 0x5f, 0x0a, 0x1b, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x65,
+	0x72, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74,
-	0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x0a, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x53, 0x6b, 0x69, 0x70, 0x3a, 0x49,
-	0x0a, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f,
-	0x64, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0xec, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x3a, 0x52, 0x0a, 0x14, 0x69, 0x6e, 0x74,
-	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x63, 0x6f, 0x64,
-	0x65, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0xed, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x3a, 0x5f, 0x0a,
-	0x1b, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
-	0x5f, 0x65, 0x72, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d,
-	0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xee, 0xa6, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x18, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x4d, 0x65,
-	0x74, 0x68, 0x6f, 0x64, 0x45, 0x72, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x3a, 0x33,
-	0x0a, 0x03, 0x6e, 0x69, 0x6c, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f,
-	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03,
-	0x6e, 0x69, 0x6c, 0x3a, 0x37, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x1f, 0x2e, 0x67,
+	0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xee, 0xa6, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x18, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x45, 0x72, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x3a, 0x43, 0x0a, 0x0c,
+	0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1e, 0x2e, 0x67,
 	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xec, 0xa6,
-	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x3a, 0x3b, 0x0a, 0x07,
-	0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xed, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x07, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x3a, 0x4c, 0x0a, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x64, 0x61,
-	0x63, 0x74, 0x2e, 0x76, 0x33, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x42, 0x5a, 0x40, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x72, 0x72, 0x61, 0x6b, 0x69, 0x73, 0x2d, 0x64, 0x69,
-	0x67, 0x69, 0x74, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e,
-	0x2d, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2f, 0x76, 0x33, 0x2f, 0x72, 0x65, 0x64, 0x61, 0x63,
-	0x74, 0x2f, 0x76, 0x33, 0x3b, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xef, 0xa6, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x49, 0x6e, 0x70, 0x75,
+	0x74, 0x3a, 0x33, 0x0a, 0x03, 0x6e, 0x69, 0x6c, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb, 0xa6, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x03, 0x6e, 0x69, 0x6c, 0x3a, 0x37, 0x0a, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x12,
+	0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0xec, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x3a,
+	0x3b, 0x0a, 0x07, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xed, 0xa6, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x3a, 0x51, 0x0a, 0x13,
+	0x75, 0x73, 0x65, 0x5f, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x72, 0x65, 0x64, 0x61, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0xee, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x75, 0x73,
+	0x65, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x52, 0x65, 0x64, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x3a,
+	0x40, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x1f, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xf0,
+	0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x73, 0x3a, 0x42, 0x0a, 0x0b, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64,
+	0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0xf1, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x61, 0x75, 0x74, 0x6f, 0x4e,
+	0x65, 0x73, 0x74, 0x65, 0x64, 0x3a, 0x39, 0x0a, 0x06, 0x65, 0x78, 0x63, 0x65, 0x70, 0x74, 0x12,
+	0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0xf2, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x78, 0x63, 0x65, 0x70, 0x74,
+	0x3a, 0x46, 0x0a, 0x0d, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0xf3, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x6d, 0x70, 0x74,
+	0x79, 0x46, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x3a, 0x3c, 0x0a, 0x08, 0x67, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x62, 0x79, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xf4, 0xa6, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67,
+	0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x3a, 0x4c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xeb,
+	0xa6, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2e,
+	0x76, 0x33, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x35, 0x0a, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x1d, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xec, 0xa6, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x42, 0x3a, 0x5a, 0x38, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x32,
+	0x6b, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x72, 0x65, 0x64,
+	0x61, 0x63, 0x74, 0x2f, 0x76, 0x33, 0x2f, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x2f, 0x76, 0x33,
+	0x3b, 0x72, 0x65, 0x64, 0x61, 0x63, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -808,40 +1251,52 @@ func file_redact_v3_redact_proto_rawDescGZIP() []byte {
 	return file_redact_v3_redact_proto_rawDescData
 }
 
-var file_redact_v3_redact_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_redact_v3_redact_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_redact_v3_redact_proto_goTypes = []interface{}{
 	(*FieldRules)(nil),                  // 0: redact.v3.FieldRules
-	(*MessageRules)(nil),                // 1: redact.v3.MessageRules
-	(*ElementRules)(nil),                // 2: redact.v3.ElementRules
-	(*descriptorpb.FileOptions)(nil),    // 3: google.protobuf.FileOptions
-	(*descriptorpb.ServiceOptions)(nil), // 4: google.protobuf.ServiceOptions
-	(*descriptorpb.MethodOptions)(nil),  // 5: google.protobuf.MethodOptions
-	(*descriptorpb.MessageOptions)(nil), // 6: google.protobuf.MessageOptions
-	(*descriptorpb.FieldOptions)(nil),   // 7: google.protobuf.FieldOptions
+	(*RegexReplace)(nil),                // 1: redact.v3.RegexReplace
+	(*MessageRules)(nil),                // 2: redact.v3.MessageRules
+	(*ElementRules)(nil),                // 3: redact.v3.ElementRules
+	(*descriptorpb.FileOptions)(nil),    // 4: google.protobuf.FileOptions
+	(*descriptorpb.ServiceOptions)(nil), // 5: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 6: google.protobuf.MethodOptions
+	(*descriptorpb.MessageOptions)(nil), // 7: google.protobuf.MessageOptions
+	(*descriptorpb.FieldOptions)(nil),   // 8: google.protobuf.FieldOptions
 }
 var file_redact_v3_redact_proto_depIdxs = []int32{
-	1,  // 0: redact.v3.FieldRules.message:type_name -> redact.v3.MessageRules
-	2,  // 1: redact.v3.FieldRules.element:type_name -> redact.v3.ElementRules
-	0,  // 2: redact.v3.ElementRules.item:type_name -> redact.v3.FieldRules
-	3,  // 3: redact.v3.file_skip:extendee -> google.protobuf.FileOptions
-	4,  // 4: redact.v3.service_skip:extendee -> google.protobuf.ServiceOptions
-	4,  // 5: redact.v3.internal_service:extendee -> google.protobuf.ServiceOptions
-	4,  // 6: redact.v3.internal_service_code:extendee -> google.protobuf.ServiceOptions
-	4,  // 7: redact.v3.internal_service_err_message:extendee -> google.protobuf.ServiceOptions
-	5,  // 8: redact.v3.method_skip:extendee -> google.protobuf.MethodOptions
-	5,  // 9: redact.v3.internal_method:extendee -> google.protobuf.MethodOptions
-	5,  // 10: redact.v3.internal_method_code:extendee -> google.protobuf.MethodOptions
-	5,  // 11: redact.v3.internal_method_err_message:extendee -> google.protobuf.MethodOptions
-	6,  // 12: redact.v3.nil:extendee -> google.protobuf.MessageOptions
-	6,  // 13: redact.v3.empty:extendee -> google.protobuf.MessageOptions
-	6,  // 14: redact.v3.ignored:extendee -> google.protobuf.MessageOptions
-	7,  // 15: redact.v3.value:extendee -> google.protobuf.FieldOptions
-	0,  // 16: redact.v3.value:type_name -> redact.v3.FieldRules
-	17, // [17:17] is the sub-list for method output_type
-	17, // [17:17] is the sub-list for method input_type
-	16, // [16:17] is the sub-list for extension type_name
-	3,  // [3:16] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	2,  // 0: redact.v3.FieldRules.message:type_name -> redact.v3.MessageRules
+	3,  // 1: redact.v3.FieldRules.element:type_name -> redact.v3.ElementRules
+	1,  // 2: redact.v3.FieldRules.regex_replace:type_name -> redact.v3.RegexReplace
+	0,  // 3: redact.v3.ElementRules.item:type_name -> redact.v3.FieldRules
+	0,  // 4: redact.v3.ElementRules.key:type_name -> redact.v3.FieldRules
+	4,  // 5: redact.v3.file_skip:extendee -> google.protobuf.FileOptions
+	4,  // 6: redact.v3.file_default_string:extendee -> google.protobuf.FileOptions
+	5,  // 7: redact.v3.service_skip:extendee -> google.protobuf.ServiceOptions
+	5,  // 8: redact.v3.internal_service:extendee -> google.protobuf.ServiceOptions
+	5,  // 9: redact.v3.internal_service_code:extendee -> google.protobuf.ServiceOptions
+	5,  // 10: redact.v3.internal_service_err_message:extendee -> google.protobuf.ServiceOptions
+	6,  // 11: redact.v3.method_skip:extendee -> google.protobuf.MethodOptions
+	6,  // 12: redact.v3.internal_method:extendee -> google.protobuf.MethodOptions
+	6,  // 13: redact.v3.internal_method_code:extendee -> google.protobuf.MethodOptions
+	6,  // 14: redact.v3.internal_method_err_message:extendee -> google.protobuf.MethodOptions
+	6,  // 15: redact.v3.redact_input:extendee -> google.protobuf.MethodOptions
+	7,  // 16: redact.v3.nil:extendee -> google.protobuf.MessageOptions
+	7,  // 17: redact.v3.empty:extendee -> google.protobuf.MessageOptions
+	7,  // 18: redact.v3.ignored:extendee -> google.protobuf.MessageOptions
+	7,  // 19: redact.v3.use_custom_redactor:extendee -> google.protobuf.MessageOptions
+	7,  // 20: redact.v3.all_fields:extendee -> google.protobuf.MessageOptions
+	7,  // 21: redact.v3.auto_nested:extendee -> google.protobuf.MessageOptions
+	7,  // 22: redact.v3.except:extendee -> google.protobuf.MessageOptions
+	7,  // 23: redact.v3.empty_factory:extendee -> google.protobuf.MessageOptions
+	7,  // 24: redact.v3.gated_by:extendee -> google.protobuf.MessageOptions
+	8,  // 25: redact.v3.value:extendee -> google.protobuf.FieldOptions
+	8,  // 26: redact.v3.allow:extendee -> google.protobuf.FieldOptions
+	0,  // 27: redact.v3.value:type_name -> redact.v3.FieldRules
+	28, // [28:28] is the sub-list for method output_type
+	28, // [28:28] is the sub-list for method input_type
+	27, // [27:28] is the sub-list for extension type_name
+	5,  // [5:27] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_redact_v3_redact_proto_init() }
@@ -863,7 +1318,7 @@ func file_redact_v3_redact_proto_init() {
 			}
 		}
 		file_redact_v3_redact_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MessageRules); i {
+			switch v := v.(*RegexReplace); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -875,6 +1330,18 @@ func file_redact_v3_redact_proto_init() {
 			}
 		}
 		file_redact_v3_redact_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MessageRules); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redact_v3_redact_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ElementRules); i {
 			case 0:
 				return &v.state
@@ -906,6 +1373,14 @@ func file_redact_v3_redact_proto_init() {
 		(*FieldRules_Enum)(nil),
 		(*FieldRules_Message)(nil),
 		(*FieldRules_Element)(nil),
+		(*FieldRules_PanMask)(nil),
+		(*FieldRules_EnumLast)(nil),
+		(*FieldRules_Round)(nil),
+		(*FieldRules_RoundTo)(nil),
+		(*FieldRules_CopyFrom)(nil),
+		(*FieldRules_ZeroFill)(nil),
+		(*FieldRules_RegexReplace)(nil),
+		(*FieldRules_Magnitude)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -913,8 +1388,8 @@ func file_redact_v3_redact_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_redact_v3_redact_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
-			NumExtensions: 13,
+			NumMessages:   4,
+			NumExtensions: 22,
 			NumServices:   0,
 		},
 		GoTypes:           file_redact_v3_redact_proto_goTypes,
@@ -0,0 +1,81 @@
+// Package redactgrpc provides the gRPC client interceptors redacting the
+// replies, kept out of the redact package so that the generated files without
+// services do not depend on grpc.
+package redactgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// RedactFunc redacts a reply in place, e.g. redact.Apply
+type RedactFunc func(reply interface{}) error
+
+// UnaryClientInterceptor returns a client interceptor redacting the replies of
+// the successful unary calls, implementing redact.Redactor or
+// redact.FallibleRedactor, e.g. for clients logging the replies without
+// trusting the server. The calls of the skipped full method names, e.g.
+// "/pkg.Service/Method", are not redacted.
+//
+// The replies are redacted in place, the callers needing the original values
+// must clone them before the call returns, e.g. with another interceptor.
+func UnaryClientInterceptor(skip ...string) grpc.UnaryClientInterceptor {
+	return unaryClientInterceptor(redact.Apply, skip)
+}
+
+// unaryClientInterceptor returns UnaryClientInterceptor, redacting the replies
+// with the redactReply function
+func unaryClientInterceptor(redactReply RedactFunc, skip []string) grpc.UnaryClientInterceptor {
+	skipped := make(map[string]bool, len(skip))
+	for _, method := range skip {
+		skipped[method] = true
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		if skipped[method] {
+			return nil
+		}
+		if err := redactReply(reply); err != nil {
+			return status.Errorf(codes.Internal, "redaction failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// RedactedClientConn wraps the client connection, redacting the replies of
+// the unary calls in place as UnaryClientInterceptor, the streams are passed
+// through. It is used by the generated NewRedacted<Service>Client functions.
+func RedactedClientConn(cc grpc.ClientConnInterface, skip ...string) grpc.ClientConnInterface {
+	return &redactedClientConn{ClientConnInterface: cc, interceptor: UnaryClientInterceptor(skip...)}
+}
+
+// RedactedClientConnFunc wraps the client connection as RedactedClientConn,
+// redacting the replies with the redactReply function instead of redact.Apply.
+// It is used by the NewRedacted<Service>Client functions generated with
+// separate_package, the replies having no Redact() method.
+func RedactedClientConnFunc(cc grpc.ClientConnInterface, redactReply RedactFunc, skip ...string) grpc.ClientConnInterface {
+	return &redactedClientConn{ClientConnInterface: cc, interceptor: unaryClientInterceptor(redactReply, skip)}
+}
+
+// redactedClientConn intercepts the unary calls of the wrapped connection
+type redactedClientConn struct {
+	grpc.ClientConnInterface
+	interceptor grpc.UnaryClientInterceptor
+}
+
+// Invoke performs the unary call through the interceptor
+func (c *redactedClientConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	invoker := func(ctx context.Context, method string, req, reply interface{}, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return c.ClientConnInterface.Invoke(ctx, method, req, reply, opts...)
+	}
+	return c.interceptor(ctx, method, args, reply, nil, invoker, opts...)
+}
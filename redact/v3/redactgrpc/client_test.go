@@ -0,0 +1,85 @@
+package redactgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/menta2k/protoc-gen-redact/v3/examples/tests"
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3/redactgrpc"
+)
+
+// replyingConn is a client connection replying with the string value
+type replyingConn struct {
+	grpc.ClientConnInterface
+	err error
+}
+
+func (c *replyingConn) Invoke(_ context.Context, _ string, _, reply interface{}, _ ...grpc.CallOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	reply.(*tests.TestMessage).StringValue = "secret"
+	return nil
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := redactgrpc.UnaryClientInterceptor("/pkg.Service/Skipped")
+	invoker := func(_ context.Context, _ string, _, reply interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		reply.(*tests.TestMessage).StringValue = "secret"
+		return nil
+	}
+
+	reply := &tests.TestMessage{}
+	if err := interceptor(context.Background(), "/pkg.Service/Method", nil, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if reply.StringValue != "redacted-value-value" {
+		t.Errorf("expected the reply to be redacted, got %q", reply.StringValue)
+	}
+
+	reply = &tests.TestMessage{}
+	if err := interceptor(context.Background(), "/pkg.Service/Skipped", nil, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if reply.StringValue != "secret" {
+		t.Errorf("expected the skipped reply not to be redacted, got %q", reply.StringValue)
+	}
+}
+
+func TestRedactedClientConn(t *testing.T) {
+	cc := redactgrpc.RedactedClientConn(&replyingConn{})
+	reply := &tests.TestMessage{}
+	if err := cc.Invoke(context.Background(), "/pkg.Service/Method", nil, reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.StringValue != "redacted-value-value" {
+		t.Errorf("expected the reply to be redacted, got %q", reply.StringValue)
+	}
+
+	// the errors of the calls are returned as is
+	failure := status.Error(codes.NotFound, "not found")
+	cc = redactgrpc.RedactedClientConn(&replyingConn{err: failure})
+	if err := cc.Invoke(context.Background(), "/pkg.Service/Method", nil, &tests.TestMessage{}); err != failure {
+		t.Errorf("expected the call error, got %v", err)
+	}
+}
+
+func TestRedactedClientConnFunc(t *testing.T) {
+	redactReply := func(interface{}) error { return errors.New("boom") }
+	cc := redactgrpc.RedactedClientConnFunc(&replyingConn{}, redactReply)
+	err := cc.Invoke(context.Background(), "/pkg.Service/Method", nil, &tests.TestMessage{})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected an internal error on the failed redaction, got %v", err)
+	}
+
+	// the skipped calls are not redacted
+	cc = redactgrpc.RedactedClientConnFunc(&replyingConn{}, redactReply, "/pkg.Service/Method")
+	if err := cc.Invoke(context.Background(), "/pkg.Service/Method", nil, &tests.TestMessage{}); err != nil {
+		t.Errorf("expected the skipped call to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package redacttest provides test helpers checking the messages are fully
+// redacted. It is kept apart from the redact package, which does not depend on
+// the testing package.
+package redacttest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	redact "github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// AssertRedacted redacts a clone of the message and fails the test if some of
+// its sensitive fields still hold their original value, see Unredacted. It
+// returns the paths of the unredacted fields.
+func AssertRedacted(t testing.TB, msg proto.Message) []string {
+	t.Helper()
+
+	paths, err := Unredacted(msg)
+	if err != nil {
+		t.Errorf("Redaction of %T failed: %v", msg, err)
+		return nil
+	}
+	if len(paths) > 0 {
+		t.Errorf("%T is not fully redacted, unredacted fields: %s", msg, strings.Join(paths, ", "))
+	}
+	return paths
+}
+
+// Unredacted redacts a clone of the message with redact.ApplyReflect, using
+// its generated Redact() method if any, and returns the paths of the sensitive
+// fields still holding their original value, e.g. `profile.email`. The
+// sensitive fields are the fields with redaction rules, and the fields not
+// allowed of the all_fields messages, the unset fields are not reported.
+// Nested messages with rules are checked field by field, the other messages
+// as a whole. A value already equal to its redaction value, e.g. a rounded
+// value, is reported: the tested messages should hold distinctive values.
+func Unredacted(msg proto.Message) ([]string, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	clone := proto.Clone(msg)
+	if err := redact.ApplyReflect(clone); err != nil {
+		return nil, err
+	}
+	return unredacted(msg.ProtoReflect(), clone.ProtoReflect(), "", nil), nil
+}
+
+// unredacted appends the paths of the sensitive fields of the original message
+// which are equal in the redacted one
+func unredacted(orig, red protoreflect.Message, prefix string, paths []string) []string {
+	if !orig.IsValid() || !red.IsValid() {
+		return paths
+	}
+	fields := orig.Descriptor().Fields()
+	// the messages gated by a false field are kept on purpose
+	gatedBy, _ := proto.GetExtension(orig.Descriptor().Options(), redact.E_GatedBy).(string)
+	gate := fields.ByName(protoreflect.Name(gatedBy))
+	if gate != nil && gate.Kind() == protoreflect.BoolKind && !orig.Get(gate).Bool() {
+		return paths
+	}
+	except := exceptFields(orig.Descriptor())
+	allFields := boolOption(orig.Descriptor().Options(), redact.E_AllFields) || except != nil
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		rules, _ := proto.GetExtension(fd.Options(), redact.E_Value).(*redact.FieldRules)
+		sensitive := rules.GetValues() != nil ||
+			(allFields && !boolOption(fd.Options(), redact.E_Allow) && !except[string(fd.Name())] && fd != gate)
+		if !sensitive || rules.GetMessage().GetSkip() || !orig.Has(fd) || !red.Has(fd) {
+			continue
+		}
+
+		path := prefix + string(fd.Name())
+		switch {
+		case fd.Message() != nil && ignored(fd.Message()):
+			// kept on purpose
+		case fd.IsList() && fd.Message() != nil && hasRules(fd.Message()):
+			origItems, redItems := orig.Get(fd).List(), red.Get(fd).List()
+			if origItems.Len() != redItems.Len() {
+				continue
+			}
+			for k := 0; k < origItems.Len(); k++ {
+				paths = unredacted(origItems.Get(k).Message(), redItems.Get(k).Message(), fmt.Sprintf("%s[%d].", path, k), paths)
+			}
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil && hasRules(fd.Message()):
+			paths = unredacted(orig.Get(fd).Message(), red.Get(fd).Message(), path+".", paths)
+		case orig.Get(fd).Equal(red.Get(fd)):
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// hasRules checks if the message has redaction options or fields with
+// redaction rules, the messages without rules are compared as a whole
+func hasRules(desc protoreflect.MessageDescriptor) bool {
+	opts := desc.Options()
+	if boolOption(opts, redact.E_Nil) || boolOption(opts, redact.E_Empty) || boolOption(opts, redact.E_AllFields) ||
+		exceptFields(desc) != nil {
+		return true
+	}
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if rules, _ := proto.GetExtension(fields.Get(i).Options(), redact.E_Value).(*redact.FieldRules); rules.GetValues() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// exceptFields returns the fields kept by the except option of the message, nil
+// without the option
+func exceptFields(desc protoreflect.MessageDescriptor) map[string]bool {
+	except, _ := proto.GetExtension(desc.Options(), redact.E_Except).(string)
+	var set map[string]bool
+	for _, name := range redact.ExceptFields(except) {
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[name] = true
+	}
+	return set
+}
+
+// ignored checks if the message is ignored by the redaction
+func ignored(desc protoreflect.MessageDescriptor) bool {
+	return boolOption(desc.Options(), redact.E_Ignored)
+}
+
+// boolOption reads a boolean option of the descriptor options
+func boolOption(opts proto.Message, ext protoreflect.ExtensionType) bool {
+	val, _ := proto.GetExtension(opts, ext).(bool)
+	return val
+}
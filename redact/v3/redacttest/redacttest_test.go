@@ -0,0 +1,133 @@
+package redacttest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	redact "github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// dynamicAccount builds the descriptor of an annotated message at runtime, the
+// rules of its mismatch fields do not match their type and are ignored
+func dynamicAccount(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, rules *redact.FieldRules) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if rules != nil {
+			fd.Options = &descriptorpb.FieldOptions{}
+			proto.SetExtension(fd.Options, redact.E_Value, rules)
+		}
+		return fd
+	}
+	mismatch := &redact.FieldRules{Values: &redact.FieldRules_Int32{Int32: -1}}
+	profile := field("profile", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		&redact.FieldRules{Values: &redact.FieldRules_Message{Message: &redact.MessageRules{}}})
+	profile.TypeName = proto.String(".redacttest.Profile")
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("redacttest/account.proto"),
+		Package:    proto.String("redacttest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Account"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("username", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+				field("password", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&redact.FieldRules{Values: &redact.FieldRules_String_{String_: "hidden"}}),
+				profile,
+				field("mismatch", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64, mismatch),
+			},
+		}, {
+			Name: proto.String("Profile"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("email", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&redact.FieldRules{Values: &redact.FieldRules_String_{String_: "r*d@ct*d"}}),
+				field("phone", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, mismatch),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	return fd.Messages().ByName("Account")
+}
+
+// newAccount returns an account with all its fields set
+func newAccount(t *testing.T) *dynamicpb.Message {
+	desc := dynamicAccount(t)
+	fields := desc.Fields()
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(fields.ByName("username"), protoreflect.ValueOfString("john"))
+	msg.Set(fields.ByName("password"), protoreflect.ValueOfString("secret"))
+	msg.Set(fields.ByName("mismatch"), protoreflect.ValueOfInt64(42))
+	profile := msg.Mutable(fields.ByName("profile")).Message()
+	profile.Set(profile.Descriptor().Fields().ByName("email"), protoreflect.ValueOfString("john@example.com"))
+	profile.Set(profile.Descriptor().Fields().ByName("phone"), protoreflect.ValueOfInt64(5550100))
+	return msg
+}
+
+func TestUnredacted(t *testing.T) {
+	msg := newAccount(t)
+	paths, err := Unredacted(msg)
+	if err != nil {
+		t.Fatalf("Should redact the message: %v", err)
+	}
+	if want := []string{"profile.phone", "mismatch"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Unredacted fields should be %v, got %v", want, paths)
+	}
+	if got := msg.Get(msg.Descriptor().Fields().ByName("password")).String(); got != "secret" {
+		t.Errorf("The message should not be modified, got %q", got)
+	}
+
+	desc := msg.Descriptor()
+	msg.Clear(desc.Fields().ByName("mismatch"))
+	profile := msg.Mutable(desc.Fields().ByName("profile")).Message()
+	profile.Clear(profile.Descriptor().Fields().ByName("phone"))
+	if paths, _ := Unredacted(msg); len(paths) != 0 {
+		t.Errorf("Unset fields should not be reported, got %v", paths)
+	}
+	if paths, err := Unredacted(nil); paths != nil || err != nil {
+		t.Errorf("Nil messages should be ignored, got %v, %v", paths, err)
+	}
+}
+
+// recorder records the errors of AssertRedacted
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRedacted(t *testing.T) {
+	rec := &recorder{TB: t}
+	paths := AssertRedacted(rec, newAccount(t))
+
+	if len(paths) != 2 || len(rec.errors) != 1 {
+		t.Fatalf("Should report the unredacted fields, got %v and %v", paths, rec.errors)
+	}
+	if want := "*dynamicpb.Message is not fully redacted, unredacted fields: profile.phone, mismatch"; rec.errors[0] != want {
+		t.Errorf("Error should be %q, got %q", want, rec.errors[0])
+	}
+}
@@ -0,0 +1,528 @@
+package redact
+
+import (
+	"regexp"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// defaultString is the default redaction value of the string fields
+const defaultString = "REDACTED"
+
+// RedactReflect redacts the message in place using reflection, following the
+// redaction rules of its descriptor. It covers the messages without generated
+// Redact() methods, e.g. dynamicpb messages built from descriptors at runtime.
+//   - `ignored` messages are kept, `nil` and `empty` messages are cleared
+//   - fields of a oneof are only redacted when set
+//   - lists and maps cannot hold nil messages, the items redacted to nil are
+//     replaced by empty messages instead
+//   - well-known types are redacted by value, as by the generated code
+func RedactReflect(msg proto.Message) {
+	if msg == nil {
+		return
+	}
+	redactReflect(msg.ProtoReflect())
+}
+
+// ApplyReflect redacts the message as Apply with its generated Redact()
+// method, returning the error of FallibleRedactor, or by reflection with
+// RedactReflect when its type was not generated by the plugin
+func ApplyReflect(msg proto.Message) error {
+	switch msg.(type) {
+	case Redactor, FallibleRedactor:
+		return Apply(msg)
+	}
+	RedactReflect(msg)
+	return nil
+}
+
+// redactReflect redacts the message following the rules of its descriptor
+func redactReflect(msg protoreflect.Message) {
+	if !msg.IsValid() || redactWellKnown(msg) {
+		return
+	}
+	opts := msg.Descriptor().Options()
+	if boolOption(opts, E_Ignored) {
+		return
+	}
+	if boolOption(opts, E_Nil) || boolOption(opts, E_Empty) {
+		clearMessage(msg)
+		return
+	}
+	gate := gateField(msg.Descriptor())
+	if gate != nil && !msg.Get(gate).Bool() {
+		return
+	}
+
+	except := exceptFields(msg.Descriptor())
+	allFields := boolOption(opts, E_AllFields) || except != nil
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		rules, ok := proto.GetExtension(fd.Options(), E_Value).(*FieldRules)
+		if !ok || rules.GetValues() == nil {
+			if boolOption(fd.Options(), E_Allow) || except[fd.Name()] || fd == gate || skipOneof(msg, fd) {
+				continue
+			}
+			switch {
+			case allFields:
+				redactDefault(msg, fd)
+			case autoNested(fd):
+				redactItems(msg, fd, &ElementRules{Nested: true})
+				if !fd.IsList() && !fd.IsMap() && msg.Has(fd) {
+					redactReflect(msg.Mutable(fd).Message())
+				}
+			}
+			continue
+		}
+		if skipOneof(msg, fd) {
+			continue
+		}
+		redactField(msg, fd, rules)
+	}
+}
+
+// exceptFields returns the fields kept by the except option of the message, nil
+// without the option
+func exceptFields(desc protoreflect.MessageDescriptor) map[protoreflect.Name]bool {
+	except, _ := proto.GetExtension(desc.Options(), E_Except).(string)
+	names := ExceptFields(except)
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[protoreflect.Name]bool, len(names))
+	for _, name := range names {
+		set[protoreflect.Name(name)] = true
+	}
+	return set
+}
+
+// gateField returns the bool field gating the redaction of the message with
+// gated_by, nil without the option or if it is not a singular bool field
+func gateField(desc protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	gatedBy, _ := proto.GetExtension(desc.Options(), E_GatedBy).(string)
+	if gatedBy == "" {
+		return nil
+	}
+	fd := desc.Fields().ByName(protoreflect.Name(gatedBy))
+	if fd == nil || fd.Kind() != protoreflect.BoolKind || fd.IsList() {
+		return nil
+	}
+	return fd
+}
+
+// autoNested checks if the field embeds an auto_nested message, redacted by
+// its own rules
+func autoNested(fd protoreflect.FieldDescriptor) bool {
+	item := fd
+	if fd.IsMap() {
+		item = fd.MapValue()
+	}
+	return item.Message() != nil && boolOption(item.Message().Options(), E_AutoNested)
+}
+
+// skipOneof checks if the field is an unset option of a oneof, which is not
+// redacted
+func skipOneof(msg protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	oneof := fd.ContainingOneof()
+	return oneof != nil && !oneof.IsSynthetic() && !msg.Has(fd)
+}
+
+// redactDefault redacts the field without rules of an all_fields message with
+// the default of its type: the lists and maps are cleared and the messages are
+// redacted recursively
+func redactDefault(msg protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsList() || fd.IsMap():
+		msg.Clear(fd)
+	case fd.Message() != nil:
+		if msg.Has(fd) {
+			redactReflect(msg.Mutable(fd).Message())
+		}
+	default:
+		msg.Set(fd, defaultValue(fd))
+	}
+}
+
+// redactField redacts the field of the message following its rules, the
+// rules not matching the type of the field are ignored
+func redactField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, rules *FieldRules) {
+	switch rule := rules.GetValues().(type) {
+	case *FieldRules_Message:
+		if fd.IsList() || fd.IsMap() || fd.Message() == nil {
+			return
+		}
+		switch {
+		case rule.Message.GetSkip():
+		case rule.Message.GetEmpty():
+			msg.Set(fd, msg.NewField(fd))
+		case rule.Message.GetNil():
+			msg.Clear(fd)
+		case msg.Has(fd):
+			redactReflect(msg.Mutable(fd).Message())
+		}
+	case *FieldRules_Element:
+		redactItems(msg, fd, rule.Element)
+	case *FieldRules_CopyFrom:
+		copyField(msg, fd, msg.Descriptor().Fields().ByName(protoreflect.Name(rule.CopyFrom)))
+	default:
+		if fd.IsList() || fd.IsMap() {
+			return
+		}
+		if val, ok := ruleValue(fd, rules, msg.Get(fd)); ok {
+			msg.Set(fd, val)
+		}
+	}
+}
+
+// copyField sets the field to the value of its sibling, the siblings which are
+// not singular scalars of the same kind are ignored
+func copyField(msg protoreflect.Message, fd, src protoreflect.FieldDescriptor) {
+	if src == nil || src == fd || src.Kind() != fd.Kind() || src.Message() != nil || fd.Message() != nil ||
+		src.Cardinality() == protoreflect.Repeated || fd.Cardinality() == protoreflect.Repeated {
+		return
+	}
+	if src.Enum() != nil && src.Enum().FullName() != fd.Enum().FullName() {
+		return
+	}
+	if !msg.Has(src) {
+		msg.Clear(fd)
+		return
+	}
+	msg.Set(fd, msg.Get(src))
+}
+
+// redactItems redacts the items of the list or map field following the rules
+func redactItems(msg protoreflect.Message, fd protoreflect.FieldDescriptor, rule *ElementRules) {
+	if !fd.IsList() && !fd.IsMap() {
+		return
+	}
+	item := fd
+	if fd.IsMap() {
+		item = fd.MapValue()
+		if key := rule.GetKey(); key != nil && fd.MapKey().Kind() == protoreflect.StringKind {
+			redactKeys(msg, fd, key)
+		}
+	}
+	switch {
+	case rule.GetEmpty():
+		msg.Clear(fd)
+	case rule.GetCollapse():
+		collapseItems(msg, fd, rule.GetItem())
+	case rule.GetClearElements():
+		updateItems(msg, fd, func(val protoreflect.Value, newItem func() protoreflect.Value) protoreflect.Value {
+			if item.Message() != nil {
+				return newItem()
+			}
+			return item.Default()
+		})
+	case rule.GetNested():
+		updateItems(msg, fd, func(val protoreflect.Value, newItem func() protoreflect.Value) protoreflect.Value {
+			if item.Message() != nil {
+				redactReflect(val.Message())
+				return val
+			}
+			return defaultValue(item)
+		})
+	case rule.GetItem().GetValues() != nil:
+		updateItems(msg, fd, func(val protoreflect.Value, newItem func() protoreflect.Value) protoreflect.Value {
+			rules := rule.GetItem()
+			if item.Message() == nil {
+				if redacted, ok := ruleValue(item, rules, val); ok {
+					return redacted
+				}
+				return val
+			}
+			switch {
+			case rules.GetMessage() == nil, rules.GetMessage().GetSkip():
+				return val
+			case rules.GetMessage().GetEmpty(), rules.GetMessage().GetNil():
+				return newItem()
+			}
+			redactReflect(val.Message())
+			return val
+		})
+	}
+}
+
+// collapseItems replaces the non-empty list of scalars by a list of a single
+// sentinel item, the value of the item rules or the redaction default
+func collapseItems(msg protoreflect.Message, fd protoreflect.FieldDescriptor, rules *FieldRules) {
+	if !fd.IsList() || fd.Message() != nil || !msg.Has(fd) {
+		return
+	}
+	items := msg.Mutable(fd).List()
+	sentinel, ok := ruleValue(fd, rules, items.NewElement())
+	switch {
+	case ok:
+	case fd.Kind() == protoreflect.StringKind:
+		sentinel = protoreflect.ValueOfString(stringDefault(fd))
+	default:
+		sentinel = items.NewElement()
+	}
+	items.Truncate(0)
+	items.Append(sentinel)
+}
+
+// redactKeys rebuilds the map field with its string keys redacted by the rules,
+// the entries whose keys are redacted to the same value are merged
+func redactKeys(msg protoreflect.Message, fd protoreflect.FieldDescriptor, rules *FieldRules) {
+	if !msg.Has(fd) {
+		return
+	}
+	items := msg.Mutable(fd).Map()
+	keys := make([]protoreflect.MapKey, 0, items.Len())
+	vals := make([]protoreflect.Value, 0, items.Len())
+	items.Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+		keys = append(keys, key)
+		vals = append(vals, val)
+		return true
+	})
+	for _, key := range keys {
+		items.Clear(key)
+	}
+	for i, key := range keys {
+		if redacted, ok := ruleValue(fd.MapKey(), rules, key.Value()); ok {
+			key = redacted.MapKey()
+		}
+		items.Set(key, vals[i])
+	}
+}
+
+// updateItems replaces each item of the list or map field by the updated one,
+// newItem returns an empty message for the message items
+func updateItems(
+	msg protoreflect.Message,
+	fd protoreflect.FieldDescriptor,
+	update func(val protoreflect.Value, newItem func() protoreflect.Value) protoreflect.Value,
+) {
+	if !msg.Has(fd) {
+		return
+	}
+	if fd.IsMap() {
+		items := msg.Mutable(fd).Map()
+		keys := make([]protoreflect.MapKey, 0, items.Len())
+		items.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, key)
+			return true
+		})
+		for _, key := range keys {
+			items.Set(key, update(items.Get(key), items.NewValue))
+		}
+		return
+	}
+	items := msg.Mutable(fd).List()
+	for i := 0; i < items.Len(); i++ {
+		items.Set(i, update(items.Get(i), items.NewElement))
+	}
+}
+
+// regexps caches the compiled patterns of the regex_replace rules, by pattern,
+// as the package-level vars of the generated code, the invalid patterns are
+// cached as nil
+var regexps sync.Map
+
+// compiledRegexp returns the compiled pattern, compiling it on first use, or
+// nil if it is invalid
+func compiledRegexp(pattern string) *regexp.Regexp {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	regexps.Store(pattern, re)
+	return re
+}
+
+// ruleValue returns the redaction value of the scalar rule, it returns false
+// if the rule does not match the kind of the field
+func ruleValue(fd protoreflect.FieldDescriptor, rules *FieldRules, cur protoreflect.Value) (protoreflect.Value, bool) {
+	kind := fd.Kind()
+	switch rule := rules.GetValues().(type) {
+	case *FieldRules_Float:
+		return protoreflect.ValueOfFloat32(rule.Float), kind == protoreflect.FloatKind
+	case *FieldRules_Double:
+		return protoreflect.ValueOfFloat64(rule.Double), kind == protoreflect.DoubleKind
+	case *FieldRules_Int32:
+		return protoreflect.ValueOfInt32(rule.Int32), kind == protoreflect.Int32Kind
+	case *FieldRules_Int64:
+		return protoreflect.ValueOfInt64(rule.Int64), kind == protoreflect.Int64Kind
+	case *FieldRules_Uint32:
+		return protoreflect.ValueOfUint32(rule.Uint32), kind == protoreflect.Uint32Kind
+	case *FieldRules_Uint64:
+		return protoreflect.ValueOfUint64(rule.Uint64), kind == protoreflect.Uint64Kind
+	case *FieldRules_Sint32:
+		return protoreflect.ValueOfInt32(rule.Sint32), kind == protoreflect.Sint32Kind
+	case *FieldRules_Sint64:
+		return protoreflect.ValueOfInt64(rule.Sint64), kind == protoreflect.Sint64Kind
+	case *FieldRules_Fixed32:
+		return protoreflect.ValueOfUint32(rule.Fixed32), kind == protoreflect.Fixed32Kind
+	case *FieldRules_Fixed64:
+		return protoreflect.ValueOfUint64(rule.Fixed64), kind == protoreflect.Fixed64Kind
+	case *FieldRules_Sfixed32:
+		return protoreflect.ValueOfInt32(rule.Sfixed32), kind == protoreflect.Sfixed32Kind
+	case *FieldRules_Sfixed64:
+		return protoreflect.ValueOfInt64(rule.Sfixed64), kind == protoreflect.Sfixed64Kind
+	case *FieldRules_Bool:
+		return protoreflect.ValueOfBool(rule.Bool), kind == protoreflect.BoolKind
+	case *FieldRules_String_:
+		return protoreflect.ValueOfString(rule.String_), kind == protoreflect.StringKind
+	case *FieldRules_Bytes:
+		return protoreflect.ValueOfBytes(rule.Bytes), kind == protoreflect.BytesKind
+	case *FieldRules_Enum:
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(rule.Enum)), kind == protoreflect.EnumKind
+	case *FieldRules_PanMask:
+		if kind != protoreflect.StringKind || !rule.PanMask {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfString(MaskPAN(cur.String())), true
+	case *FieldRules_RegexReplace:
+		if kind != protoreflect.StringKind {
+			return protoreflect.Value{}, false
+		}
+		re := compiledRegexp(rule.RegexReplace.GetPattern())
+		if re == nil {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfString(re.ReplaceAllString(cur.String(), rule.RegexReplace.GetReplacement())), true
+	case *FieldRules_Round:
+		return roundValue(kind, rule.Round, cur)
+	case *FieldRules_Magnitude:
+		if !rule.Magnitude {
+			return protoreflect.Value{}, false
+		}
+		return magnitudeValue(kind, cur)
+	case *FieldRules_RoundTo:
+		switch {
+		case rule.RoundTo == 0:
+		case kind == protoreflect.FloatKind:
+			return protoreflect.ValueOfFloat32(RoundTo(float32(cur.Float()), float32(rule.RoundTo))), true
+		case kind == protoreflect.DoubleKind:
+			return protoreflect.ValueOfFloat64(RoundTo(cur.Float(), rule.RoundTo)), true
+		}
+		return protoreflect.Value{}, false
+	case *FieldRules_ZeroFill:
+		if kind != protoreflect.BytesKind || !rule.ZeroFill || cur.Bytes() == nil {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfBytes(make([]byte, len(cur.Bytes()))), true
+	case *FieldRules_EnumLast:
+		if kind != protoreflect.EnumKind || !rule.EnumLast || fd.Enum().Values().Len() == 0 {
+			return protoreflect.Value{}, false
+		}
+		values := fd.Enum().Values()
+		last := values.Get(0).Number()
+		for i := 1; i < values.Len(); i++ {
+			if num := values.Get(i).Number(); num > last {
+				last = num
+			}
+		}
+		return protoreflect.ValueOfEnum(last), true
+	}
+	return protoreflect.Value{}, false
+}
+
+// roundValue truncates the integer value toward zero to a multiple of the
+// factor, it returns false if the field is not an integer or the factor does
+// not fit its type
+func roundValue(kind protoreflect.Kind, factor int64, cur protoreflect.Value) (protoreflect.Value, bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if f := int32(factor); factor != 0 && int64(f) == factor {
+			return protoreflect.ValueOfInt32(int32(cur.Int()) / f * f), true
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if factor != 0 {
+			return protoreflect.ValueOfInt64(cur.Int() / factor * factor), true
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if f := uint32(factor); factor > 0 && int64(f) == factor {
+			return protoreflect.ValueOfUint32(uint32(cur.Uint()) / f * f), true
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if factor > 0 {
+			return protoreflect.ValueOfUint64(cur.Uint() / uint64(factor) * uint64(factor)), true
+		}
+	}
+	return protoreflect.Value{}, false
+}
+
+// magnitudeValue redacts the integer value to its order of magnitude
+func magnitudeValue(kind protoreflect.Kind, cur protoreflect.Value) (protoreflect.Value, bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(Magnitude(int32(cur.Int()))), true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(Magnitude(cur.Int())), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(Magnitude(uint32(cur.Uint()))), true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(Magnitude(cur.Uint())), true
+	}
+	return protoreflect.Value{}, false
+}
+
+// defaultValue returns the default redaction value of the scalar field
+func defaultValue(fd protoreflect.FieldDescriptor) protoreflect.Value {
+	if fd.Kind() == protoreflect.StringKind {
+		return protoreflect.ValueOfString(stringDefault(fd))
+	}
+	if fd.Kind() == protoreflect.BytesKind {
+		return protoreflect.ValueOfBytes(nil)
+	}
+	return fd.Default()
+}
+
+// stringDefault returns the redaction default of the string field, the
+// file_default_string of its file when set
+func stringDefault(fd protoreflect.FieldDescriptor) string {
+	opts := fd.ParentFile().Options()
+	if opts != nil && proto.HasExtension(opts, E_FileDefaultString) {
+		if str, _ := proto.GetExtension(opts, E_FileDefaultString).(string); str != "" {
+			return str
+		}
+	}
+	return defaultString
+}
+
+// redactWellKnown redacts the well-known types by value, it returns false if
+// the message is not a well-known type
+func redactWellKnown(msg protoreflect.Message) bool {
+	desc := msg.Descriptor()
+	if desc.FullName().Parent() != "google.protobuf" {
+		return false
+	}
+	switch desc.Name() {
+	case "Timestamp", "Duration", "Any", "Struct", "ListValue":
+		// the unix epoch, zero and emptied values
+		clearMessage(msg)
+	case "Value":
+		clearMessage(msg)
+		msg.Set(desc.Fields().ByName("null_value"), protoreflect.ValueOfEnum(0))
+	case "DoubleValue", "FloatValue", "Int64Value", "UInt64Value", "Int32Value",
+		"UInt32Value", "BoolValue", "StringValue", "BytesValue":
+		value := desc.Fields().ByName("value")
+		msg.Set(value, defaultValue(value))
+	default:
+		return false
+	}
+	return true
+}
+
+// clearMessage clears all the populated fields of the message
+func clearMessage(msg protoreflect.Message) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		msg.Clear(fd)
+		return true
+	})
+}
+
+// boolOption reads a boolean option of the descriptor
+func boolOption(opts proto.Message, ext protoreflect.ExtensionType) bool {
+	val, _ := proto.GetExtension(opts, ext).(bool)
+	return val
+}
@@ -0,0 +1,607 @@
+package redact
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// fieldOptions returns the field options holding the redaction rules
+func fieldOptions(rules *FieldRules) *descriptorpb.FieldOptions {
+	opts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(opts, E_Value, rules)
+	return opts
+}
+
+// dynamicAccount builds the descriptor of an annotated message at runtime
+func dynamicAccount(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, rules *FieldRules) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if rules != nil {
+			fd.Options = fieldOptions(rules)
+		}
+		return fd
+	}
+	ignored := &descriptorpb.MessageOptions{}
+	proto.SetExtension(ignored, E_Ignored, true)
+
+	profile := field("profile", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		&FieldRules{Values: &FieldRules_Message{Message: &MessageRules{}}})
+	profile.TypeName = proto.String(".dynamic.Profile")
+	audit := field("audit", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		&FieldRules{Values: &FieldRules_Message{Message: &MessageRules{}}})
+	audit.TypeName = proto.String(".dynamic.Audit")
+	tokens := field("tokens", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		&FieldRules{Values: &FieldRules_Element{Element: &ElementRules{Item: &FieldRules{Values: &FieldRules_String_{String_: "***"}}}}})
+	tokens.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	mismatch := field("mismatch", 8, descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		&FieldRules{Values: &FieldRules_Int32{Int32: -1}})
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/dynamic.proto"),
+		Package:    proto.String("dynamic"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Account"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("username", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+				field("password", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&FieldRules{Values: &FieldRules_String_{String_: "hidden"}}),
+				field("pin", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32,
+					&FieldRules{Values: &FieldRules_Int32{Int32: -1}}),
+				field("card", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&FieldRules{Values: &FieldRules_PanMask{PanMask: true}}),
+				profile, audit, tokens, mismatch,
+			},
+		}, {
+			Name: proto.String("Profile"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("email", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&FieldRules{Values: &FieldRules_String_{String_: "r*d@ct*d"}}),
+			},
+		}, {
+			Name:    proto.String("Audit"),
+			Options: ignored,
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("actor", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+					&FieldRules{Values: &FieldRules_String_{String_: "hidden"}}),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	return fd.Messages().ByName("Account")
+}
+
+func TestRedactReflectDynamic(t *testing.T) {
+	desc := dynamicAccount(t)
+	fields := desc.Fields()
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(fields.ByName("username"), protoreflect.ValueOfString("john"))
+	msg.Set(fields.ByName("password"), protoreflect.ValueOfString("secret"))
+	msg.Set(fields.ByName("pin"), protoreflect.ValueOfInt32(1234))
+	msg.Set(fields.ByName("card"), protoreflect.ValueOfString("4111 1111 1111 1111"))
+	msg.Set(fields.ByName("mismatch"), protoreflect.ValueOfInt64(42))
+	profile := msg.Mutable(fields.ByName("profile")).Message()
+	profile.Set(profile.Descriptor().Fields().ByName("email"), protoreflect.ValueOfString("john@example.com"))
+	audit := msg.Mutable(fields.ByName("audit")).Message()
+	audit.Set(audit.Descriptor().Fields().ByName("actor"), protoreflect.ValueOfString("admin"))
+	tokens := msg.Mutable(fields.ByName("tokens")).List()
+	tokens.Append(protoreflect.ValueOfString("t1"))
+	tokens.Append(protoreflect.ValueOfString("t2"))
+
+	RedactReflect(msg)
+
+	for name, want := range map[protoreflect.Name]interface{}{
+		"username": "john",
+		"password": "hidden",
+		"pin":      int32(-1),
+		"card":     "**** **** **** 1111",
+		"mismatch": int64(42),
+	} {
+		if got := msg.Get(fields.ByName(name)).Interface(); got != want {
+			t.Errorf("%s should be %v, got %v", name, want, got)
+		}
+	}
+	if got := profile.Get(profile.Descriptor().Fields().ByName("email")).String(); got != "r*d@ct*d" {
+		t.Errorf("Profile should be redacted recursively, got %q", got)
+	}
+	if got := audit.Get(audit.Descriptor().Fields().ByName("actor")).String(); got != "admin" {
+		t.Errorf("Audit is ignored and should be kept, got %q", got)
+	}
+	for i := 0; i < tokens.Len(); i++ {
+		if got := tokens.Get(i).String(); got != "***" {
+			t.Errorf("tokens[%d] should be redacted, got %q", i, got)
+		}
+	}
+}
+
+func TestRedactReflectAllFields(t *testing.T) {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, E_AllFields, true)
+	allow := &descriptorpb.FieldOptions{}
+	proto.SetExtension(allow, E_Allow, true)
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	id := field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	id.Options = allow
+	tags := field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	note := field("note", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	note.Options = fieldOptions(&FieldRules{Values: &FieldRules_String_{String_: "custom"}})
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/allfields.proto"),
+		Package:    proto.String("dynamic.allfields"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:    proto.String("Secret"),
+			Options: opts,
+			Field: []*descriptorpb.FieldDescriptorProto{
+				id,
+				field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("balance", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				tags, note,
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	fields := fd.Messages().Get(0).Fields()
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(fields.ByName("id"), protoreflect.ValueOfString("id-1"))
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString("john"))
+	msg.Set(fields.ByName("balance"), protoreflect.ValueOfInt64(42))
+	msg.Mutable(fields.ByName("tags")).List().Append(protoreflect.ValueOfString("vip"))
+	msg.Set(fields.ByName("note"), protoreflect.ValueOfString("note"))
+
+	RedactReflect(msg)
+
+	for name, want := range map[protoreflect.Name]interface{}{
+		"id":      "id-1",
+		"name":    defaultString,
+		"balance": int64(0),
+		"note":    "custom",
+	} {
+		if got := msg.Get(fields.ByName(name)).Interface(); got != want {
+			t.Errorf("%s should be %v, got %v", name, want, got)
+		}
+	}
+	if msg.Has(fields.ByName("tags")) {
+		t.Errorf("tags should be cleared")
+	}
+}
+
+func TestRedactReflectFileDefault(t *testing.T) {
+	msgOpts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(msgOpts, E_AllFields, true)
+	fileOpts := &descriptorpb.FileOptions{}
+	proto.SetExtension(fileOpts, E_FileDefaultString, "[MASKED]")
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/filedefault.proto"),
+		Package:    proto.String("dynamic.filedefault"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		Options:    fileOpts,
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:    proto.String("Contact"),
+			Options: msgOpts,
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	name := fd.Messages().Get(0).Fields().ByName("name")
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(name, protoreflect.ValueOfString("john"))
+
+	RedactReflect(msg)
+
+	if got := msg.Get(name).String(); got != "[MASKED]" {
+		t.Errorf("name should be redacted to the file default, got %q", got)
+	}
+}
+
+func TestRedactReflectExcept(t *testing.T) {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, E_Except, "id")
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/except.proto"),
+		Package:    proto.String("dynamic.except"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:    proto.String("Patient"),
+			Options: opts,
+			Field:   []*descriptorpb.FieldDescriptorProto{field("id", 1), field("name", 2)},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	fields := fd.Messages().Get(0).Fields()
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(fields.ByName("id"), protoreflect.ValueOfString("p1"))
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString("john"))
+
+	RedactReflect(msg)
+
+	if got := msg.Get(fields.ByName("id")).String(); got != "p1" {
+		t.Errorf("id is listed and should be kept, got %q", got)
+	}
+	if got := msg.Get(fields.ByName("name")).String(); got != defaultString {
+		t.Errorf("name should be redacted, got %q", got)
+	}
+}
+
+func TestRedactReflectRegexReplace(t *testing.T) {
+	opts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(opts, E_Value, &FieldRules{Values: &FieldRules_RegexReplace{
+		RegexReplace: &RegexReplace{Pattern: `\d`, Replacement: "#"},
+	}})
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/regex.proto"),
+		Package:    proto.String("dynamic.regex"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Note"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("text"),
+				JsonName: proto.String("text"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options:  opts,
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	text := fd.Messages().Get(0).Fields().ByName("text")
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(text, protoreflect.ValueOfString("call 555-1234"))
+
+	RedactReflect(msg)
+
+	if got := msg.Get(text).String(); got != "call ###-####" {
+		t.Errorf("the digits should be replaced, got %q", got)
+	}
+	if _, ok := regexps.Load(`\d`); !ok {
+		t.Errorf("the compiled pattern should be cached")
+	}
+}
+
+func TestCompiledRegexp(t *testing.T) {
+	re := compiledRegexp(`[a-z]+`)
+	if re == nil || compiledRegexp(`[a-z]+`) != re {
+		t.Errorf("the pattern should be compiled once, got %v", re)
+	}
+	if re := compiledRegexp(`(`); re != nil || compiledRegexp(`(`) != nil {
+		t.Errorf("the invalid patterns should be nil, got %v", re)
+	}
+}
+
+func TestRedactReflectMagnitude(t *testing.T) {
+	opts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(opts, E_Value, &FieldRules{Values: &FieldRules_Magnitude{Magnitude: true}})
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/magnitude.proto"),
+		Package:    proto.String("dynamic.magnitude"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Metric"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("value"),
+				JsonName: proto.String("value"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_SINT64.Enum(),
+				Options:  opts,
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	value := fd.Messages().Get(0).Fields().ByName("value")
+
+	for in, want := range map[int64]int64{
+		12345:         10000,
+		9:             1,
+		10:            10,
+		-42:           -10,
+		math.MinInt64: -1000000000000000000,
+		math.MaxInt64: 1000000000000000000,
+		-1:            -1,
+	} {
+		msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+		msg.Set(value, protoreflect.ValueOfInt64(in))
+
+		RedactReflect(msg)
+
+		if got := msg.Get(value).Int(); got != want {
+			t.Errorf("%d should be redacted to %d, got %d", in, want, got)
+		}
+	}
+	if got := Magnitude(uint64(math.MaxUint64)); got != 10000000000000000000 {
+		t.Errorf("the largest unsigned values should be redacted, got %d", got)
+	}
+	if got := Magnitude(int32(0)); got != 0 {
+		t.Errorf("zero should be kept, got %d", got)
+	}
+}
+
+func TestRedactReflectGatedBy(t *testing.T) {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, E_AllFields, true)
+	proto.SetExtension(opts, E_GatedBy, "is_sensitive")
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/gated.proto"),
+		Package:    proto.String("dynamic.gated"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:    proto.String("Record"),
+			Options: opts,
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("is_sensitive", 2, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	fields := fd.Messages().Get(0).Fields()
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString("john"))
+
+	RedactReflect(msg)
+	if got := msg.Get(fields.ByName("name")).String(); got != "john" {
+		t.Errorf("name should be kept with a false gate, got %q", got)
+	}
+
+	msg.Set(fields.ByName("is_sensitive"), protoreflect.ValueOfBool(true))
+	RedactReflect(msg)
+	if got := msg.Get(fields.ByName("name")).String(); got != defaultString {
+		t.Errorf("name should be redacted with a true gate, got %q", got)
+	}
+	if !msg.Get(fields.ByName("is_sensitive")).Bool() {
+		t.Errorf("the gate should be kept")
+	}
+}
+
+func TestExceptFields(t *testing.T) {
+	got := ExceptFields(" id, created_at,,")
+	if len(got) != 2 || got[0] != "id" || got[1] != "created_at" {
+		t.Errorf("Should split and trim the names, got %q", got)
+	}
+	if got := ExceptFields(""); got != nil {
+		t.Errorf("Should return nil without names, got %q", got)
+	}
+}
+
+func TestRedactReflectAutoNested(t *testing.T) {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, E_AutoNested, true)
+	allow := &descriptorpb.FieldOptions{}
+	proto.SetExtension(allow, E_Allow, true)
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(".dynamic.autonested.PII"),
+		}
+	}
+	email := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("email"),
+		JsonName: proto.String("email"),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		Options:  fieldOptions(&FieldRules{Values: &FieldRules_String_{String_: "hidden"}}),
+	}
+	aliases := field("aliases", 2)
+	aliases.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	public := field("public", 3)
+	public.Options = allow
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/autonested.proto"),
+		Package:    proto.String("dynamic.autonested"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  proto.String("Customer"),
+			Field: []*descriptorpb.FieldDescriptorProto{field("contact", 1), aliases, public},
+		}, {
+			Name:    proto.String("PII"),
+			Options: opts,
+			Field:   []*descriptorpb.FieldDescriptorProto{email},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	fields := fd.Messages().Get(0).Fields()
+	emailField := fd.Messages().Get(1).Fields().ByName("email")
+	pii := func(value string) protoreflect.Message {
+		item := dynamicpb.NewMessage(fd.Messages().Get(1))
+		item.Set(emailField, protoreflect.ValueOfString(value))
+		return item
+	}
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	msg.Set(fields.ByName("contact"), protoreflect.ValueOfMessage(pii("a@example.com")))
+	msg.Mutable(fields.ByName("aliases")).List().Append(protoreflect.ValueOfMessage(pii("b@example.com")))
+	msg.Set(fields.ByName("public"), protoreflect.ValueOfMessage(pii("c@example.com")))
+
+	RedactReflect(msg)
+
+	if got := msg.Get(fields.ByName("contact")).Message().Get(emailField).String(); got != "hidden" {
+		t.Errorf("contact should be redacted, got %q", got)
+	}
+	if got := msg.Get(fields.ByName("aliases")).List().Get(0).Message().Get(emailField).String(); got != "hidden" {
+		t.Errorf("aliases should be redacted, got %q", got)
+	}
+	if got := msg.Get(fields.ByName("public")).Message().Get(emailField).String(); got != "c@example.com" {
+		t.Errorf("public is allowed and should be kept, got %q", got)
+	}
+}
+
+func TestRedactReflectCollapse(t *testing.T) {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, rule *ElementRules) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:     typ.Enum(),
+			Options:  fieldOptions(&FieldRules{Values: &FieldRules_Element{Element: rule}}),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic/collapse.proto"),
+		Package:    proto.String("dynamic.collapse"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact/v3/redact.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Profile"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("tags", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, &ElementRules{Collapse: true}),
+				field("ids", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64,
+					&ElementRules{Collapse: true, Item: &FieldRules{Values: &FieldRules_Int64{Int64: -1}}}),
+				field("scores", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32, &ElementRules{Collapse: true}),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("Should build the file descriptor: %v", err)
+	}
+	fields := fd.Messages().Get(0).Fields()
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	tags := msg.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("vip"))
+	tags.Append(protoreflect.ValueOfString("beta"))
+	ids := msg.Mutable(fields.ByName("ids")).List()
+	ids.Append(protoreflect.ValueOfInt64(42))
+
+	RedactReflect(msg)
+
+	if tags.Len() != 1 || tags.Get(0).String() != defaultString {
+		t.Errorf("tags should be collapsed to the default, got %v", tags)
+	}
+	if ids.Len() != 1 || ids.Get(0).Int() != -1 {
+		t.Errorf("ids should be collapsed to the item value, got %v", ids)
+	}
+	if msg.Has(fields.ByName("scores")) {
+		t.Errorf("scores is empty and should be kept empty")
+	}
+}
+
+// generatedAccount has a Redact() method, as the generated messages
+type generatedAccount struct {
+	*dynamicpb.Message
+	redacted bool
+}
+
+func (g *generatedAccount) Redact() string {
+	g.redacted = true
+	return ""
+}
+
+func TestApplyReflect(t *testing.T) {
+	desc := dynamicAccount(t)
+	password := desc.Fields().ByName("password")
+
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(password, protoreflect.ValueOfString("secret"))
+	if err := ApplyReflect(msg); err != nil {
+		t.Fatalf("Should redact by reflection: %v", err)
+	}
+	if got := msg.Get(password).String(); got != "hidden" {
+		t.Errorf("password should be redacted by reflection, got %q", got)
+	}
+
+	generated := &generatedAccount{Message: dynamicpb.NewMessage(desc)}
+	generated.Set(password, protoreflect.ValueOfString("secret"))
+	if err := ApplyReflect(generated); err != nil {
+		t.Fatalf("Should redact with Redact(): %v", err)
+	}
+	if !generated.redacted || generated.Get(password).String() != "secret" {
+		t.Errorf("Should call the Redact() method instead of reflection")
+	}
+	if err := ApplyReflect(nil); err != nil {
+		t.Errorf("Should ignore nil messages: %v", err)
+	}
+}
+
+func TestRedactReflectNil(t *testing.T) {
+	RedactReflect(nil)
+	RedactReflect(dynamicpb.NewMessage(dynamicAccount(t)))
+}
@@ -0,0 +1,49 @@
+package redact
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// requestRedactors holds the functions redacting the registered request types,
+// by their Go type
+var requestRedactors sync.Map
+
+// RegisterRequest registers the function redacting the requests of the Go type
+// of msg, e.g. a nil *pb.Request, used by RedactRequest instead of
+// ApplyReflect. The functions are registered by the code generated with
+// register_requests, registering a type again replaces its function.
+func RegisterRequest(msg proto.Message, redact func(ctx context.Context, req interface{}) error) {
+	requestRedactors.Store(reflect.TypeOf(msg), redact)
+}
+
+// RedactRequest returns a redacted clone of the request, e.g. for the logging
+// interceptors, the request itself is not modified and is passed as is to the
+// handler: only the logged copy is redacted. The clone is redacted by the
+// function registered for its type with RegisterRequest, or by ApplyReflect.
+// The requests which are not proto messages, or whose redaction fails, are
+// returned as nil rather than logged unredacted, the nil messages as is.
+func RedactRequest(ctx context.Context, req interface{}) interface{} {
+	msg, ok := req.(proto.Message)
+	if !ok || msg == nil {
+		return nil
+	}
+	if !msg.ProtoReflect().IsValid() {
+		// a nil message, e.g. a nil *pb.Request, has nothing to redact
+		return msg
+	}
+	clone := proto.Clone(msg)
+	var err error
+	if redact, ok := requestRedactors.Load(reflect.TypeOf(clone)); ok {
+		err = redact.(func(context.Context, interface{}) error)(ctx, clone)
+	} else {
+		err = ApplyReflect(clone)
+	}
+	if err != nil {
+		return nil
+	}
+	return clone
+}
@@ -0,0 +1,65 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestRedactRequest(t *testing.T) {
+	ctx := context.Background()
+	if got := RedactRequest(ctx, "request"); got != nil {
+		t.Errorf("RedactRequest should drop the requests which are not messages, got %v", got)
+	}
+
+	desc := dynamicAccount(t)
+	password := desc.Fields().ByName("password")
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(password, protoreflect.ValueOfString("secret"))
+
+	// the dynamic messages are redacted by reflection
+	got, ok := RedactRequest(ctx, msg).(*dynamicpb.Message)
+	if !ok {
+		t.Fatalf("RedactRequest should return a clone of the request, got %T", got)
+	}
+	if got.Get(password).String() != "hidden" {
+		t.Errorf("the copy should be redacted, got %q", got.Get(password).String())
+	}
+	if msg.Get(password).String() != "secret" {
+		t.Errorf("the request should be kept, got %q", msg.Get(password).String())
+	}
+}
+
+func TestRedactRequestRegistered(t *testing.T) {
+	typ := reflect.TypeOf(&dynamicpb.Message{})
+	defer requestRedactors.Delete(typ)
+
+	desc := dynamicAccount(t)
+	username := desc.Fields().ByName("username")
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(username, protoreflect.ValueOfString("john"))
+
+	RegisterRequest(msg, func(_ context.Context, req interface{}) error {
+		req.(proto.Message).ProtoReflect().Set(username, protoreflect.ValueOfString("anonymous"))
+		return nil
+	})
+	got := RedactRequest(context.Background(), msg).(proto.Message)
+	if name := got.ProtoReflect().Get(username).String(); name != "anonymous" {
+		t.Errorf("the copy should be redacted by the registered function, got %q", name)
+	}
+
+	RegisterRequest(msg, func(context.Context, interface{}) error {
+		return errors.New("failed")
+	})
+	if got := RedactRequest(context.Background(), msg); got != nil {
+		t.Errorf("the requests failing the redaction should be dropped, got %v", got)
+	}
+	if msg.Get(username).String() != "john" {
+		t.Errorf("the request should be kept, got %q", msg.Get(username).String())
+	}
+}
@@ -0,0 +1,31 @@
+package redact
+
+import "math"
+
+// RoundTo truncates the value toward zero to a multiple of the factor, e.g.
+// RoundTo(12.34, 0.5) is 12, keeping an approximate value. The infinite and
+// NaN values are kept.
+func RoundTo[T ~float32 | ~float64](v, factor T) T {
+	if factor == 0 || math.IsInf(float64(v), 0) || math.IsNaN(float64(v)) {
+		return v
+	}
+	return T(math.Trunc(float64(v)/float64(factor)) * float64(factor))
+}
+
+// Magnitude redacts the integer to its order of magnitude, the largest power of
+// ten not above its absolute value with its sign, e.g. Magnitude(12345) is
+// 10000 and Magnitude(-42) is -10. Zero is kept.
+func Magnitude[T ~int32 | ~int64 | ~uint32 | ~uint64](v T) T {
+	if v == 0 {
+		return 0
+	}
+	// the quotients keep the sign, the lowest values have no absolute value
+	p := T(1)
+	for q := v / 10; q != 0; q /= 10 {
+		p *= 10
+	}
+	if v < 0 {
+		p = -p
+	}
+	return p
+}
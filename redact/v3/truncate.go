@@ -0,0 +1,45 @@
+package redact
+
+import "unicode/utf8"
+
+// TruncateString caps the string to at most n bytes, without splitting a
+// multi-byte character
+func TruncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// TruncateBytes caps the bytes to at most n bytes
+func TruncateBytes(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	return b[:n]
+}
+
+// TruncateSlice caps the repeated field to at most n items
+func TruncateSlice[T any](s []T, n int) []T {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// TruncateMap caps the map field to at most n entries, the dropped entries
+// are arbitrary as maps are unordered
+func TruncateMap[K comparable, V any](m map[K]V, n int) {
+	if len(m) <= n {
+		return
+	}
+	for k := range m {
+		if len(m) <= n {
+			return
+		}
+		delete(m, k)
+	}
+}
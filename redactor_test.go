@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"testing"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
@@ -39,12 +40,13 @@ func TestRedactionDefaults(t *testing.T) {
 		// String type
 		{"string", pgs.StringT, false, `"REDACTED"`},
 
-		// Bytes and group
+		// Bytes type
 		{"bytes", pgs.BytesT, false, "nil"},
-		{"group", pgs.GroupT, false, "nil"},
 
-		// Message type
-		{"message", pgs.MessageT, false, "-"},
+		// Message and group types
+		{"message", pgs.MessageT, false, "nil"},
+		{"group", pgs.GroupT, false, "nil"},
+		{"repeated_group", pgs.GroupT, true, "nil"},
 
 		// Repeated/map types
 		{"repeated_int32", pgs.Int32T, true, "nil"},
@@ -91,6 +93,7 @@ func TestToCustomRule(t *testing.T) {
 		{"bytes", pgs.BytesT, pgs.Optional, "(redact.custom).bytes"},
 		{"enum", pgs.EnumT, pgs.Optional, "(redact.custom).enum"},
 		{"message", pgs.MessageT, pgs.Optional, "(redact.custom).message.*"},
+		{"group", pgs.GroupT, pgs.Optional, "(redact.custom).message.*"},
 
 		// Unknown type
 		{"unknown", pgs.ProtoType(999), pgs.Optional, "(redact.redact)"},
@@ -105,6 +108,147 @@ func TestToCustomRule(t *testing.T) {
 	}
 }
 
+// TestParseDefault tests conversion of default override parameters
+func TestParseDefault(t *testing.T) {
+	tests := []struct {
+		name      string
+		typ       pgs.ProtoType
+		val       string
+		want      string
+		shouldErr bool
+	}{
+		{"int64", pgs.Int64T, "-1", "-1", false},
+		{"int32_overflow", pgs.Int32T, "4294967296", "", true},
+		{"uint32_negative", pgs.UInt32T, "-1", "", true},
+		{"double", pgs.DoubleT, "-1.5", "-1.5", false},
+		{"bool", pgs.BoolT, "true", "true", false},
+		{"bool_invalid", pgs.BoolT, "yes", "", true},
+		{"bool_one", pgs.BoolT, "1", "true", false},
+		{"bool_t", pgs.BoolT, "t", "true", false},
+		{"bool_upper", pgs.BoolT, "TRUE", "true", false},
+		{"bool_zero", pgs.BoolT, "0", "false", false},
+		{"int32_plus", pgs.Int32T, "+7", "7", false},
+		{"int64_leading_zero", pgs.Int64T, "010", "10", false},
+		{"uint64_leading_zero", pgs.UInt64T, "0017", "17", false},
+		{"float_exponent", pgs.FloatT, "1E3", "1000", false},
+		{"float_nan", pgs.FloatT, "NaN", "", true},
+		{"float_overflow", pgs.FloatT, "1e39", "", true},
+		{"double_inf", pgs.DoubleT, "Inf", "", true},
+		{"double_negative_inf", pgs.DoubleT, "-infinity", "", true},
+		{"double_plus", pgs.DoubleT, "+0.25", "0.25", false},
+		{"enum", pgs.EnumT, "2", "2", false},
+		{"string", pgs.StringT, `say "hi"`, `"say \"hi\""`, false},
+		{"bytes", pgs.BytesT, "xx", `[]byte("xx")`, false},
+		{"message", pgs.MessageT, "x", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDefault(tt.typ, tt.val)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestRedactionDefaultRegistry tests the per type defaults of the module
+func TestRedactionDefaultRegistry(t *testing.T) {
+	m := &Module{ModuleBase: &pgs.ModuleBase{}}
+
+	// without a registry the predefined defaults are used
+	assert.Equal(t, "0", m.redactionDefault(pgs.Int64T, false))
+
+	m.defaults = defaultRegistry()
+	for _, typ := range defaultParams {
+		assert.Equal(t, RedactionDefaults(typ, false), m.redactionDefault(typ, false))
+	}
+
+	m.defaults[pgs.Int64T] = "-1"
+	assert.Equal(t, "-1", m.redactionDefault(pgs.Int64T, false))
+	assert.Equal(t, "0", m.redactionDefault(pgs.Int32T, false))
+	assert.Equal(t, "nil", m.redactionDefault(pgs.Int64T, true), "Repeated fields are not affected")
+}
+
+// TestParseGoRef tests parsing of the Go references of the plugin parameters
+func TestParseGoRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		ref       string
+		want      *GoRef
+		alias     string
+		shouldErr bool
+	}{
+		{"simple", "github.com/acme/authz.ShouldRedact", &GoRef{"github.com/acme/authz", "ShouldRedact"}, "authz", false},
+		{"dashed_package", "github.com/acme/go-authz.Check", &GoRef{"github.com/acme/go-authz", "Check"}, "go_authz", false},
+		{"dotted_module", "gopkg.in/authz.v2.Check", &GoRef{"gopkg.in/authz.v2", "Check"}, "authz_v2", false},
+		{"no_path", "ShouldRedact", nil, "", true},
+		{"no_name", "github.com/acme.v2/authz", nil, "", true},
+		{"unexported", "github.com/acme/authz.shouldRedact", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoRef(tt.ref)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.alias, got.Alias())
+		})
+	}
+}
+
+// TestParseGoExpr tests parsing of the expressions of the default_<type>_expr
+// parameters, and their resolution with the import aliases of a file
+func TestParseGoExpr(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		imports   map[string]string
+		want      string
+		shouldErr bool
+	}{
+		{"literal", `"***"`, map[string]string{}, `"***"`, false},
+		{"import_path", "github.com/acme/mask.Placeholder()", map[string]string{"mask": "github.com/acme/mask"}, "acme_mask.Placeholder()", false},
+		{"std", "math.MinInt64", map[string]string{"math": "math"}, "math.MinInt64", false},
+		{"nested_std", `[]byte(strings.ToUpper("x"))`, map[string]string{"strings": "strings"}, `[]byte(strings1.ToUpper("x"))`, false},
+		{"redact", "redact.Placeholder()", map[string]string{"redact": "github.com/menta2k/protoc-gen-redact/v3/redact/v3"}, "redact.Placeholder()", false},
+		{"local", "Masks.String", map[string]string{}, "Masks.String", false},
+		{"chained", "time.Unix(0, 0).String()", map[string]string{"time": "time"}, "time.Unix(0, 0).String()", false},
+		{"self", "github.com/acme/api.Mask", map[string]string{"api": "github.com/acme/api"}, "Mask", false},
+		{"unbalanced", "mask.Placeholder(", nil, "", true},
+		{"statements", `"x"; os.Exit(1)`, nil, "", true},
+		{"empty", "", nil, "", true},
+		{"unexported", "github.com/acme/mask.placeholder()", nil, "", true},
+	}
+
+	path2Alias := map[string]string{
+		"github.com/acme/mask": "acme_mask",
+		"math":                 "math",
+		"strings":              "strings1",
+		"time":                 "time",
+		"github.com/menta2k/protoc-gen-redact/v3/redact/v3": "redact",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoExpr(tt.expr)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.imports, got.Imports)
+			assert.Equal(t, tt.want, got.resolve(path2Alias))
+		})
+	}
+}
+
 // TestModuleName tests the module name
 func TestModuleName(t *testing.T) {
 	m := &Module{ModuleBase: &pgs.ModuleBase{}}
@@ -253,7 +397,16 @@ func TestRuleInformation(t *testing.T) {
 				Values: &redact.FieldRules_String_{String_: "custom_value"},
 			},
 			expectedType:       pgs.StringT,
-			expectedValue:      "`custom_value`",
+			expectedValue:      `"custom_value"`,
+			shouldContainValue: true,
+		},
+		{
+			name: "string_rule_backtick",
+			rules: &redact.FieldRules{
+				Values: &redact.FieldRules_String_{String_: "a`b"},
+			},
+			expectedType:       pgs.StringT,
+			expectedValue:      `"a` + "`" + `b"`,
 			shouldContainValue: true,
 		},
 		{
@@ -262,7 +415,16 @@ func TestRuleInformation(t *testing.T) {
 				Values: &redact.FieldRules_Bytes{Bytes: []byte("test_bytes")},
 			},
 			expectedType:       pgs.BytesT,
-			expectedValue:      "[]byte(`test_bytes`)",
+			expectedValue:      `[]byte("test_bytes")`,
+			shouldContainValue: true,
+		},
+		{
+			name: "bytes_rule_backtick",
+			rules: &redact.FieldRules{
+				Values: &redact.FieldRules_Bytes{Bytes: []byte("a`b")},
+			},
+			expectedType:       pgs.BytesT,
+			expectedValue:      `[]byte("a` + "`" + `b")`,
 			shouldContainValue: true,
 		},
 		{
@@ -315,6 +477,8 @@ func TestRuleInformation(t *testing.T) {
 			if tt.shouldContainValue {
 				assert.Equal(t, tt.expectedValue, result.RedactionValue,
 					"Expected RedactionValue %v, got %v", tt.expectedValue, result.RedactionValue)
+				assert.Equal(t, fmt.Sprintf("%v", result.RedactionValue), result.Literal,
+					"Literal should match the formatted RedactionValue")
 			}
 		})
 	}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+)
+
+// piiKeywords are parts of the field names which likely hold PII
+var piiKeywords = []string{"password", "ssn", "email", "token", "secret"}
+
+// looksLikePII checks if the field name contains one of the PII keywords,
+// ignoring the case
+func looksLikePII(name string, keywords []string) bool {
+	name = strings.ToLower(name)
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(name, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPII warns about the unredacted field if its name looks like PII, or
+// fails with strict_pii. Fields explicitly allowed are not reported.
+func (m *Module) checkPII(field pgs.Field, flData *FieldData) {
+	if (!m.warnPII && !m.strictPII) || flData.Redact || flData.Allow {
+		return
+	}
+	if !looksLikePII(field.Name().String(), m.piiKeywords) {
+		return
+	}
+	msg := fmt.Sprintf("%s looks like PII but is not redacted, "+
+		"add a (redact.v3.value) rule or mark it with (redact.v3.allow) = true", field.FullyQualifiedName())
+	if m.strictPII {
+		m.Fail(msg)
+		return
+	}
+	m.Logf("Warning: %s", msg)
+}
+
+// checkRedaction fails the generation for the messages without redacted fields
+// which are neither ignored nor redacted to nil or empty, with require_redaction
+// the public messages must be explicitly ignored. The data of the messages
+// follow the order of the messages.
+func (m *Module) checkRedaction(msgs []pgs.Message, data []*MessageData) {
+	var names []string
+	for i, msg := range msgs {
+		if data[i] == nil || data[i].Ignore || data[i].ToNil || data[i].ToEmpty {
+			continue
+		}
+		redacted := false
+		for _, field := range data[i].Fields {
+			redacted = redacted || field.Redact
+		}
+		if !redacted {
+			names = append(names, msg.FullyQualifiedName())
+		}
+	}
+	if len(names) > 0 {
+		m.Failf("%s redact no field, add (redact.v3.value) rules or mark them with "+
+			"option (redact.v3.ignored) = true", strings.Join(names, ", "))
+	}
+}
+
+// report summarizes the redaction of the file for reviews, listing the
+// redacted and unredacted fields of each message. The unredacted fields
+// looking like PII and the messages without any redaction are flagged.
+func (m *Module) report(data *ProtoFileData) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Redaction report for %s\n", data.Source)
+	for _, msg := range data.Messages {
+		if msg == nil {
+			continue
+		}
+		switch {
+		case msg.Ignore:
+			fmt.Fprintf(sb, "\nmessage %s: ignored\n", msg.Name)
+			continue
+		case msg.ToNil:
+			fmt.Fprintf(sb, "\nmessage %s: redacted to nil\n", msg.Name)
+			continue
+		case msg.ToEmpty:
+			fmt.Fprintf(sb, "\nmessage %s: redacted to empty\n", msg.Name)
+			continue
+		}
+
+		redacted := 0
+		for _, field := range msg.Fields {
+			if field.Redact {
+				redacted++
+			}
+		}
+		fmt.Fprintf(sb, "\nmessage %s: %d/%d fields redacted\n", msg.Name, redacted, len(msg.Fields))
+		if redacted == 0 {
+			sb.WriteString("  WARNING: no redacted fields\n")
+		}
+		for _, field := range msg.Fields {
+			meta := fieldMeta(field)
+			switch {
+			case field.Redact && meta.Value != "":
+				fmt.Fprintf(sb, "  + %s: %s %s\n", field.Name, meta.Strategy, meta.Value)
+			case field.Redact:
+				fmt.Fprintf(sb, "  + %s: %s\n", field.Name, meta.Strategy)
+			case looksLikePII(field.Name, m.piiKeywords):
+				fmt.Fprintf(sb, "  - %s: WARNING: looks like PII but is not redacted\n", field.Name)
+			default:
+				fmt.Fprintf(sb, "  - %s\n", field.Name)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLooksLikePII(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords []string
+		want     bool
+	}{
+		{name: "password", keywords: piiKeywords, want: true},
+		{name: "user_email", keywords: piiKeywords, want: true},
+		{name: "AccessToken", keywords: piiKeywords, want: true},
+		{name: "ssn", keywords: piiKeywords, want: true},
+		{name: "username", keywords: piiKeywords, want: false},
+		{name: "street", keywords: piiKeywords, want: false},
+		{name: "phone", keywords: []string{"Phone"}, want: true},
+		{name: "email", keywords: []string{"phone"}, want: false},
+		{name: "email", keywords: []string{""}, want: false},
+		{name: "email", keywords: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, looksLikePII(tt.name, tt.keywords))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package allfields
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAllFieldsRedaction(t *testing.T) {
+	msg := &Secret{
+		Id:      "id-1",
+		Name:    "john",
+		Balance: 42,
+		Active:  true,
+		Email:   proto.String("john@example.com"),
+		Key:     []byte("key"),
+		Level:   Level_LEVEL_HIGH,
+		Tags:    []string{"vip"},
+		Labels:  map[string]string{"team": "core"},
+		Inner:   &Inner{Token: "token", Hint: "hint"},
+		Note:    "note",
+	}
+	msg.Redact()
+
+	if msg.Id != "id-1" {
+		t.Errorf("Allowed field should be kept, got %q", msg.Id)
+	}
+	if msg.Name != "REDACTED" || msg.Balance != 0 || msg.Active || msg.GetEmail() != "REDACTED" {
+		t.Errorf("Scalar fields should be redacted with the defaults, got %v", msg)
+	}
+	if msg.Key != nil || msg.Level != Level_LEVEL_UNSPECIFIED || msg.Tags != nil || msg.Labels != nil {
+		t.Errorf("Bytes, enum, repeated and map fields should be cleared, got %v", msg)
+	}
+	if msg.Inner.GetToken() != "hidden" || msg.Inner.GetHint() != "hint" {
+		t.Errorf("Inner should be redacted by its own rules, got %v", msg.Inner)
+	}
+	if msg.Note != "custom" {
+		t.Errorf("Field rules should override the defaults, got %q", msg.Note)
+	}
+}
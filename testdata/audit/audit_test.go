@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	redact "github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// recordAudit records the fields reported by redact.Audit until the end of the
+// test
+func recordAudit(t *testing.T) func() []string {
+	var (
+		mu     sync.Mutex
+		fields []string
+	)
+	redact.SetAuditHook(func(msg, field string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fields = append(fields, msg+"."+field)
+	})
+	t.Cleanup(func() { redact.SetAuditHook(nil) })
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return fields
+	}
+}
+
+func TestAuditRedaction(t *testing.T) {
+	audited := recordAudit(t)
+	msg := &Account{
+		Username: "john",
+		Password: "secret",
+		Profile:  &Profile{Email: "john@example.com"},
+		Backup:   &Profile{Email: "john@backup.com"},
+		Secret:   &Account_Otp{Otp: "123456"},
+	}
+	msg.Redact()
+
+	want := []string{
+		"audit.Account.password",
+		"audit.Account.tokens",
+		"audit.Profile.email",
+		"audit.Account.profile",
+		"audit.Account.otp",
+	}
+	if got := audited(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Audited fields should be %v, got %v", want, got)
+	}
+	if msg.Password != "hidden" || msg.GetOtp() != "000000" {
+		t.Errorf("Fields should be redacted, got %v", msg)
+	}
+}
+
+func TestAuditDefaultHook(t *testing.T) {
+	redact.SetAuditHook(nil)
+	msg := &Account{Password: "secret"}
+	msg.Redact()
+
+	if msg.Password != "hidden" {
+		t.Errorf("Password should be redacted, got %q", msg.Password)
+	}
+}
@@ -0,0 +1,53 @@
+package autonested
+
+import "testing"
+
+func TestAutoNestedRedaction(t *testing.T) {
+	msg := &Customer{
+		Id:        "c1",
+		Contact:   &PII{Email: "a@example.com", Country: "FR"},
+		Aliases:   []*PII{{Email: "b@example.com"}, nil},
+		Relatives: map[string]*PII{"mother": {Email: "c@example.com"}},
+		Source:    &Customer_Referrer{Referrer: &PII{Email: "d@example.com"}},
+		Public:    &PII{Email: "e@example.com"},
+	}
+	msg.Redact()
+
+	if msg.Id != "c1" || msg.Contact.Country != "FR" {
+		t.Errorf("Safe fields should be kept, got %v", msg)
+	}
+	if msg.Contact.Email != "hidden" || msg.Aliases[0].Email != "hidden" || msg.Aliases[1] != nil {
+		t.Errorf("Embedded PII should be redacted, got %v and %v", msg.Contact, msg.Aliases)
+	}
+	if msg.Relatives["mother"].Email != "hidden" || msg.GetReferrer().Email != "hidden" {
+		t.Errorf("Embedded PII should be redacted, got %v and %v", msg.Relatives, msg.GetReferrer())
+	}
+	if msg.Public.Email != "e@example.com" {
+		t.Errorf("Allowed PII should be kept, got %v", msg.Public)
+	}
+}
+
+func TestAutoNestedMessages(t *testing.T) {
+	msg := &Order{
+		Customer: &Customer{Contact: &PII{Email: "a@example.com"}},
+		Billing:  &PII{Email: "b@example.com"},
+		Shipping: &PII{Email: "c@example.com"},
+	}
+	msg.Redact()
+
+	if msg.Customer.Contact.Email != "hidden" || msg.Billing.Email != "hidden" {
+		t.Errorf("Embedded PII should be redacted, got %v and %v", msg.Customer, msg.Billing)
+	}
+	if msg.Shipping != nil {
+		t.Errorf("Explicit rules should be kept, got %v", msg.Shipping)
+	}
+}
+
+func TestAutoNestedNil(t *testing.T) {
+	msg := &Customer{Id: "c1"}
+	msg.Redact()
+
+	if msg.Contact != nil || msg.Aliases != nil || msg.Source != nil {
+		t.Errorf("Nil messages should be kept nil, got %v", msg)
+	}
+}
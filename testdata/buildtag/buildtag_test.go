@@ -0,0 +1,27 @@
+package buildtag
+
+import (
+	"context"
+	"testing"
+)
+
+// server returns the requested account as is
+type server struct {
+	UnimplementedAccountsServer
+}
+
+func (server) GetAccount(_ context.Context, in *Account) (*Account, error) { return in, nil }
+
+func TestBuildTag(t *testing.T) {
+	msg := &Account{Username: "john", Password: "secret"}
+	msg.Redact()
+
+	if msg.Password != want {
+		t.Errorf("Password should be %q, got %q", want, msg.Password)
+	}
+
+	res, err := RedactedAccountsServer(server{}, nil).GetAccount(context.Background(), &Account{Password: "secret"})
+	if err != nil || res.Password != want {
+		t.Errorf("Response password should be %q, got %v (%v)", want, res, err)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build redact
+
+package buildtag
+
+// want is the password once redacted, with the redact build tag
+const want = "hidden"
@@ -0,0 +1,39 @@
+package clearelements
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClearElements(t *testing.T) {
+	msg := &Order{
+		Items:      []*Item{{Sku: "a"}, {Sku: "b"}, nil},
+		BySku:      map[string]*Item{"a": {Sku: "a"}},
+		Notes:      []string{"first", "second"},
+		Quantities: map[string]int64{"a": 1, "b": 2},
+		Gifts:      []*Item{{Sku: "c"}},
+	}
+	msg.Redact()
+
+	if len(msg.Items) != 3 {
+		t.Fatalf("Items count should be kept, got %d", len(msg.Items))
+	}
+	for i, item := range msg.Items {
+		if item == nil || !proto.Equal(item, &Item{}) {
+			t.Errorf("Items[%d] should be an empty message, got %v", i, item)
+		}
+	}
+	if len(msg.BySku) != 1 || !proto.Equal(msg.BySku["a"], &Item{}) {
+		t.Errorf("BySku should keep its keys with empty messages, got %v", msg.BySku)
+	}
+	if len(msg.Notes) != 2 || msg.Notes[0] != "" || msg.Notes[1] != "" {
+		t.Errorf("Notes should keep the count with empty strings, got %q", msg.Notes)
+	}
+	if len(msg.Quantities) != 2 || msg.Quantities["a"] != 0 || msg.Quantities["b"] != 0 {
+		t.Errorf("Quantities should keep their keys with zero values, got %v", msg.Quantities)
+	}
+	if len(msg.Gifts) != 0 {
+		t.Errorf("Gifts should be emptied, got %v", msg.Gifts)
+	}
+}
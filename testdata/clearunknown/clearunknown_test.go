@@ -0,0 +1,30 @@
+package clearunknown
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// unknown returns an unknown string field, e.g. added by a newer schema
+func unknown() []byte {
+	b := protowire.AppendTag(nil, 99, protowire.BytesType)
+	return protowire.AppendString(b, "secret")
+}
+
+func TestClearUnknownRedaction(t *testing.T) {
+	msg := &Event{Name: "login", Secret: "secret", Audit: &Audit{Actor: "admin"}}
+	msg.ProtoReflect().SetUnknown(unknown())
+	msg.Audit.ProtoReflect().SetUnknown(unknown())
+	msg.Redact()
+
+	if len(msg.ProtoReflect().GetUnknown()) != 0 {
+		t.Errorf("Unknown fields should be cleared, got %v", msg.ProtoReflect().GetUnknown())
+	}
+	if msg.Name != "login" || msg.Secret != "hidden" {
+		t.Errorf("Known fields should be redacted by their rules, got %v", msg)
+	}
+	if len(msg.Audit.ProtoReflect().GetUnknown()) == 0 {
+		t.Errorf("Unknown fields of the ignored messages should be kept")
+	}
+}
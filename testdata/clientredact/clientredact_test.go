@@ -0,0 +1,49 @@
+package clientredact
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// tokenConn replies to the unary calls with a secret token
+type tokenConn struct {
+	grpc.ClientConnInterface
+}
+
+func (tokenConn) Invoke(_ context.Context, _ string, _, reply interface{}, _ ...grpc.CallOption) error {
+	proto.Merge(reply.(proto.Message), &Token{Value: "secret"})
+	return nil
+}
+
+func TestRedactedClient(t *testing.T) {
+	client := NewRedactedTokensClient(tokenConn{})
+
+	res, err := client.GetToken(context.Background(), &GetTokenRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "REDACTED" {
+		t.Errorf("Value should be redacted, got %q", res.Value)
+	}
+
+	res, err = client.GetRawToken(context.Background(), &GetTokenRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "secret" {
+		t.Errorf("Value of the skipped method should be kept, got %q", res.Value)
+	}
+}
+
+func TestPlainClient(t *testing.T) {
+	res, err := NewTokensClient(tokenConn{}).GetToken(context.Background(), &GetTokenRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "secret" {
+		t.Errorf("Value should be kept by the plain client, got %q", res.Value)
+	}
+}
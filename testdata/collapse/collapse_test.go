@@ -0,0 +1,38 @@
+package collapse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollapseRedaction(t *testing.T) {
+	msg := &Profile{
+		Tags:  []string{"vip", "beta"},
+		Ids:   []int64{1, 2, 3},
+		Roles: []Role{Role_ROLE_ADMIN},
+		Keys:  [][]byte{[]byte("k1"), []byte("k2")},
+	}
+	msg.Redact()
+
+	if !reflect.DeepEqual(msg.Tags, []string{"REDACTED"}) {
+		t.Errorf("Tags should be collapsed to the default, got %v", msg.Tags)
+	}
+	if !reflect.DeepEqual(msg.Ids, []int64{-1}) {
+		t.Errorf("Ids should be collapsed to the item value, got %v", msg.Ids)
+	}
+	if !reflect.DeepEqual(msg.Roles, []Role{Role_ROLE_UNSPECIFIED}) {
+		t.Errorf("Roles should be collapsed to the default, got %v", msg.Roles)
+	}
+	if !reflect.DeepEqual(msg.Keys, [][]byte{[]byte("***")}) {
+		t.Errorf("Keys should be collapsed to the item value, got %q", msg.Keys)
+	}
+}
+
+func TestCollapseEmpty(t *testing.T) {
+	msg := &Profile{Tags: []string{}}
+	msg.Redact()
+
+	if msg.Tags == nil || len(msg.Tags) != 0 || msg.Ids != nil {
+		t.Errorf("Empty lists should be kept empty and nil lists nil, got %v", msg)
+	}
+}
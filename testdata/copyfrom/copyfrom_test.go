@@ -0,0 +1,43 @@
+package copyfrom
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCopyFromRedaction(t *testing.T) {
+	msg := &User{
+		UserId:        "u-42",
+		DisplayName:   "John Doe",
+		Alias:         proto.String("jd"),
+		Nickname:      proto.String("johnny"),
+		Role:          Role_ROLE_ADMIN,
+		RequestedRole: Role_ROLE_ADMIN,
+		Avatar:        []byte("avatar"),
+		Placeholder:   []byte("default"),
+	}
+	msg.Redact()
+
+	if msg.DisplayName != "u-42" {
+		t.Errorf("DisplayName should be copied from UserId, got %q", msg.DisplayName)
+	}
+	if msg.GetNickname() != "jd" || msg.Nickname == msg.Alias {
+		t.Errorf("Nickname should be a copy of Alias, got %v", msg.Nickname)
+	}
+	if msg.RequestedRole != Role_ROLE_UNSPECIFIED {
+		t.Errorf("RequestedRole should be copied from the redacted Role, got %v", msg.RequestedRole)
+	}
+	if string(msg.Avatar) != "default" {
+		t.Errorf("Avatar should be copied from Placeholder, got %q", msg.Avatar)
+	}
+}
+
+func TestCopyFromUnsetSibling(t *testing.T) {
+	msg := &User{Nickname: proto.String("johnny")}
+	msg.Redact()
+
+	if msg.Nickname != nil {
+		t.Errorf("Nickname should be unset as Alias, got %q", msg.GetNickname())
+	}
+}
@@ -0,0 +1,22 @@
+package crosspkg
+
+import (
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/crosspkg/common"
+)
+
+func TestImportedMapValueRedaction(t *testing.T) {
+	msg := &Directory{Profiles: map[string]*common.Profile{
+		"jdoe": {Name: "John", Email: "john@example.com"},
+	}}
+	msg.Redact()
+
+	profile := msg.Profiles["jdoe"]
+	if profile.Email != "hidden" {
+		t.Errorf("Email should be redacted by the imported Redact(), got %q", profile.Email)
+	}
+	if profile.Name != "John" {
+		t.Errorf("Name should be kept, got %q", profile.Name)
+	}
+}
@@ -0,0 +1,36 @@
+package ctxpredicate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/ctxpredicate/predicate"
+)
+
+type tokensServer struct {
+	UnimplementedTokensServer
+}
+
+func (tokensServer) GetToken(context.Context, *GetTokenRequest) (*Token, error) {
+	return &Token{Value: "secret"}, nil
+}
+
+func TestContextPredicate(t *testing.T) {
+	srv := RedactedTokensServer(tokensServer{}, nil)
+
+	res, err := srv.GetToken(context.Background(), &GetTokenRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "REDACTED" {
+		t.Errorf("Value should be redacted, got %q", res.Value)
+	}
+
+	res, err = srv.GetToken(predicate.WithDebug(context.Background()), &GetTokenRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "secret" {
+		t.Errorf("Value should not be redacted when the predicate is false, got %q", res.Value)
+	}
+}
@@ -0,0 +1,16 @@
+// Package predicate decides whether to redact from a debug flag of the context
+package predicate
+
+import "context"
+
+type debugKey struct{}
+
+// WithDebug flags the context to skip the redaction
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// ShouldRedact redacts unless the context is flagged for debugging
+func ShouldRedact(ctx context.Context) bool {
+	return ctx.Value(debugKey{}) == nil
+}
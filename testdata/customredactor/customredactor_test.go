@@ -0,0 +1,64 @@
+package customredactor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+func TestCustomRedactor(t *testing.T) {
+	var names []string
+	redact.SetRedactor(redact.FieldRedactorFunc(func(name string, v any) any {
+		names = append(names, name)
+		switch name {
+		case "username":
+			return "j***"
+		case "balance":
+			// mismatched types are redacted to the zero value
+			return "0"
+		case "contact":
+			return &Account_Phone{Phone: "***"}
+		}
+		return v
+	}))
+	defer redact.SetRedactor(nil)
+
+	msg := &Account{
+		Username: "john",
+		Password: "secret",
+		Balance:  42,
+		Tokens:   []string{"t1"},
+		Profile:  &Profile{Name: "John"},
+		Contact:  &Account_Email{Email: "john@example.com"},
+	}
+	msg.Redact()
+
+	if want := []string{"username", "password", "balance", "tokens", "profile", "contact"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Fields should be routed by their proto names %v, got %v", want, names)
+	}
+	if msg.Username != "j***" {
+		t.Errorf("Username should be redacted by the redactor, got %q", msg.Username)
+	}
+	if msg.Password != "hidden" {
+		t.Errorf("Password should keep its field rule, got %q", msg.Password)
+	}
+	if msg.Balance != 0 {
+		t.Errorf("Balance should be zeroed on a type mismatch, got %d", msg.Balance)
+	}
+	if msg.GetPhone() != "***" || msg.GetEmail() != "" {
+		t.Errorf("Oneof should be routed as a whole, got %v", msg.Contact)
+	}
+	if msg.Profile.Name != "John" || !reflect.DeepEqual(msg.Tokens, []string{"t1"}) {
+		t.Errorf("Fields returned as is should be kept, got %v", msg)
+	}
+}
+
+func TestCustomRedactorNop(t *testing.T) {
+	msg := &Account{Username: "john", Password: "secret"}
+	msg.Redact()
+
+	if msg.Username != "john" || msg.Password != "hidden" {
+		t.Errorf("Default redactor should keep the values, got %v", msg)
+	}
+}
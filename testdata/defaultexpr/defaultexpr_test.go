@@ -0,0 +1,35 @@
+package defaultexpr
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestDefaultExpressions(t *testing.T) {
+	msg := &Payment{
+		Card:      "4242",
+		Holder:    "Jane",
+		Amount:    42,
+		Signature: []byte("sig"),
+		Settled:   true,
+		Notes:     []string{"a", "b"},
+	}
+	msg.Redact()
+
+	if msg.Card != "hidden" {
+		t.Errorf("Card should keep its explicit value, got %q", msg.Card)
+	}
+	if msg.Holder != "[MASKED]" || msg.Notes[0] != "[MASKED]" || msg.Notes[1] != "[MASKED]" {
+		t.Errorf("Strings should be the mask placeholder, got %q and %q", msg.Holder, msg.Notes)
+	}
+	if msg.Amount != math.MinInt64 {
+		t.Errorf("Amount should be math.MinInt64, got %d", msg.Amount)
+	}
+	if !bytes.Equal(msg.Signature, []byte("REDACTED")) {
+		t.Errorf("Signature should be the redact marker, got %q", msg.Signature)
+	}
+	if msg.Settled {
+		t.Errorf("Settled should keep the predefined default")
+	}
+}
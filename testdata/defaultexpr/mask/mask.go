@@ -0,0 +1,7 @@
+// Package mask provides the redaction placeholders of the default expressions
+package mask
+
+// Placeholder returns the placeholder of the redacted strings
+func Placeholder() string {
+	return "[MASKED]"
+}
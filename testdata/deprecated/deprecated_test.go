@@ -0,0 +1,28 @@
+package deprecated
+
+import "testing"
+
+func TestRedactDeprecated(t *testing.T) {
+	msg := &Customer{
+		Name:          "Jane",
+		Ssn:           "123-45-6789",
+		LegacyToken:   "secret",
+		LegacyId:      "42",
+		LegacyAddress: &Address{Street: "Main St", City: "Springfield"},
+		LegacyEmails:  []string{"jane@example.com"},
+	}
+	msg.Redact()
+
+	if msg.Name != "Jane" || msg.LegacyId != "42" {
+		t.Errorf("Name and LegacyId should be kept, got %q and %q", msg.Name, msg.LegacyId)
+	}
+	if msg.Ssn != "REDACTED" || msg.LegacyToken != "hidden" {
+		t.Errorf("Deprecated fields should be redacted, got %q and %q", msg.Ssn, msg.LegacyToken)
+	}
+	if msg.LegacyAddress.Street != "hidden" || msg.LegacyAddress.City != "Springfield" {
+		t.Errorf("LegacyAddress should be redacted by its rules, got %v", msg.LegacyAddress)
+	}
+	if msg.LegacyEmails != nil {
+		t.Errorf("LegacyEmails should be nil, got %v", msg.LegacyEmails)
+	}
+}
@@ -0,0 +1,56 @@
+package depth
+
+import "testing"
+
+func newCustomer() *Customer {
+	return &Customer{
+		Name: "john",
+		Address: &Address{
+			Street: "main street",
+			Geo:    &Geo{Lat: "48.85"},
+		},
+	}
+}
+
+func TestDepthRedaction(t *testing.T) {
+	msg := &Order{
+		Customer: newCustomer(),
+		Full:     newCustomer(),
+		History:  []*Customer{newCustomer()},
+		Payer:    &Order_Primary{Primary: newCustomer()},
+	}
+	msg.Redact()
+
+	if msg.Customer.Name != "hidden" {
+		t.Errorf("Customer should be redacted, got %v", msg.Customer)
+	}
+	if msg.Customer.Address.Street != "main street" || msg.Customer.Address.Geo.Lat != "48.85" {
+		t.Errorf("Customer address should be left intact at depth 1, got %v", msg.Customer.Address)
+	}
+
+	full := msg.Full
+	if full.Name != "hidden" || full.Address.Street != "hidden" || full.Address.Geo.Lat != "hidden" {
+		t.Errorf("Full customer should be redacted down to the last level, got %v", full)
+	}
+
+	item := msg.History[0]
+	if item.Name != "hidden" || item.Address.Street != "hidden" {
+		t.Errorf("History customers should be redacted down to depth 2, got %v", item)
+	}
+	if item.Address.Geo.Lat != "48.85" {
+		t.Errorf("History geo should be left intact beyond depth 2, got %v", item.Address.Geo)
+	}
+
+	primary := msg.GetPrimary()
+	if primary.Name != "hidden" || primary.Address.Street != "main street" {
+		t.Errorf("Primary customer should be redacted at depth 1 only, got %v", primary)
+	}
+}
+
+func TestDepthRedactUnlimited(t *testing.T) {
+	msg := newCustomer()
+	msg.Redact()
+	if msg.Name != "hidden" || msg.Address.Street != "hidden" || msg.Address.Geo.Lat != "hidden" {
+		t.Errorf("Redact() should not limit the depth, got %v", msg)
+	}
+}
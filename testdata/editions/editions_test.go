@@ -0,0 +1,54 @@
+package editions
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/editions/implicit"
+)
+
+func TestEditionsRedaction(t *testing.T) {
+	msg := &Account{
+		Name:     proto.String("john"),
+		Balance:  proto.Int64(42),
+		Status:   Status_STATUS_UNSPECIFIED.Enum(),
+		Avatar:   []byte("avatar"),
+		Card:     proto.String("4111 1111 1111 1111"),
+		Implicit: "secret",
+		Tags:     []string{"a", "b"},
+		Profile: &implicit.Profile{
+			Email: "john@example.com",
+			Phone: proto.String("555-0100"),
+		},
+	}
+	msg.Redact()
+
+	if msg.GetName() != "hidden" || msg.GetBalance() != -1 || msg.GetStatus() != Status_STATUS_REDACTED {
+		t.Errorf("Explicit presence fields should be redacted, got %v", msg)
+	}
+	if string(msg.GetAvatar()) != "***" || msg.GetCard() != "**** **** **** 1111" {
+		t.Errorf("Avatar and card should be redacted, got %q and %q", msg.GetAvatar(), msg.GetCard())
+	}
+	if msg.GetImplicit() != "hidden" {
+		t.Errorf("Implicit presence field should be redacted, got %q", msg.GetImplicit())
+	}
+	if msg.GetTags()[0] != "hidden" || msg.GetTags()[1] != "hidden" {
+		t.Errorf("Tags should be redacted, got %v", msg.GetTags())
+	}
+	if msg.GetProfile().GetEmail() != "hidden" || msg.GetProfile().GetPhone() != "hidden" {
+		t.Errorf("Profile should be redacted, got %v", msg.GetProfile())
+	}
+}
+
+func TestEditionsUnsetFields(t *testing.T) {
+	msg := &Account{}
+	msg.Redact()
+
+	if msg.Card != nil || msg.Avatar != nil || msg.Profile != nil {
+		t.Errorf("Unset card, avatar and profile should stay unset, got %v", msg)
+	}
+	if msg.GetName() != "hidden" || msg.GetStatus() != Status_STATUS_REDACTED {
+		t.Errorf("Unset explicit presence fields should be set to the redaction values, got %v", msg)
+	}
+}
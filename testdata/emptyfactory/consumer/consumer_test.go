@@ -0,0 +1,16 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/emptyfactory"
+)
+
+func TestEmptyFactoryImported(t *testing.T) {
+	msg := &Profile{Config: &emptyfactory.Config{Region: "eu", Token: "secret"}}
+	msg.Redact()
+
+	if msg.Config.Region != "unknown" || msg.Config.Token != "" {
+		t.Errorf("Config should be the factory value of its package, got %v", msg.Config)
+	}
+}
@@ -0,0 +1,45 @@
+package emptyfactory
+
+import (
+	"context"
+	"testing"
+)
+
+type settingsServer struct {
+	UnimplementedSettingsServiceServer
+}
+
+func (settingsServer) GetSettings(context.Context, *GetSettingsRequest) (*Settings, error) {
+	return &Settings{Theme: "dark"}, nil
+}
+
+func TestEmptyFactoryFields(t *testing.T) {
+	msg := &Account{
+		Config:  &Config{Region: "eu", Token: "secret"},
+		History: []*Config{{Region: "us", Token: "secret"}},
+		Backup:  &Config{Region: "eu", Token: "secret"},
+	}
+	msg.Redact()
+
+	if msg.Config.Region != "unknown" || msg.Config.Token != "" {
+		t.Errorf("Config should be the factory value, got %v", msg.Config)
+	}
+	if msg.History[0].Region != "unknown" || msg.History[0] == msg.Config {
+		t.Errorf("History items should be new factory values, got %v", msg.History)
+	}
+	if msg.Backup.Region != "eu" || msg.Backup.Token != "hidden" {
+		t.Errorf("Backup should be redacted by its rules, got %v", msg.Backup)
+	}
+}
+
+func TestEmptyFactoryServer(t *testing.T) {
+	srv := RedactedSettingsServiceServer(settingsServer{}, nil)
+
+	res, err := srv.GetSettings(context.Background(), &GetSettingsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Theme != "default" {
+		t.Errorf("Response should be the factory value, got %v", res)
+	}
+}
@@ -0,0 +1,12 @@
+package emptyfactory
+
+// NewRedactedConfig returns the empty value of the redacted configs, keeping a
+// safe region
+func NewRedactedConfig() *Config {
+	return &Config{Region: "unknown"}
+}
+
+// NewRedactedSettings returns the empty value of the redacted settings
+func NewRedactedSettings() *Settings {
+	return &Settings{Theme: "default"}
+}
@@ -0,0 +1,33 @@
+package enumlast
+
+import (
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/enumlast/common"
+)
+
+func TestEnumLast(t *testing.T) {
+	previous := Account_STATUS_ACTIVE
+	msg := &Account{
+		Status:   Account_STATUS_ACTIVE,
+		Level:    common.Level_LEVEL_HIGH,
+		Levels:   []common.Level{common.Level_LEVEL_HIGH, common.Level_LEVEL_UNSPECIFIED},
+		Previous: &previous,
+	}
+	msg.Redact()
+
+	if msg.Status != Account_STATUS_REDACTED {
+		t.Errorf("Status should be redacted to the last value, got %v", msg.Status)
+	}
+	if msg.Level != common.Level_LEVEL_REDACTED {
+		t.Errorf("Level should be redacted to the highest-numbered value, got %v", msg.Level)
+	}
+	for i, level := range msg.Levels {
+		if level != common.Level_LEVEL_REDACTED {
+			t.Errorf("Levels[%d] should be redacted, got %v", i, level)
+		}
+	}
+	if msg.GetPrevious() != Account_STATUS_REDACTED {
+		t.Errorf("Previous should be redacted, got %v", msg.GetPrevious())
+	}
+}
@@ -0,0 +1,50 @@
+package errmessage
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type adminServer struct {
+	UnimplementedAdminServer
+}
+
+func TestEmptyErrMessage(t *testing.T) {
+	srv := RedactedAdminServer(adminServer{}, nil)
+
+	for name, call := range map[string]func(context.Context, *Request) (*Response, error){
+		"Empty": srv.Empty,
+		"Blank": srv.Blank,
+	} {
+		_, err := call(context.Background(), &Request{})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("%s should be denied, got %v", name, err)
+		}
+		want := `Permission Denied. Method: "AdminServer.` + name + `" has been redacted`
+		if got := status.Convert(err).Message(); got != want {
+			t.Errorf("%s should fall back to the default message %q, got %q", name, want, got)
+		}
+	}
+
+	_, err := srv.Custom(context.Background(), &Request{})
+	if got := status.Convert(err).Message(); got != "Custom is internal" {
+		t.Errorf("Custom should keep its message, got %q", got)
+	}
+
+	_, err = srv.Qualified(context.Background(), &Request{})
+	if got, want := status.Convert(err).Message(), "errmessage.Admin.Qualified of errmessage is %internal%"; got != want {
+		t.Errorf("Qualified should substitute the package and fqn %q, got %q", want, got)
+	}
+}
+
+func TestBacktickErrMessage(t *testing.T) {
+	srv := RedactedAdminServer(adminServer{}, nil)
+
+	_, err := srv.Backtick(context.Background(), &Request{})
+	if got := status.Convert(err).Message(); got != "`Backtick` is internal" {
+		t.Errorf("Backtick should keep the backticks of its message, got %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package except
+
+import "testing"
+
+func TestExceptRedaction(t *testing.T) {
+	msg := &Patient{
+		Id:        "p1",
+		CreatedAt: 1700000000,
+		Name:      "john",
+		BirthYear: 1980,
+		Diagnoses: []string{"flu"},
+		Contact:   &Contact{Phone: "555-0100"},
+		Note:      "note",
+		Ward:      "B",
+	}
+	msg.Redact()
+
+	if msg.Id != "p1" || msg.CreatedAt != 1700000000 || msg.Ward != "B" {
+		t.Errorf("Listed and allowed fields should be kept, got %v", msg)
+	}
+	if msg.Name != "REDACTED" || msg.BirthYear != 0 || msg.Diagnoses != nil {
+		t.Errorf("Other fields should be redacted with the defaults, got %v", msg)
+	}
+	if msg.Contact.Phone != "hidden" || msg.Note != "custom" {
+		t.Errorf("Rules should be applied, got %v", msg)
+	}
+}
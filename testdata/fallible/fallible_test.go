@@ -0,0 +1,49 @@
+package fallible
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+type walletsServer struct {
+	UnimplementedWalletsServer
+}
+
+func (walletsServer) GetWallet(context.Context, *GetWalletRequest) (*Wallet, error) {
+	return &Wallet{
+		Primary: &Card{Number: "4111111111111111"},
+		Cards:   []*Card{{Number: "5555555555554444"}},
+	}, nil
+}
+
+func TestFallibleRedact(t *testing.T) {
+	srv := RedactedWalletsServer(walletsServer{}, nil)
+	res, err := srv.GetWallet(context.Background(), &GetWalletRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Primary.Number != "****" || res.Cards[0].Number != "****" {
+		t.Errorf("cards should be redacted: %v", res)
+	}
+
+	var msg *Wallet
+	if err := msg.Redact(); err != nil {
+		t.Errorf("nil message should be redacted without error, got %v", err)
+	}
+}
+
+type failing struct{}
+
+func (failing) Redact() error { return errors.New("key unavailable") }
+
+func TestApplyError(t *testing.T) {
+	if err := redact.Apply(failing{}); err == nil {
+		t.Error("Apply should return the redaction error")
+	}
+	if err := redact.Apply(&Wallet{}); err != nil {
+		t.Errorf("Apply should succeed, got %v", err)
+	}
+}
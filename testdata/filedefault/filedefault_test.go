@@ -0,0 +1,41 @@
+package filedefault
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFileDefaultRedaction(t *testing.T) {
+	msg := &Contact{
+		Name:   "John Doe",
+		Email:  proto.String("john@example.com"),
+		Phones: []string{"555-0100"},
+		Age:    42,
+	}
+	msg.Redact()
+
+	if msg.Name != "[MASKED]" || msg.GetEmail() != "[MASKED]" {
+		t.Errorf("strings should be redacted to the file default, got %q and %q", msg.Name, msg.GetEmail())
+	}
+	if msg.Phones != nil {
+		t.Errorf("repeated strings should be cleared, got %v", msg.Phones)
+	}
+	if msg.Age != 0 {
+		t.Errorf("Age should keep the type default, got %d", msg.Age)
+	}
+}
+
+func TestFileDefaultOverride(t *testing.T) {
+	msg := &Login{User: "john", Hints: []string{"pet", "city"}}
+	msg.Redact()
+
+	if msg.User != "anonymous" {
+		t.Errorf("User should keep the explicit value, got %q", msg.User)
+	}
+	for i, hint := range msg.Hints {
+		if hint != "[MASKED]" {
+			t.Errorf("Hints[%d] should be redacted to the file default, got %q", i, hint)
+		}
+	}
+}
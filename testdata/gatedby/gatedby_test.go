@@ -0,0 +1,38 @@
+package gatedby
+
+import "testing"
+
+func TestGatedByRedaction(t *testing.T) {
+	kept := &Record{Id: "r1", Name: "john"}
+	kept.Redact()
+	if kept.Name != "john" {
+		t.Errorf("Record not flagged as sensitive should be kept, got %v", kept)
+	}
+
+	msg := &Record{Id: "r1", Name: "john", IsSensitive: true}
+	msg.Redact()
+	if msg.Name != "hidden" || msg.Id != "r1" {
+		t.Errorf("Record flagged as sensitive should be redacted, got %v", msg)
+	}
+	if !msg.IsSensitive {
+		t.Errorf("Gate should be kept, got %v", msg)
+	}
+}
+
+func TestGatedByOptional(t *testing.T) {
+	kept := &Profile{Email: "john@example.com", Record: &Record{Name: "john", IsSensitive: true}}
+	kept.Redact()
+	if kept.Email != "john@example.com" || kept.Record.Name != "john" {
+		t.Errorf("Profile with an unset gate should be kept, got %v", kept)
+	}
+
+	private := true
+	msg := &Profile{Email: "john@example.com", Private: &private, Record: &Record{Name: "john"}}
+	msg.Redact()
+	if msg.Email != "REDACTED" || !msg.GetPrivate() {
+		t.Errorf("Profile should be redacted but its gate, got %v", msg)
+	}
+	if msg.Record.Name != "john" {
+		t.Errorf("Nested record follows its own gate, got %v", msg.Record)
+	}
+}
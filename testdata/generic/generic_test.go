@@ -0,0 +1,82 @@
+package generic
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+type vault struct {
+	UnimplementedVaultServer
+}
+
+func (vault) GetSecret(_ context.Context, in *GetSecretRequest) (*Secret, error) {
+	return &Secret{Name: in.GetName(), Value: "hunter2"}, nil
+}
+
+// dial serves the redacted Vault service and returns a client connection to it
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterRedactedVaultServer(srv, vault{}, nil)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRedactorInterface(t *testing.T) {
+	var _ redact.Redactor = (*Secret)(nil)
+
+	msg := &Secret{Name: "db", Value: "hunter2"}
+	redact.Apply(msg)
+	if msg.Value != "REDACTED" {
+		t.Fatalf("redact.Apply did not redact the message: %v", msg)
+	}
+}
+
+func TestTypedClient(t *testing.T) {
+	res, err := NewVaultClient(dial(t)).GetSecret(context.Background(), &GetSecretRequest{Name: "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetValue() != "REDACTED" || res.GetName() != "db" {
+		t.Fatalf("unexpected response: %v", res)
+	}
+}
+
+func TestGenericClient(t *testing.T) {
+	// invoke by method name only, decoding into a message built from the
+	// descriptor, as reflection based tools (grpcurl, gateways) do
+	desc := File_testdata_generic_generic_proto.Services().ByName("Vault").Methods().ByName("GetSecret")
+	req := dynamicpb.NewMessage(desc.Input())
+	req.Set(desc.Input().Fields().ByName("name"), protoreflect.ValueOfString("db"))
+	res := dynamicpb.NewMessage(desc.Output())
+
+	if err := dial(t).Invoke(context.Background(), "/generic.Vault/GetSecret", req, res); err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Get(desc.Output().Fields().ByName("value")).String(); got != "REDACTED" {
+		t.Fatalf("generic client received unredacted value %q", got)
+	}
+}
@@ -0,0 +1,33 @@
+package ignoredembed
+
+import "testing"
+
+func TestIgnoredEmbedRedaction(t *testing.T) {
+	msg := &Account{
+		Password: "secret",
+		Audit:    &Audit{Actor: "admin"},
+		History:  []*Audit{{Actor: "root"}, nil},
+		Audits:   map[string]*Audit{"last": {Actor: "ops"}},
+		Source:   &Account_Origin{Origin: &Audit{Actor: "system"}},
+	}
+	msg.Redact()
+
+	if msg.Password != "hidden" {
+		t.Errorf("Password should be redacted, got %q", msg.Password)
+	}
+	if msg.Audit.Actor != "admin" || msg.History[0].Actor != "root" || msg.History[1] != nil {
+		t.Errorf("Ignored messages should be kept, got %v and %v", msg.Audit, msg.History)
+	}
+	if msg.Audits["last"].Actor != "ops" || msg.GetOrigin().Actor != "system" {
+		t.Errorf("Ignored messages should be kept, got %v and %v", msg.Audits, msg.GetOrigin())
+	}
+}
+
+func TestIgnoredEmbedNil(t *testing.T) {
+	msg := &Account{Password: "secret"}
+	msg.Redact()
+
+	if msg.Audit != nil || msg.History != nil || msg.Source != nil {
+		t.Errorf("Nil messages should be kept nil, got %v", msg)
+	}
+}
@@ -0,0 +1,43 @@
+package importedoutput
+
+import (
+	"context"
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/importedoutput/common"
+)
+
+type profilesServer struct {
+	UnimplementedProfilesServer
+}
+
+func (profilesServer) GetProfile(context.Context, *GetProfileRequest) (*common.Profile, error) {
+	return &common.Profile{Name: "John", Email: "john@example.com"}, nil
+}
+
+func (profilesServer) GetSession(context.Context, *GetProfileRequest) (*common.Session, error) {
+	return &common.Session{Token: "secret"}, nil
+}
+
+func TestImportedOutputRedaction(t *testing.T) {
+	srv := RedactedProfilesServer(profilesServer{}, nil)
+
+	profile, err := srv.GetProfile(context.Background(), &GetProfileRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Email != "hidden" {
+		t.Errorf("Email should be redacted by the imported Redact(), got %q", profile.Email)
+	}
+	if profile.Name != "John" {
+		t.Errorf("Name should be kept, got %q", profile.Name)
+	}
+
+	session, err := srv.GetSession(context.Background(), &GetProfileRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session == nil || session.Token != "" {
+		t.Errorf("Session should be emptied by its imported message option, got %v", session)
+	}
+}
@@ -0,0 +1,23 @@
+package importedredactor
+
+import (
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/importedredactor/common"
+)
+
+func TestImportedRedactor(t *testing.T) {
+	msg := &Account{
+		Profile: &common.Profile{Name: "John", Email: "john@example.com"},
+		Friends: []*common.Profile{{Email: "jane@example.com"}},
+		Audit:   &common.Audit{Actor: "admin"},
+	}
+	msg.Redact()
+
+	if msg.Profile.Email != "hidden" || msg.Friends[0].Email != "hidden" {
+		t.Errorf("Profiles should be redacted by the imported Redact(), got %v", msg)
+	}
+	if msg.Audit.Actor != "admin" {
+		t.Errorf("Audit is ignored and should be kept, got %q", msg.Audit.Actor)
+	}
+}
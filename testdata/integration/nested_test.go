@@ -0,0 +1,27 @@
+package testdata
+
+import "testing"
+
+func TestRedactNilNested(t *testing.T) {
+	msg := &ComplexMessage{
+		Users:   []*TestMessage{nil, {Addresses: []*Address{nil}, ProfilesMap: map[string]*Profile{"nil": nil}}},
+		UserMap: map[string]*TestMessage{"nil": nil},
+	}
+	msg.Redact()
+
+	if msg.Owner != nil || msg.Users[0] != nil || msg.UserMap["nil"] != nil {
+		t.Errorf("Nil nested messages should be kept nil, got %v", msg)
+	}
+	if msg.Users[1].Addresses[0] != nil || msg.Users[1].ProfilesMap["nil"] != nil {
+		t.Errorf("Nil items should be kept nil, got %v", msg.Users[1])
+	}
+}
+
+func TestRedactNested(t *testing.T) {
+	msg := &ComplexMessage{Owner: &Profile{Username: "john", Bio: "bio"}}
+	msg.Redact()
+
+	if msg.Owner.Username != "john" || msg.Owner.Bio != "[REDACTED BIO]" {
+		t.Errorf("Owner should be redacted by its own rules, got %v", msg.Owner)
+	}
+}
@@ -0,0 +1,26 @@
+package literals
+
+import "testing"
+
+func TestStringLiterals(t *testing.T) {
+	msg := &Secret{
+		Backtick:  "secret",
+		Raw:       []byte("secret"),
+		Multiline: "secret",
+		Items:     []string{"secret"},
+	}
+	msg.Redact()
+
+	if msg.Backtick != "a`b" {
+		t.Errorf("Backtick should be redacted, got %q", msg.Backtick)
+	}
+	if string(msg.Raw) != "c`d" {
+		t.Errorf("Raw should be redacted, got %q", msg.Raw)
+	}
+	if msg.Multiline != "line1\nline2" {
+		t.Errorf("Multiline should be redacted, got %q", msg.Multiline)
+	}
+	if msg.Items[0] != "`quoted`" {
+		t.Errorf("Items should be redacted, got %q", msg.Items)
+	}
+}
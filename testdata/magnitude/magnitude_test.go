@@ -0,0 +1,46 @@
+package magnitude
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMagnitudeRedaction(t *testing.T) {
+	msg := &Usage{
+		Requests:  12345,
+		Users:     7,
+		Balance:   -420,
+		BytesSent: proto.Uint64(1 << 40),
+		Latencies: []int64{0, 99, -1001},
+		Counters:  map[string]uint64{"a": 10, "b": 999999},
+		Quota:     &Usage_Daily{Daily: 250},
+	}
+	msg.Redact()
+
+	if msg.Requests != 10000 || msg.Users != 1 || msg.Balance != -100 {
+		t.Errorf("Integers should keep their order of magnitude, got %d, %d and %d", msg.Requests, msg.Users, msg.Balance)
+	}
+	if msg.GetBytesSent() != 1000000000000 {
+		t.Errorf("Optional fields should keep their order of magnitude, got %d", msg.GetBytesSent())
+	}
+	if want := []int64{0, 10, -1000}; !reflect.DeepEqual(msg.Latencies, want) {
+		t.Errorf("Latencies should be %v, got %v", want, msg.Latencies)
+	}
+	if want := map[string]uint64{"a": 10, "b": 100000}; !reflect.DeepEqual(msg.Counters, want) {
+		t.Errorf("Counters should be %v, got %v", want, msg.Counters)
+	}
+	if msg.GetDaily() != 100 {
+		t.Errorf("Daily should keep its order of magnitude, got %d", msg.GetDaily())
+	}
+}
+
+func TestMagnitudeUnsetFields(t *testing.T) {
+	msg := &Usage{Quota: &Usage_Plan{Plan: "pro"}}
+	msg.Redact()
+
+	if msg.BytesSent != nil || msg.GetPlan() != "pro" {
+		t.Errorf("Unset fields should stay unset, got %v and %q", msg.BytesSent, msg.GetPlan())
+	}
+}
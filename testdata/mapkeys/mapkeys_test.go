@@ -0,0 +1,40 @@
+package mapkeys
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapKeysRedaction(t *testing.T) {
+	msg := &Request{
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+			"X-Api-Key":     "key",
+		},
+		Balances: map[string]int64{"4111 1111 1111 1111": 42},
+		Sessions: map[string]*Session{"user-1": {Token: "t1"}},
+	}
+	msg.Redact()
+
+	if want := map[string]string{"***": "REDACTED"}; !reflect.DeepEqual(msg.Headers, want) {
+		t.Errorf("Headers should be merged under the redacted key %v, got %v", want, msg.Headers)
+	}
+	if want := map[string]int64{"**** **** **** 1111": 42}; !reflect.DeepEqual(msg.Balances, want) {
+		t.Errorf("Balances keys should be masked %v, got %v", want, msg.Balances)
+	}
+	if len(msg.Sessions) != 1 || msg.Sessions["session"].GetToken() != "t1" {
+		t.Errorf("Sessions values should be kept under the redacted key, got %v", msg.Sessions)
+	}
+}
+
+func TestMapKeysNilAndEmpty(t *testing.T) {
+	msg := &Request{Headers: map[string]string{}}
+	msg.Redact()
+
+	if msg.Headers == nil || len(msg.Headers) != 0 {
+		t.Errorf("Empty map should be kept empty, got %v", msg.Headers)
+	}
+	if msg.Balances != nil || msg.Sessions != nil {
+		t.Errorf("Nil maps should be kept nil, got %v and %v", msg.Balances, msg.Sessions)
+	}
+}
@@ -0,0 +1,50 @@
+package mapkeytypes
+
+import "testing"
+
+func TestMapKeyTypesRedaction(t *testing.T) {
+	item := &Item{Secret: "secret"}
+	msg := &Inventory{
+		ByName:     map[string]*Item{"a": item},
+		ByInt32:    map[int32]*Item{-1: item},
+		ByInt64:    map[int64]*Item{1 << 40: item},
+		ByUint32:   map[uint32]*Item{1: item},
+		ByUint64:   map[uint64]*Item{1: item},
+		BySint32:   map[int32]*Item{-1: item},
+		BySint64:   map[int64]*Item{-1: item},
+		ByFixed32:  map[uint32]*Item{1: item},
+		ByFixed64:  map[uint64]*Item{1: item},
+		BySfixed32: map[int32]*Item{-1: item},
+		BySfixed64: map[int64]*Item{-1: item},
+		ByFlag:     map[bool]*Item{true: item},
+		Labels:     map[int64]string{1: "label"},
+		Nested:     map[bool]*Item{false: {Secret: "secret"}, true: nil},
+	}
+	msg.Redact()
+
+	for name, size := range map[string]int{
+		"ByName":     len(msg.ByName),
+		"ByInt32":    len(msg.ByInt32),
+		"ByInt64":    len(msg.ByInt64),
+		"ByUint32":   len(msg.ByUint32),
+		"ByUint64":   len(msg.ByUint64),
+		"BySint32":   len(msg.BySint32),
+		"BySint64":   len(msg.BySint64),
+		"ByFixed32":  len(msg.ByFixed32),
+		"ByFixed64":  len(msg.ByFixed64),
+		"BySfixed32": len(msg.BySfixed32),
+		"BySfixed64": len(msg.BySfixed64),
+		"ByFlag":     len(msg.ByFlag),
+		"Labels":     len(msg.Labels),
+	} {
+		if size != 0 {
+			t.Errorf("%s should be emptied, got %d items", name, size)
+		}
+	}
+	if msg.ByFlag == nil || msg.Labels == nil {
+		t.Errorf("Emptied maps should not be nil")
+	}
+	if msg.Nested[false].Secret != "hidden" || msg.Nested[true] != nil {
+		t.Errorf("Nested items should be redacted, got %v", msg.Nested)
+	}
+}
@@ -0,0 +1,73 @@
+package mapnested
+
+import "testing"
+
+// newList returns an ItemList with sensitive values
+func newList() *ItemList {
+	return &ItemList{
+		Secrets: []string{"s1", "s2"},
+		Items:   []*Item{{Sku: "a", Owner: "john"}, nil},
+		BySku:   map[string]*Item{"b": {Sku: "b", Owner: "jane"}},
+		Tags:    []string{"tag"},
+	}
+}
+
+// checkList checks the sensitive values of the list are redacted
+func checkList(t *testing.T, name string, list *ItemList) {
+	t.Helper()
+
+	for i, secret := range list.Secrets {
+		if secret != "hidden" {
+			t.Errorf("%s.Secrets[%d] should be redacted, got %q", name, i, secret)
+		}
+	}
+	if list.Items[0].Owner != "hidden" || list.Items[0].Sku != "a" || list.Items[1] != nil {
+		t.Errorf("%s.Items should be redacted recursively, got %v", name, list.Items)
+	}
+	if list.BySku["b"].Owner != "hidden" {
+		t.Errorf("%s.BySku should be redacted recursively, got %v", name, list.BySku)
+	}
+	if list.Tags[0] != "tag" {
+		t.Errorf("%s.Tags should not be redacted, got %q", name, list.Tags)
+	}
+}
+
+func TestMapOfNestedRepeated(t *testing.T) {
+	msg := &Catalog{
+		Lists:    map[string]*ItemList{"x": newList(), "nil": nil},
+		Archived: map[int32]*ItemList{1: newList()},
+		Public:   map[string]*ItemList{"y": newList()},
+	}
+	msg.Redact()
+
+	checkList(t, "Lists", msg.Lists["x"])
+	if list, ok := msg.Lists["nil"]; !ok || list != nil {
+		t.Errorf("nil map values should be kept, got %v", list)
+	}
+	checkList(t, "Archived", msg.Archived[1])
+	if msg.Public["y"].Secrets[0] != "s1" {
+		t.Errorf("Public should not be redacted, got %v", msg.Public["y"])
+	}
+}
+
+func TestMapOfCompositeKeys(t *testing.T) {
+	msg := &Ledger{
+		Entries: map[string]*Entry{
+			"k1":     {Key: &EntryKey{Account: "acc-1", Region: "eu"}, Amount: 10},
+			"nokey":  {Amount: 20},
+			"nilval": nil,
+		},
+	}
+	msg.Redact()
+
+	entry := msg.Entries["k1"]
+	if entry.Key.Account != "hidden" || entry.Key.Region != "eu" || entry.Amount != 10 {
+		t.Errorf("Entry keys should be redacted through the map, got %v", entry)
+	}
+	if msg.Entries["nokey"].Key != nil || msg.Entries["nokey"].Amount != 20 {
+		t.Errorf("Entries without key should be kept, got %v", msg.Entries["nokey"])
+	}
+	if entry, ok := msg.Entries["nilval"]; !ok || entry != nil {
+		t.Errorf("nil map values should be kept, got %v", entry)
+	}
+}
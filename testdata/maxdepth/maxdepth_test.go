@@ -0,0 +1,34 @@
+package maxdepth
+
+import "testing"
+
+func TestMaxDepthRedaction(t *testing.T) {
+	msg := &Level0{
+		Secret: "s0",
+		Next: &Level1{
+			Secret: "s1",
+			Next: &Level2{
+				Secret: "s2",
+				Next:   &Level3{Secret: "s3", Next: &Level4{Secret: "s4"}},
+				Items:  []*Level3{{Secret: "s3"}},
+			},
+		},
+	}
+	msg.Redact()
+
+	if msg.Secret != "hidden" || msg.Next.Secret != "hidden" || msg.Next.Next.Secret != "hidden" {
+		t.Errorf("Levels within the max depth should be redacted, got %v", msg)
+	}
+	if msg.Next.Next.Next != nil || msg.Next.Next.Items != nil {
+		t.Errorf("Levels beyond the max depth should be redacted to nil, got %v", msg.Next.Next)
+	}
+}
+
+func TestMaxDepthRecursion(t *testing.T) {
+	msg := &Node{Secret: "s0", Child: &Node{Secret: "s1"}}
+	msg.Redact()
+
+	if msg.Secret != "hidden" || msg.Child != nil {
+		t.Errorf("Recursive redaction should be cut, got %v", msg)
+	}
+}
@@ -0,0 +1,36 @@
+package maxlen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxFieldLen(t *testing.T) {
+	note := strings.Repeat("n", 100)
+	msg := &Event{
+		Message: strings.Repeat("m", 100),
+		Payload: make([]byte, 100),
+		Tags:    []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"},
+		Labels:  map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6", "g": "7", "h": "8", "i": "9"},
+		Note:    &note,
+		Secret:  "password",
+		Source:  &Event_Host{Host: strings.Repeat("h", 100)},
+	}
+	msg.Redact()
+
+	if len(msg.Message) != 8 || len(msg.Payload) != 8 || len(msg.Tags) != 8 || len(msg.Labels) != 8 || len(*msg.Note) != 8 {
+		t.Errorf("fields should be capped to 8: %v", msg)
+	}
+	if msg.Secret != "hidden" {
+		t.Errorf("Secret should be redacted, got %q", msg.Secret)
+	}
+}
+
+func TestMaxFieldLenMultiByte(t *testing.T) {
+	msg := &Event{Message: strings.Repeat("é", 10)}
+	msg.Redact()
+
+	if msg.Message != "éééé" {
+		t.Errorf("Message should be capped on a character boundary, got %q", msg.Message)
+	}
+}
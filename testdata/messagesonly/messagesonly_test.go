@@ -0,0 +1,12 @@
+package messagesonly
+
+import "testing"
+
+func TestMessagesOnlyRedaction(t *testing.T) {
+	msg := &Credentials{Username: "john", Password: "secret"}
+	msg.Redact()
+
+	if msg.Username != "john" || msg.Password != "hidden" {
+		t.Errorf("Password should be redacted, got %v", msg)
+	}
+}
@@ -0,0 +1,18 @@
+package noopnested
+
+import "testing"
+
+func TestIgnoredEmbeddedMessage(t *testing.T) {
+	msg := &Order{
+		Payment: &Payment{Card: "4111"},
+		Audit:   &Audit{Actor: "john"},
+	}
+	msg.Redact()
+
+	if msg.Payment.Card != "****" {
+		t.Errorf("Payment should be redacted, got %q", msg.Payment.Card)
+	}
+	if msg.Audit.Actor != "john" {
+		t.Errorf("Audit is ignored and should be kept, got %q", msg.Audit.Actor)
+	}
+}
@@ -0,0 +1,67 @@
+package oneofoptional
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOneOfOptionalFields(t *testing.T) {
+	msg := &Outer{V: &Outer_Inner{Inner: &Inner{
+		Token: proto.String("secret"),
+		Pin:   proto.Int32(1234),
+		Blob:  []byte("secret"),
+		Note:  proto.String("note"),
+		W:     &Inner_Leaf{Leaf: &Leaf{Code: proto.Int64(42), Flag: proto.Bool(true)}},
+	}}}
+	msg.Redact()
+
+	inner := msg.GetInner()
+	if inner.GetToken() != "hidden" || inner.GetPin() != -1 || string(inner.GetBlob()) != "blob" {
+		t.Errorf("Inner optional fields should be redacted, got %v", inner)
+	}
+	if inner.GetNote() != "note" {
+		t.Errorf("Note should not be redacted, got %q", inner.GetNote())
+	}
+	leaf := inner.GetLeaf()
+	if leaf.Code == nil || leaf.GetCode() != 0 || leaf.Flag == nil || leaf.GetFlag() {
+		t.Errorf("Leaf optional fields should be redacted keeping their presence, got %v", leaf)
+	}
+}
+
+func TestOneOfOptionalFieldsUnset(t *testing.T) {
+	msg := &Outer{V: &Outer_Inner{Inner: &Inner{}}}
+	msg.Redact()
+
+	inner := msg.GetInner()
+	// optional scalars are always set to the redaction value, hiding whether
+	// they were set, but the optional bytes are only redacted when set
+	if inner.Token == nil || inner.GetToken() != "hidden" || inner.Pin == nil || inner.GetPin() != -1 {
+		t.Errorf("Inner optional scalars should be set to the redaction value, got %v", inner)
+	}
+	if inner.Blob != nil {
+		t.Errorf("Blob should be kept unset, got %q", inner.Blob)
+	}
+	if inner.Note != nil || inner.W != nil {
+		t.Errorf("Note and W should be kept unset, got %v", inner)
+	}
+}
+
+func TestOneOfOptionalBranches(t *testing.T) {
+	for _, msg := range []*Outer{
+		{},
+		{V: &Outer_Name{Name: "name"}},
+		{V: &Outer_Inner{}},
+		{V: &Outer_Inner{Inner: &Inner{W: &Inner_Leaf{}}}},
+	} {
+		want := proto.Clone(msg).(*Outer)
+		if inner := want.GetInner(); msg.GetInner() != nil {
+			inner.Token, inner.Pin = proto.String("hidden"), proto.Int32(-1)
+		}
+		msg.Redact()
+
+		if !proto.Equal(msg, want) {
+			t.Errorf("Unset branches should be kept, got %v, want %v", msg, want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package optionalbytes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnsetOptionalBytesStayUnset(t *testing.T) {
+	msg := &Envelope{}
+	msg.Redact()
+
+	if msg.Signature != nil || msg.Checksum != nil {
+		t.Fatalf("unset optional bytes became set: %v", msg)
+	}
+	if !bytes.Equal(msg.Payload, []byte("payload")) {
+		t.Fatalf("non-optional bytes not redacted: %v", msg)
+	}
+}
+
+func TestSetOptionalBytesAreRedacted(t *testing.T) {
+	msg := &Envelope{Signature: []byte("secret"), Checksum: []byte("secret")}
+	msg.Redact()
+
+	if !bytes.Equal(msg.Signature, []byte("sig")) {
+		t.Fatalf("signature not redacted: %q", msg.Signature)
+	}
+	if msg.Checksum == nil || len(msg.Checksum) != 0 {
+		t.Fatalf("checksum should be set and empty: %q", msg.Checksum)
+	}
+}
@@ -0,0 +1,49 @@
+package optionalenum
+
+import (
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/optionalenum/common"
+)
+
+func TestOptionalEnumRedaction(t *testing.T) {
+	active, high := Status_STATUS_ACTIVE, common.Level_LEVEL_HIGH
+	msg := &Account{
+		Status:   &active,
+		Previous: &active,
+		Next:     &active,
+		Last:     &active,
+		Current:  Status_STATUS_ACTIVE,
+		Level:    &high,
+	}
+	msg.Redact()
+
+	if msg.Status == nil || *msg.Status != Status_STATUS_REDACTED {
+		t.Errorf("Status should be redacted, got %v", msg.Status)
+	}
+	if msg.Previous == nil || *msg.Previous != Status_STATUS_UNSPECIFIED {
+		t.Errorf("Previous should be redacted to the zero value, keeping its presence, got %v", msg.Previous)
+	}
+	if msg.Next != &active || active != Status_STATUS_ACTIVE {
+		t.Errorf("Next should be kept, without changing the shared value, got %v", msg.Next)
+	}
+	if msg.Last == nil || *msg.Last != Status_STATUS_REDACTED {
+		t.Errorf("Last should be redacted to the last value, got %v", msg.Last)
+	}
+	if msg.Current != Status_STATUS_REDACTED || msg.Level == nil || *msg.Level != common.Level_LEVEL_UNSPECIFIED {
+		t.Errorf("Current and Level should be redacted, got %v", msg)
+	}
+	if msg.Status == msg.Last {
+		t.Errorf("Redacted fields should not share their value")
+	}
+}
+
+func TestOptionalEnumDefault(t *testing.T) {
+	active := Status_STATUS_ACTIVE
+	secret := &Secret{Status: &active}
+	secret.Redact()
+
+	if secret.Status == nil || *secret.Status != Status_STATUS_UNSPECIFIED || active != Status_STATUS_ACTIVE {
+		t.Errorf("Status should be redacted to the default, got %v", secret.Status)
+	}
+}
@@ -0,0 +1,12 @@
+package ownerskip
+
+import "testing"
+
+func TestOwnerSkipRedaction(t *testing.T) {
+	msg := &Contact{Email: "john@example.com"}
+	msg.Redact()
+
+	if msg.Email != "REDACTED" {
+		t.Errorf("Email should be redacted to the placeholder, got %q", msg.Email)
+	}
+}
@@ -0,0 +1,39 @@
+package panmask
+
+import "testing"
+
+func TestPANMask(t *testing.T) {
+	backup := "5500 0000 0000 0004"
+	msg := &Payment{
+		Card:       "4111-1111-1111-1111",
+		BackupCard: &backup,
+		Cards:      []string{"4012888888881881", "not a card", ""},
+		Holder:     "John Doe",
+	}
+	msg.Redact()
+
+	if msg.Card != "****-****-****-1111" {
+		t.Errorf("Card should keep the last four digits, got %q", msg.Card)
+	}
+	if *msg.BackupCard != "**** **** **** 0004" {
+		t.Errorf("BackupCard should keep the separators, got %q", *msg.BackupCard)
+	}
+	want := []string{"************1881", "**********", ""}
+	for i := range want {
+		if msg.Cards[i] != want[i] {
+			t.Errorf("Cards[%d] should be %q, got %q", i, want[i], msg.Cards[i])
+		}
+	}
+	if msg.Holder != "John Doe" {
+		t.Errorf("Holder should not be redacted, got %q", msg.Holder)
+	}
+}
+
+func TestPANMaskInvalid(t *testing.T) {
+	msg := &Payment{Card: "4111-1111-1111-1112"}
+	msg.Redact()
+
+	if msg.Card != "*******************" {
+		t.Errorf("Card failing the Luhn check should be fully masked, got %q", msg.Card)
+	}
+}
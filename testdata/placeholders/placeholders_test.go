@@ -0,0 +1,26 @@
+package placeholders
+
+import "testing"
+
+func TestDefaultPlaceholders(t *testing.T) {
+	msg := &Contact{Emails: []string{"a@b.c"}, Scores: map[string]int64{"x": 10}}
+	msg.Redact()
+
+	if msg.Emails[0] != "REDACTED" || msg.Scores["x"] != 0 {
+		t.Fatalf("unexpected redaction: %v", msg)
+	}
+}
+
+func TestReassignedPlaceholders(t *testing.T) {
+	defer func(s string, i int64) { RedactedString, RedactedInt64 = s, i }(RedactedString, RedactedInt64)
+	RedactedString, RedactedInt64 = "[MASKED]", -1
+
+	msg := &Contact{Emails: []string{"a@b.c"}, Scores: map[string]int64{"x": 10}}
+	msg.Redact()
+	note := &Note{Lines: []string{"secret"}}
+	note.Redact()
+
+	if msg.Emails[0] != "[MASKED]" || msg.Scores["x"] != -1 || note.Lines[0] != "[MASKED]" {
+		t.Fatalf("reassigned placeholders not used: %v %v", msg, note)
+	}
+}
@@ -0,0 +1,50 @@
+package proof
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// proof is the payload recorded by the sink
+type proof struct {
+	typeName   string
+	fieldPaths []string
+	at         time.Time
+}
+
+func TestRecordProof(t *testing.T) {
+	var proofs []proof
+	redact.SetProofSink(redact.ProofSinkFunc(func(typeName string, fieldPaths []string, at time.Time) {
+		proofs = append(proofs, proof{typeName: typeName, fieldPaths: fieldPaths, at: at})
+	}))
+	defer redact.SetProofSink(nil)
+
+	before := time.Now()
+	msg := &Account{Username: "john", Password: "secret", Profile: &Profile{Name: "John"}}
+	msg.Redact()
+
+	if len(proofs) != 1 {
+		t.Fatalf("Only the redacted message should record a proof, got %v", proofs)
+	}
+	if proofs[0].typeName != "proof.Account" {
+		t.Errorf("Type name should be the full proto name, got %q", proofs[0].typeName)
+	}
+	if want := []string{"password", "tokens", "profile"}; !reflect.DeepEqual(proofs[0].fieldPaths, want) {
+		t.Errorf("Field paths should be %v, got %v", want, proofs[0].fieldPaths)
+	}
+	if proofs[0].at.Before(before) || proofs[0].at.After(time.Now()) {
+		t.Errorf("Proof should be recorded at the redaction time, got %v", proofs[0].at)
+	}
+}
+
+func TestRecordProofNop(t *testing.T) {
+	msg := &Account{Password: "secret"}
+	msg.Redact()
+
+	if msg.Password != "hidden" {
+		t.Errorf("Password should be redacted with the default sink, got %q", msg.Password)
+	}
+}
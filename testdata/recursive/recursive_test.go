@@ -0,0 +1,70 @@
+package recursive
+
+import "testing"
+
+// chain builds a chain of nodes ending with the leaf
+func chain(depth int, leaf isNode_V) *Node {
+	root := &Node{Name: "root", V: leaf}
+	for i := 0; i < depth; i++ {
+		root = &Node{Name: "node", V: &Node_Child{Child: root}}
+	}
+	return root
+}
+
+func TestRecursiveOneOf(t *testing.T) {
+	const depth = 100
+	root := chain(depth, &Node_Leaf{Leaf: 42})
+	root.Redact()
+
+	node := root
+	for i := 0; i < depth; i++ {
+		if node.Name != "hidden" {
+			t.Fatalf("Name at depth %d should be redacted, got %q", i, node.Name)
+		}
+		child, ok := node.V.(*Node_Child)
+		if !ok {
+			t.Fatalf("V at depth %d should be kept a child, got %T", i, node.V)
+		}
+		node = child.Child
+	}
+	if node.Name != "hidden" || node.GetLeaf() != -1 {
+		t.Errorf("Leaf node should be redacted, got %v", node)
+	}
+}
+
+func TestRecursiveOneOfBranches(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		v    isNode_V
+		want isNode_V
+	}{
+		{name: "card", v: &Node_Card{Card: "4111 1111 1111 1111"}, want: &Node_Card{Card: "**** **** **** 1111"}},
+		{name: "label", v: &Node_Label{Label: "safe"}, want: &Node_Label{Label: "safe"}},
+		{name: "unset", v: nil, want: nil},
+		{name: "nil child", v: &Node_Child{}, want: &Node_Child{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			node := &Node{V: test.v}
+			node.Redact()
+
+			switch want := test.want.(type) {
+			case *Node_Card:
+				if node.GetCard() != want.Card {
+					t.Errorf("Card should be masked, got %q", node.GetCard())
+				}
+			case *Node_Label:
+				if node.GetLabel() != want.Label {
+					t.Errorf("Label should not be redacted, got %q", node.GetLabel())
+				}
+			case *Node_Child:
+				if node.GetChild() != nil {
+					t.Errorf("Child should be kept nil, got %v", node.GetChild())
+				}
+			default:
+				if node.V != nil {
+					t.Errorf("V should be kept unset, got %T", node.V)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package redactedfields
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactedFields(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"Account", (&Account{}).RedactedFields(), []string{"password", "profile", "pin"}},
+		{"Profile", (&Profile{}).RedactedFields(), []string{"email"}},
+		{"Audit", (&Audit{}).RedactedFields(), []string{}},
+		{"Token", (&Token{}).RedactedFields(), []string{}},
+		{"nil", (*Account)(nil).RedactedFields(), []string{"password", "profile", "pin"}},
+	} {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s should redact %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+}
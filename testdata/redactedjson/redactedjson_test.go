@@ -0,0 +1,41 @@
+package redactedjson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactedJSON(t *testing.T) {
+	msg := &Account{UserName: "john", Password: "secret", DisplayName: "John"}
+
+	data, err := msg.RedactedJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Should marshal valid JSON, got %s: %v", data, err)
+	}
+	want := map[string]interface{}{"login": "john", "secret": "hidden", "displayName": "John"}
+	if len(got) != len(want) {
+		t.Errorf("Should marshal %v, got %s", want, data)
+	}
+	for key, val := range want {
+		if got[key] != val {
+			t.Errorf("Should marshal %q as %v with its JSON name, got %s", key, val, data)
+		}
+	}
+	if msg.Password != "secret" {
+		t.Errorf("Message should not be modified, got %v", msg)
+	}
+}
+
+func TestRedactedJSONNil(t *testing.T) {
+	var msg *Account
+	if data, err := msg.RedactedJSON(); err != nil || string(data) != "null" {
+		t.Errorf("Nil message should marshal to null, got %s, %v", data, err)
+	}
+	if data, err := (&Session{Token: "secret"}).RedactedJSON(); err != nil || string(data) != "null" {
+		t.Errorf("Message redacted to nil should marshal to null, got %s, %v", data, err)
+	}
+}
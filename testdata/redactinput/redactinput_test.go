@@ -0,0 +1,57 @@
+package redactinput
+
+import (
+	"context"
+	"testing"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+// authServer echoes the password of the request received by the handler
+type authServer struct {
+	UnimplementedAuthServer
+}
+
+func (authServer) Login(_ context.Context, in *LoginRequest) (*LoginResponse, error) {
+	return &LoginResponse{Password: in.Password}, nil
+}
+
+func (authServer) LoginInternal(_ context.Context, in *LoginRequest) (*LoginResponse, error) {
+	return &LoginResponse{Password: in.Password}, nil
+}
+
+func (authServer) Check(_ context.Context, in *LoginRequest) (*LoginResponse, error) {
+	return &LoginResponse{Password: in.Password}, nil
+}
+
+func TestRedactInput(t *testing.T) {
+	internal := redact.Wrapper(func(context.Context) bool { return true })
+	for name, srv := range map[string]AuthServer{
+		"external": RedactedAuthServer(authServer{}, nil),
+		"internal": RedactedAuthServer(authServer{}, internal),
+	} {
+		res, err := srv.Login(context.Background(), &LoginRequest{Username: "john", Password: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Password != "hidden" {
+			t.Errorf("%s: handler should receive the redacted request, got %q", name, res.Password)
+		}
+
+		res, err = srv.Check(context.Background(), &LoginRequest{Username: "john", Password: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Password != "secret" {
+			t.Errorf("%s: handler should receive the request as is by default, got %q", name, res.Password)
+		}
+	}
+
+	res, err := RedactedAuthServer(authServer{}, internal).LoginInternal(context.Background(), &LoginRequest{Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Password != "hidden" {
+		t.Errorf("Internal handler should receive the redacted request, got %q", res.Password)
+	}
+}
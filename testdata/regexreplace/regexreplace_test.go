@@ -0,0 +1,37 @@
+package regexreplace
+
+import "testing"
+
+func TestRegexReplaceRedaction(t *testing.T) {
+	email := "john@example.com"
+	msg := &Ticket{
+		Body:    "card 4111 expires 12/30",
+		Email:   &email,
+		Notes:   []string{"call 555-0100 today"},
+		Tags:    map[string]string{"a": "user=john"},
+		Contact: &Ticket_Phone{Phone: "+1 555"},
+	}
+	msg.Redact()
+
+	if msg.Body != "card #### expires ##/##" {
+		t.Errorf("Body digits should be replaced, got %q", msg.Body)
+	}
+	if msg.GetEmail() != "***@example.com" {
+		t.Errorf("Email user should be replaced, got %q", msg.GetEmail())
+	}
+	if msg.Notes[0] != "call <phone> today" {
+		t.Errorf("Notes phone numbers should be replaced, got %q", msg.Notes[0])
+	}
+	if msg.Tags["a"] != "user=*" {
+		t.Errorf("Tags values should be replaced with the submatches, got %q", msg.Tags["a"])
+	}
+	if msg.GetPhone() != "+0 000" {
+		t.Errorf("Phone digits should be replaced, got %q", msg.GetPhone())
+	}
+
+	empty := &Ticket{}
+	empty.Redact()
+	if empty.Email != nil {
+		t.Errorf("Unset optional field should be kept unset, got %q", *empty.Email)
+	}
+}
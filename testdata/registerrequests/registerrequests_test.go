@@ -0,0 +1,28 @@
+package registerrequests
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+)
+
+func TestRedactRequest(t *testing.T) {
+	req := &LoginRequest{Username: "john", Password: "secret"}
+	logged, ok := redact.RedactRequest(context.Background(), req).(*LoginRequest)
+	if !ok {
+		t.Fatalf("RedactRequest should return a *LoginRequest")
+	}
+
+	if logged == req || logged.Password != "hidden" || logged.Username != "john" {
+		t.Errorf("the logged copy should be redacted, got %v", logged)
+	}
+	if req.Password != "secret" {
+		t.Errorf("the request should be kept for the handler, got %q", req.Password)
+	}
+	if _, ok := redact.RedactRequest(context.Background(), &emptypb.Empty{}).(*emptypb.Empty); !ok {
+		t.Errorf("RedactRequest should return a *emptypb.Empty")
+	}
+}
@@ -0,0 +1,48 @@
+package repeatedbytes
+
+import (
+	"testing"
+)
+
+func TestRepeatedBytesRedaction(t *testing.T) {
+	items := func() [][]byte { return [][]byte{[]byte("a"), []byte("b")} }
+	msg := &Blobs{
+		Blobs:   items(),
+		Masked:  items(),
+		Emptied: items(),
+		Cleared: items(),
+		Dropped: items(),
+		Files:   map[string][]byte{"a.txt": []byte("a")},
+	}
+	msg.Redact()
+
+	for name, field := range map[string][][]byte{"Blobs": msg.Blobs, "Cleared": msg.Cleared} {
+		if len(field) != 2 {
+			t.Fatalf("%s should keep its length, got %d", name, len(field))
+		}
+		for i, item := range field {
+			if item != nil {
+				t.Errorf("%s[%d] should be nil, got %q", name, i, item)
+			}
+		}
+	}
+	for i, item := range msg.Masked {
+		if string(item) != "***" {
+			t.Errorf("Masked[%d] should be redacted, got %q", i, item)
+		}
+	}
+	for i, item := range msg.Emptied {
+		if len(item) != 0 {
+			t.Errorf("Emptied[%d] should be empty, got %q", i, item)
+		}
+	}
+	if len(msg.Masked) != 2 || len(msg.Emptied) != 2 {
+		t.Errorf("Item rules should keep the length, got %d and %d", len(msg.Masked), len(msg.Emptied))
+	}
+	if len(msg.Dropped) != 0 {
+		t.Errorf("Dropped should be emptied, got %q", msg.Dropped)
+	}
+	if file, ok := msg.Files["a.txt"]; !ok || file != nil {
+		t.Errorf("Files should keep their keys with nil values, got %q", msg.Files)
+	}
+}
@@ -0,0 +1,50 @@
+package resetcopy
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestResetAndCopy(t *testing.T) {
+	msg := &Account{
+		Id:       "42",
+		Password: "hunter2",
+		Settings: &Settings{Theme: "dark", Token: "abc"},
+		Secrets:  &Settings{Theme: "light", Token: "xyz"},
+		Notes:    "sensitive",
+	}
+	msg.Redact()
+
+	want := &Account{
+		Id:       "42",
+		Password: "***",
+		Settings: &Settings{Theme: "dark", Token: "abc"},
+		Secrets:  &Settings{Theme: "light", Token: "***"},
+	}
+	if !proto.Equal(msg, want) {
+		t.Errorf("unexpected redaction:\n got: %v\nwant: %v", msg, want)
+	}
+}
+
+func TestResetAndCopyOneof(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		channel isContact_Channel
+		want    isContact_Channel
+	}{
+		{"allowed", &Contact_Email{Email: "john@example.com"}, &Contact_Email{Email: "john@example.com"}},
+		{"redacted", &Contact_Phone{Phone: "555-0100"}, &Contact_Phone{Phone: "***"}},
+		{"dropped", &Contact_Fax{Fax: "555-0199"}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Contact{Id: "42", Channel: tt.channel}
+			msg.Redact()
+
+			want := &Contact{Id: "42", Channel: tt.want}
+			if !proto.Equal(msg, want) {
+				t.Errorf("got %v, want %v", msg, want)
+			}
+		})
+	}
+}
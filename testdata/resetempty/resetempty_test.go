@@ -0,0 +1,37 @@
+package resetempty
+
+import "testing"
+
+func TestResetEmptyRedaction(t *testing.T) {
+	primary := &Config{Secret: "p"}
+	replica := &Config{Secret: "r"}
+	msg := &Deployment{Primary: primary, Replicas: []*Config{replica}}
+	msg.Redact()
+
+	if msg.Primary != primary || primary.GetSecret() != "" {
+		t.Errorf("Primary should be reset in place, got %p with %q", msg.Primary, msg.Primary.GetSecret())
+	}
+	if msg.Fallback == nil || msg.Fallback.GetSecret() != "" {
+		t.Errorf("Fallback should be allocated empty, got %v", msg.Fallback)
+	}
+	if msg.Replicas[0] == replica || msg.Replicas[0].GetSecret() != "" {
+		t.Errorf("Replicas should be replaced by empty values, got %p", msg.Replicas[0])
+	}
+}
+
+func TestResetEmptyOneof(t *testing.T) {
+	override := &Config{Secret: "o"}
+	msg := &Deployment{Target: &Deployment_Override{Override: override}}
+	msg.Redact()
+
+	if msg.GetOverride() != override || override.GetSecret() != "" {
+		t.Errorf("Override should be reset in place, got %p with %q", msg.GetOverride(), msg.GetOverride().GetSecret())
+	}
+
+	msg = &Deployment{Target: &Deployment_Override{}}
+	msg.Redact()
+
+	if msg.GetOverride() == nil {
+		t.Errorf("Override should be allocated empty")
+	}
+}
@@ -0,0 +1,56 @@
+package rounding
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRoundingRedaction(t *testing.T) {
+	msg := &Payment{
+		Amount:  -1999,
+		Count:   47,
+		Delta:   250,
+		Score:   12.34,
+		Ratio:   0.8,
+		Fee:     proto.Int64(1234),
+		Rate:    proto.Float64(0.37),
+		Totals:  []uint64{999, 1500, 20001},
+		Weights: map[string]float32{"a": 2.7, "b": -1.5},
+		Limit:   &Payment_Daily{Daily: 125},
+	}
+	msg.Redact()
+
+	if msg.Amount != -1000 || msg.Count != 40 || msg.Delta != 200 {
+		t.Errorf("Integers should be truncated to the factor, got %d, %d and %d", msg.Amount, msg.Count, msg.Delta)
+	}
+	if msg.Score != 12 || msg.Ratio != 0.75 {
+		t.Errorf("Floats should be truncated to the factor, got %v and %v", msg.Score, msg.Ratio)
+	}
+	if msg.GetFee() != 1200 || math.Abs(msg.GetRate()-0.3) > 1e-9 {
+		t.Errorf("Optional fields should be truncated, got %v and %v", msg.GetFee(), msg.GetRate())
+	}
+	if want := []uint64{0, 1000, 20000}; !reflect.DeepEqual(msg.Totals, want) {
+		t.Errorf("Totals should be %v, got %v", want, msg.Totals)
+	}
+	if want := map[string]float32{"a": 2, "b": -1}; !reflect.DeepEqual(msg.Weights, want) {
+		t.Errorf("Weights should be %v, got %v", want, msg.Weights)
+	}
+	if msg.GetDaily() != 100 {
+		t.Errorf("Daily should be truncated, got %d", msg.GetDaily())
+	}
+}
+
+func TestRoundingUnsetFields(t *testing.T) {
+	msg := &Payment{Limit: &Payment_Label{Label: "label"}}
+	msg.Redact()
+
+	if msg.Fee != nil || msg.Rate != nil {
+		t.Errorf("Unset optional fields should stay unset, got %v and %v", msg.Fee, msg.Rate)
+	}
+	if msg.GetLabel() != "label" {
+		t.Errorf("Label should be kept, got %q", msg.GetLabel())
+	}
+}
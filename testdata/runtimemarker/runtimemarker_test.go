@@ -0,0 +1,29 @@
+package runtimemarker
+
+import "testing"
+
+func TestRuntimeMarkerRedaction(t *testing.T) {
+	msg := &Contact{Emails: []string{"a@example.com"}, Password: "secret"}
+	msg.Redact()
+
+	if msg.Emails[0] != "REDACTED" || msg.Password != "hidden" {
+		t.Errorf("Should redact to the default marker, got %v", msg)
+	}
+}
+
+func TestRuntimeMarkerOverride(t *testing.T) {
+	defer func(marker string) { RedactedStringValue = marker }(RedactedStringValue)
+	RedactedStringValue = "[hidden]"
+
+	msg := &Contact{Emails: []string{"a@example.com"}, Password: "secret"}
+	msg.Redact()
+	secret := &Secret{Name: "john", Balance: 42}
+	secret.Redact()
+
+	if msg.Emails[0] != "[hidden]" || secret.Name != "[hidden]" {
+		t.Errorf("Should redact to the runtime marker, got %v and %v", msg, secret)
+	}
+	if msg.Password != "hidden" || secret.Balance != 0 {
+		t.Errorf("Explicit values and other types should be kept, got %v and %v", msg, secret)
+	}
+}
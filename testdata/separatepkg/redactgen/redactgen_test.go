@@ -0,0 +1,105 @@
+package redactgen
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/crosspkg/common"
+	"github.com/menta2k/protoc-gen-redact/v3/testdata/separatepkg"
+)
+
+func newAccount() *separatepkg.Account {
+	return &separatepkg.Account{
+		Id:       "a1",
+		Password: "hunter2",
+		Card:     &separatepkg.Account_Card{Number: "4111111111111111"},
+		Cards:    []*separatepkg.Account_Card{{Number: "4111111111111111"}},
+		Profile:  &common.Profile{Name: "Jane", Email: "jane@example.com"},
+		Secret:   &separatepkg.Account_Backup{Backup: &separatepkg.Account_Card{Number: "4111111111111111"}},
+	}
+}
+
+func TestRedactAccount(t *testing.T) {
+	acc := newAccount()
+	RedactAccount(acc)
+
+	if acc.Id != "a1" {
+		t.Errorf("Id should be kept, got %q", acc.Id)
+	}
+	if acc.Password != "REDACTED" {
+		t.Errorf("Password should be redacted, got %q", acc.Password)
+	}
+	if acc.Card.Number == "4111111111111111" || acc.Cards[0].Number == "4111111111111111" {
+		t.Errorf("Card numbers should be masked, got %q and %q", acc.Card.Number, acc.Cards[0].Number)
+	}
+	if acc.GetBackup().Number == "4111111111111111" {
+		t.Errorf("Backup card number should be masked, got %q", acc.GetBackup().Number)
+	}
+	if acc.Profile.Email != "hidden" || acc.Profile.Name != "Jane" {
+		t.Errorf("Profile of the other package should be redacted by reflection, got %v", acc.Profile)
+	}
+
+	if _, ok := interface{}(acc).(redact.Redactor); ok {
+		t.Error("Account should not have a Redact() method")
+	}
+}
+
+// accountConn replies to the unary calls with an account
+type accountConn struct {
+	grpc.ClientConnInterface
+}
+
+func (accountConn) Invoke(_ context.Context, _ string, _, reply interface{}, _ ...grpc.CallOption) error {
+	proto.Merge(reply.(proto.Message), newAccount())
+	return nil
+}
+
+func TestRedactedClient(t *testing.T) {
+	client := NewRedactedAccountsClient(accountConn{})
+
+	res, err := client.GetAccount(context.Background(), &separatepkg.GetAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Password != "REDACTED" {
+		t.Errorf("Password should be redacted, got %q", res.Password)
+	}
+
+	res, err = client.GetRawAccount(context.Background(), &separatepkg.GetAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Password != "hunter2" {
+		t.Errorf("Password of the skipped method should be kept, got %q", res.Password)
+	}
+}
+
+// accountsServer replies with the account, recording the request
+type accountsServer struct {
+	separatepkg.UnimplementedAccountsServer
+	req *separatepkg.GetAccountRequest
+}
+
+func (s *accountsServer) GetAccount(_ context.Context, req *separatepkg.GetAccountRequest) (*separatepkg.Account, error) {
+	s.req = req
+	return newAccount(), nil
+}
+
+func TestRedactedServer(t *testing.T) {
+	srv := &accountsServer{}
+	res, err := RedactedAccountsServer(srv, nil).GetAccount(context.Background(),
+		&separatepkg.GetAccountRequest{Id: "a1", Password: "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.req.Password != "REDACTED" {
+		t.Errorf("Password of the request should be redacted, got %q", srv.req.Password)
+	}
+	if res.Password != "REDACTED" {
+		t.Errorf("Password of the response should be redacted, got %q", res.Password)
+	}
+}
@@ -0,0 +1,20 @@
+package sharedempty
+
+import "testing"
+
+func TestSharedEmptyRedaction(t *testing.T) {
+	msg := &Deployment{
+		Primary:  &Config{Secret: "p"},
+		Fallback: &Config{Secret: "f"},
+		Replicas: []*Config{{Secret: "r1"}, {Secret: "r2"}},
+		Regions:  map[string]*Config{"eu": {Secret: "eu"}},
+	}
+	msg.Redact()
+
+	if msg.Primary.GetSecret() != "" || msg.Primary != msg.Fallback {
+		t.Errorf("Primary and Fallback should share the empty Config, got %p and %p", msg.Primary, msg.Fallback)
+	}
+	if msg.Replicas[0] != msg.Primary || msg.Replicas[1] != msg.Primary || msg.Regions["eu"] != msg.Primary {
+		t.Errorf("Items should share the empty Config")
+	}
+}
@@ -0,0 +1,43 @@
+package stdalias
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	codespb "github.com/menta2k/protoc-gen-redact/v3/testdata/stdalias/codes"
+	statuspb "github.com/menta2k/protoc-gen-redact/v3/testdata/stdalias/status"
+)
+
+type checksServer struct {
+	UnimplementedChecksServer
+}
+
+func (checksServer) Check(context.Context, *Request) (*statuspb.Detail, error) {
+	return &statuspb.Detail{Reason: "secret"}, nil
+}
+
+func TestStandardAliasCollisions(t *testing.T) {
+	srv := RedactedChecksServer(checksServer{}, nil)
+
+	res, err := srv.Check(context.Background(), &Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Reason != "hidden" {
+		t.Errorf("Detail should be redacted, got %q", res.Reason)
+	}
+
+	_, err = srv.CheckInternal(context.Background(), &Request{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Internal method should fail with the gRPC status, got %v", err)
+	}
+
+	msg := &Request{Code: codespb.Code_CODE_UNSPECIFIED, Detail: &statuspb.Detail{Reason: "secret"}}
+	msg.Redact()
+	if msg.Code != codespb.Code_CODE_DENIED || msg.Detail.Reason != "hidden" {
+		t.Errorf("Fields of the user packages should be redacted, got %v", msg)
+	}
+}
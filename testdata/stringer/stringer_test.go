@@ -0,0 +1,30 @@
+package stringer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedactedString(t *testing.T) {
+	msg := &Account{Username: "john", Password: "secret"}
+
+	for _, got := range []string{msg.RedactedString(), fmt.Sprintf("%#v", msg)} {
+		if strings.Contains(got, "secret") || !strings.Contains(got, "hidden") || !strings.Contains(got, "john") {
+			t.Errorf("Should print the redacted message, got %s", got)
+		}
+	}
+	if msg.Password != "secret" {
+		t.Errorf("Message should not be modified, got %q", msg.Password)
+	}
+}
+
+func TestRedactedStringNil(t *testing.T) {
+	var msg *Account
+	if got := msg.RedactedString(); got != "<nil>" {
+		t.Errorf("Nil message should print <nil>, got %q", got)
+	}
+	if got := (&Session{Token: "secret"}).RedactedString(); got != "<nil>" {
+		t.Errorf("Message redacted to nil should print <nil>, got %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package wellknown
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestWellKnownRedaction(t *testing.T) {
+	now := time.Now()
+	fields := map[string]interface{}{"email": "john@example.com"}
+	msg := &Session{
+		CreatedAt: timestamppb.New(now),
+		ExpiresAt: timestamppb.New(now),
+		UpdatedAt: timestamppb.New(now),
+		Ttl:       durationpb.New(time.Hour),
+		Logins:    []*timestamppb.Timestamp{timestamppb.New(now)},
+		Timings:   map[string]*durationpb.Duration{"db": durationpb.New(time.Second)},
+		Detail:    mustAny(t, timestamppb.New(now)),
+		Context:   mustAny(t, timestamppb.New(now)),
+		Trace:     mustAny(t, timestamppb.New(now)),
+		Metadata:  mustStruct(t, fields),
+		Attribute: structpb.NewStringValue("john@example.com"),
+		Tags:      &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("vip")}},
+		Labels:    mustStruct(t, fields),
+	}
+	msg.Redact()
+
+	if got := msg.CreatedAt.AsTime(); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("CreatedAt should be the epoch, got %v", got)
+	}
+	if msg.ExpiresAt != nil {
+		t.Errorf("ExpiresAt should be nil, got %v", msg.ExpiresAt)
+	}
+	if got := msg.UpdatedAt.AsTime(); !got.Equal(now) {
+		t.Errorf("UpdatedAt should be kept, got %v", got)
+	}
+	if got := msg.Ttl.AsDuration(); got != 0 {
+		t.Errorf("Ttl should be zero, got %v", got)
+	}
+	if got := msg.Logins[0].AsTime(); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("Logins should be the epoch, got %v", got)
+	}
+	if got := msg.Timings["db"].AsDuration(); got != 0 {
+		t.Errorf("Timings should be zero, got %v", got)
+	}
+	if msg.Detail == nil || msg.Detail.TypeUrl != "" || len(msg.Detail.Value) != 0 {
+		t.Errorf("Detail should be cleared, got %v", msg.Detail)
+	}
+	if msg.Context != nil {
+		t.Errorf("Context should be nil, got %v", msg.Context)
+	}
+	if len(msg.Trace.GetValue()) == 0 {
+		t.Errorf("Trace should be kept, got %v", msg.Trace)
+	}
+	if msg.Metadata == nil || len(msg.Metadata.Fields) != 0 {
+		t.Errorf("Metadata should be emptied, got %v", msg.Metadata)
+	}
+	if _, ok := msg.Attribute.GetKind().(*structpb.Value_NullValue); !ok {
+		t.Errorf("Attribute should be null, got %v", msg.Attribute)
+	}
+	if msg.Tags == nil || len(msg.Tags.Values) != 0 {
+		t.Errorf("Tags should be emptied, got %v", msg.Tags)
+	}
+	if msg.Labels != nil {
+		t.Errorf("Labels should be nil, got %v", msg.Labels)
+	}
+}
+
+func TestDurationRedaction(t *testing.T) {
+	msg := &Lease{
+		Ttl:     durationpb.New(time.Hour),
+		Grace:   durationpb.New(time.Minute),
+		Renewal: durationpb.New(time.Second),
+	}
+	msg.Redact()
+
+	if msg.Ttl == nil || msg.Ttl.AsDuration() != 0 {
+		t.Errorf("Ttl should be zero, got %v", msg.Ttl)
+	}
+	if msg.Grace != nil {
+		t.Errorf("Grace should be nil, got %v", msg.Grace)
+	}
+	if msg.Renewal == nil || msg.Renewal.AsDuration() != 0 {
+		t.Errorf("Renewal should be empty, got %v", msg.Renewal)
+	}
+}
+
+func mustStruct(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+	t.Helper()
+	s, err := structpb.NewStruct(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func mustAny(t *testing.T, msg *timestamppb.Timestamp) *anypb.Any {
+	t.Helper()
+	a, err := anypb.New(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
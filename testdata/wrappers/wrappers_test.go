@@ -0,0 +1,34 @@
+package wrappers
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWrapperRedaction(t *testing.T) {
+	msg := &Profile{
+		Nickname: wrapperspb.String("jdoe"),
+		Age:      wrapperspb.Int32(42),
+		Verified: wrapperspb.Bool(true),
+		Locale:   wrapperspb.String("en"),
+		Aliases:  []*wrapperspb.StringValue{wrapperspb.String("john")},
+	}
+	msg.Redact()
+
+	if got := msg.Nickname.GetValue(); got != "REDACTED" {
+		t.Errorf("Nickname should be redacted, got %q", got)
+	}
+	if got := msg.Age.GetValue(); got != 0 {
+		t.Errorf("Age should be redacted, got %d", got)
+	}
+	if msg.Verified != nil {
+		t.Errorf("Verified should be nil, got %v", msg.Verified)
+	}
+	if got := msg.Locale.GetValue(); got != "en" {
+		t.Errorf("Locale should be kept, got %q", got)
+	}
+	if got := msg.Aliases[0].GetValue(); got != "REDACTED" {
+		t.Errorf("Aliases should be redacted, got %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+package zerofill
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestZeroFillRedaction(t *testing.T) {
+	msg := &Signed{
+		Sig:   []byte("signature"),
+		Nonce: []byte("nonce"),
+		Chain: [][]byte{[]byte("a"), nil, []byte("abc")},
+		Key:   &Signed_PublicKey{PublicKey: []byte("key")},
+	}
+	msg.Redact()
+
+	if !bytes.Equal(msg.Sig, make([]byte, 9)) || !bytes.Equal(msg.Nonce, make([]byte, 5)) {
+		t.Errorf("Bytes should be zeroed keeping their length, got %v and %v", msg.Sig, msg.Nonce)
+	}
+	if !bytes.Equal(msg.Chain[0], []byte{0}) || msg.Chain[1] != nil || !bytes.Equal(msg.Chain[2], make([]byte, 3)) {
+		t.Errorf("Items should be zeroed keeping their length, got %v", msg.Chain)
+	}
+	if !bytes.Equal(msg.GetPublicKey(), make([]byte, 3)) {
+		t.Errorf("PublicKey should be zeroed, got %v", msg.GetPublicKey())
+	}
+}
+
+func TestZeroFillNil(t *testing.T) {
+	msg := &Signed{Sig: []byte{}}
+	msg.Redact()
+
+	if msg.Sig == nil || len(msg.Sig) != 0 || msg.Nonce != nil || msg.Key != nil {
+		t.Errorf("Nil values should be kept nil and empty values empty, got %v", msg)
+	}
+}
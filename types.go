@@ -4,31 +4,152 @@ package main
 type ProtoFileData struct {
 	Source  string
 	Package string
-	// Imports: alias -> import-path
-	Imports    map[string]string
-	References []string
-	Services   []*ServiceData
-	Messages   []*MessageData
+	// MessagePackage: import alias, with its dot, of the package of the
+	// messages and services with separate_package, the redaction is then
+	// generated as Redact<Message>() functions of a sibling package
+	MessagePackage string
+	// Version: version of the plugin, VersionConst emits it as the
+	// RedactGenVersion constant of the package, with version_const
+	Version      string
+	VersionConst bool
+	// AnnotationsHash: SHA-256 of the redaction options of the proto file, to
+	// detect the generated files out of date
+	AnnotationsHash string
+	// Imports: alias -> import-path, ranging over the map in a template
+	// visits the aliases in sorted order, keeping the output deterministic
+	Imports map[string]string
+	// ImportGroups: imports sorted by path in goimports-style groups, the
+	// standard library, the external and the local packages, set with
+	// group_imports
+	ImportGroups [][]*ImportData
+	References   []string
+	// Placeholders: package-level vars holding the redaction defaults
+	Placeholders []*PlaceholderData
+	// DefaultExprs: expressions of the default_<type>_expr parameters, with
+	// the Go types they are checked against
+	DefaultExprs []*PlaceholderData
+	// EmptyValues: package-level empty values of the messages, shared by the
+	// fields redacted to empty with shared_empty
+	EmptyValues []*PlaceholderData
+	Services    []*ServiceData
+	Messages    []*MessageData
+	// ImportedRedactors: messages of other files, with their import alias,
+	// called for redaction by the fields of the messages
+	ImportedRedactors []string
+	// Requests: requests of the methods of the services, registered for
+	// redact.RedactRequest with register_requests
+	Requests []*RequestData
+	// EmptyFactories: functions returning the empty values of the messages
+	// referenced by the file, asserted to have the expected signature
+	EmptyFactories []*FactoryData
+	// Regexps: package-level compiled patterns of the regex_replace rules
+	Regexps []*PlaceholderData
+	// DepthLimited: fields of the file limit the depth of their nested
+	// redaction, the Redact() methods call redactDepth() with the remaining
+	// depth
+	DepthLimited bool
+
+	// Fallible: Redact() methods return an error instead of the string
+	// representation of the redacted message
+	Fallible bool
+
+	// CtxPredicate: function, with its import alias, deciding from the context
+	// whether the responses of the redacted servers are redacted
+	CtxPredicate string
+
+	// MessagesOnly: the redacted server wrappers of the services are not
+	// generated
+	MessagesOnly bool
+
+	// ClearUnknown: Redact() methods clear the unknown fields of the messages
+	ClearUnknown bool
+
+	// Stringer: RedactedString() and GoString() methods are generated
+	Stringer bool
+
+	// RedactedJSON: RedactedJSON() methods are generated
+	RedactedJSON bool
+
+	// RedactedFieldsMethod: RedactedFields() methods are generated, returning
+	// the RedactedFields of the messages
+	RedactedFieldsMethod bool
+
+	// BuildTag: build constraint of the generated file, NoRedact marks the
+	// stub file generated for the builds without the tag
+	BuildTag string
+	NoRedact bool
+}
+
+// ImportData defines an import of the generated file
+type ImportData struct {
+	Alias string
+	Path  string
+}
+
+// PlaceholderData defines a package-level var holding a redaction default
+type PlaceholderData struct {
+	Name   string
+	GoType string
+	Value  string
+}
+
+// OneofData defines a oneof by the Go name of its field, and the wrapper
+// types of its fields
+type OneofData struct {
+	Name     string
+	Wrappers []string
+}
+
+// FactoryData defines the function returning the empty value of a message,
+// both with their import alias
+type FactoryData struct {
+	Func    string
+	Message string
 }
 
 // ServiceData defines custom data type for Service info needed in template
 type ServiceData struct {
-	Name    string
-	Skip    bool
-	Methods []*MethodData
+	Name       string
+	ClientName string // the Go client interface, e.g. TokensClient
+	Skip       bool
+	Methods    []*MethodData
+	// Replies: replies of the unary methods, redacted by the redacted client
+	// with the functions of the package, with separate_package
+	Replies []*ReplyData
+}
+
+// ReplyData defines the reply of a unary method, with its import alias, and
+// the function redacting it
+type ReplyData struct {
+	Type     string
+	Redactor string
+}
+
+// RequestData defines the request of a method, with its import alias, and the
+// function redacting it
+type RequestData struct {
+	Type     string
+	Redactor string
 }
 
 // MethodData defines custom data type for Method info needed in template
 type MethodData struct {
 	Name            string
+	FullMethod      string // the gRPC full method name, e.g. /pkg.Service/Method
 	Skip            bool
 	Input           string
+	RedactInput     bool         // the request is redacted before calling the handler
 	Output          *MessageData // will only contain name and options (ignore, nil, empty)
 	Internal        bool
 	StatusCode      string
 	ErrMessage      string
 	ClientStreaming bool // true if client sends a stream of requests
 	ServerStreaming bool // true if server sends a stream of responses
+
+	// InputRedactor and OutputRedactor: functions redacting the request and
+	// the response, see FieldData.Redactor
+	InputRedactor  string
+	OutputRedactor string
 }
 
 // MessageData defines custom data type for Message info needed in template
@@ -40,6 +161,54 @@ type MessageData struct {
 	Ignore  bool
 	ToNil   bool
 	ToEmpty bool
+
+	// EmptyFactory: call of the empty_factory of the message, used instead
+	// of a new empty message, e.g. by the servers redacting it to empty
+	EmptyFactory string
+
+	// RedactSummary: lines of the doc comment of the Redact() method, listing
+	// the redacted fields, by their proto names, and how these are redacted
+	RedactSummary []string
+
+	// GatedBy: Go name of the bool field gating the redaction of the message,
+	// the Redact() method is a no-op unless it is true
+	GatedBy string
+
+	// ResetAndCopy resets the message on redaction, only the fields marked
+	// with Keep are copied back
+	ResetAndCopy bool
+	// KeptOneofs: oneofs copied back after the reset when their set field is
+	// marked with Keep
+	KeptOneofs []*OneofData
+
+	// MaxFieldLen caps the length of the string, bytes, repeated and map
+	// fields on redaction, 0 disables the caps
+	MaxFieldLen int
+
+	// CustomRedactor routes the CustomFields through the registered
+	// redact.FieldRedactor on redaction
+	CustomRedactor bool
+	CustomFields   []*CustomFieldData
+
+	// ProofName and ProofPaths are the full proto name of the message and the
+	// proto names of its redacted fields, recorded as proof of redaction
+	ProofName  string
+	ProofPaths []string
+
+	// RedactedFields: proto names of the fields redacted by Redact(), returned
+	// by the RedactedFields() method
+	RedactedFields []string
+
+	// AuditName is the full proto name of the message, reported with its
+	// redacted fields through redact.Audit
+	AuditName string
+}
+
+// CustomFieldData defines a field, or a oneof, routed through the registered
+// redact.FieldRedactor by its proto name
+type CustomFieldData struct {
+	Name   string
+	GoName string
 }
 
 // FieldData defines custom data type for Field info needed in template
@@ -48,12 +217,55 @@ type FieldData struct {
 	// Redact using RedactionValue
 	Redact         bool
 	RedactionValue string
-	FieldGoType    string // Go type for the field (e.g., "int32", "string", "bool")
+	FieldGoType    string // Go type for the field (e.g., "int32", "string", "bool", or the enum type)
+	// EmptyFactory: call of the empty_factory of the embed message, used as
+	// its empty value
+	EmptyFactory string
 
 	IsMap      bool // IsMap: true for Map types
 	IsRepeated bool // IsRepeated: true for Repeated types
 	IsMessage  bool // IsMessage: true for Message type(& not Repeated/Map)
 	IsOptional bool // IsOptional: true for optional types
+	Allow      bool // Allow: true for fields explicitly marked as safe
+	InOneOf    bool // InOneOf: true for fields of a (non synthetic) oneof
+
+	// OneOf and OneOfWrapper will only be used for fields of a oneof, these are
+	// the Go names of the oneof field and of the wrapper type of this field
+	OneOf        string
+	OneOfWrapper string
+
+	// IsOptionalBytes: true for proto3 optional bytes, which are not pointers
+	// and use a nil slice as unset, the redaction only applies to set values
+	IsOptionalBytes bool
+
+	// PANMask will only be used for String types and it specifies whether or
+	// not the card number is masked instead of using RedactionValue
+	PANMask bool
+
+	// RegexVar will only be used for String types, it is the package-level var
+	// of the compiled RegexPattern whose matches are replaced by
+	// RegexReplacement instead of using RedactionValue, both Go literals
+	RegexVar         string
+	RegexPattern     string
+	RegexReplacement string
+
+	// BytesZeroFill will only be used for Bytes types, the set values are
+	// replaced by zeroed slices of the same length instead of RedactionValue
+	BytesZeroFill bool
+
+	// RoundTo will only be used for numeric types, it is the factor the field
+	// is truncated to a multiple of instead of using RedactionValue. The float
+	// and double fields, RoundFloat, are truncated by redact.RoundTo.
+	RoundTo    string
+	RoundFloat bool
+
+	// Magnitude will only be used for integer types, the field is redacted to
+	// its order of magnitude by redact.Magnitude instead of using RedactionValue
+	Magnitude bool
+
+	// CopyFrom is the Go name of the sibling field the field is set to instead
+	// of RedactionValue, the optional fields are copied by value
+	CopyFrom string
 
 	// Iterate will only be used for Repeated/Map types and it specifies
 	// whether or not to iterate each entry to be redacted
@@ -62,6 +274,39 @@ type FieldData struct {
 	// NestedEmbedCall will only be used for Message Types and it specifies
 	// whether or not the embed message should be called for redaction.
 	NestedEmbedCall bool
+	// AuditName: proto name of the field reported through redact.Audit once
+	// redacted, with audit
+	AuditName string
+	// Collapse: the non-empty list is replaced by RedactionValue, a list of a
+	// single sentinel item
+	Collapse bool
+	// SharedEmpty: the RedactionValue is the package-level empty value of the
+	// embed message, shared by the fields redacted to empty
+	SharedEmpty bool
+	// ResetEmpty: the set embed message is reset in place, keeping its pointer,
+	// a nil one is set to RedactionValue, with reset_empty
+	ResetEmpty bool
+
+	// KeyRedact: the map is rebuilt by redact.RedactMapKeys with its keys
+	// redacted to the KeyRedactionValue, or masked with redact.MaskPAN with
+	// KeyPANMask
+	KeyRedact         bool
+	KeyRedactionValue string
+	KeyPANMask        bool
+
+	// Redactor: function redacting the embed message of the nested call,
+	// redact.Apply calling its Redact() method, or with separate_package the
+	// Redact<Message>() function of the package
+	Redactor string
+	// ImportedRedactor: the embed message of the nested call is defined in
+	// another file, its generated Redact() method is asserted
+	ImportedRedactor bool
+	// LocalRedactor: the embed message of the nested call is defined in the
+	// same file, its redactDepth() method is called in the DepthLimited files
+	LocalRedactor bool
+	// Depth: levels of the nested redaction of the embed message, 0 for
+	// unlimited (message.depth)
+	Depth int
 
 	// EmbedSkip will only be used for Message Types and it specifies
 	// whether or not the embed message should be skipped.
@@ -71,4 +316,8 @@ type FieldData struct {
 	// Map or Message type field
 	EmbedMessageName          string
 	EmbedMessageNameWithAlias string
+
+	// Keep will only be used with ResetAndCopy messages and it specifies
+	// whether or not the field is copied back after the reset
+	Keep bool
 }
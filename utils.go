@@ -1,7 +1,20 @@
 package main
 
 import (
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
 	pgs "github.com/lyft/protoc-gen-star/v2"
+
+	"github.com/menta2k/protoc-gen-redact/v3/redact/v3"
 )
 
 // Note: must() and failWithInvalidType() have been moved to errors.go
@@ -15,7 +28,9 @@ import (
 //   - `0th value` for enum type
 //   - `nil` map for map type
 //   - `nil` for repeated field type
-//   - for message type, redaction is applied inside the message type
+//   - `nil` for message type, and the proto2 groups, though the message fields
+//     without rules call the redaction of their message instead, see
+//     nestedEmbedCall
 func RedactionDefaults(typ pgs.ProtoType, isRepeated bool) string {
 	// isRepeated fields is for map or slice type fields
 	if isRepeated {
@@ -33,15 +48,396 @@ func RedactionDefaults(typ pgs.ProtoType, isRepeated bool) string {
 		return "false"
 	case pgs.StringT:
 		return `"REDACTED"`
-	case pgs.BytesT, pgs.GroupT:
+	case pgs.BytesT, pgs.MessageT, pgs.GroupT:
 		return "nil"
-	case pgs.MessageT:
-		return `-`
 	default: // repeated and map
 		return "nil"
 	}
 }
 
+// defaultParams maps the plugin parameters overriding the redaction defaults
+// to their proto types, e.g. `default_int64=-1`
+var defaultParams = map[string]pgs.ProtoType{
+	"default_int32":    pgs.Int32T,
+	"default_int64":    pgs.Int64T,
+	"default_uint32":   pgs.UInt32T,
+	"default_uint64":   pgs.UInt64T,
+	"default_sint32":   pgs.SInt32,
+	"default_sint64":   pgs.SInt64,
+	"default_fixed32":  pgs.Fixed32T,
+	"default_fixed64":  pgs.Fixed64T,
+	"default_sfixed32": pgs.SFixed32,
+	"default_sfixed64": pgs.SFixed64,
+	"default_float":    pgs.FloatT,
+	"default_double":   pgs.DoubleT,
+	"default_bool":     pgs.BoolT,
+	"default_string":   pgs.StringT,
+	"default_bytes":    pgs.BytesT,
+	"default_enum":     pgs.EnumT,
+}
+
+// defaultRegistry returns the per type redaction defaults, seeded with the
+// predefined values of RedactionDefaults
+func defaultRegistry() map[pgs.ProtoType]string {
+	reg := make(map[pgs.ProtoType]string, len(defaultParams))
+	for _, typ := range defaultParams {
+		reg[typ] = RedactionDefaults(typ, false)
+	}
+	return reg
+}
+
+// parseDefault converts the value of a default override parameter into the Go
+// literal used for the type, formatted from the parsed value so that the
+// accepted spellings, e.g. "t" or "+1", produce valid Go. String and bytes
+// values are taken verbatim. NaN and infinities have no literal, they are set
+// with default_float_expr or default_double_expr, e.g. math.NaN().
+func parseDefault(typ pgs.ProtoType, val string) (string, error) {
+	var (
+		lit string
+		err error
+	)
+	switch typ {
+	case pgs.Int32T, pgs.SInt32, pgs.SFixed32, pgs.EnumT:
+		lit, err = formatInt(val, 32)
+	case pgs.Int64T, pgs.SInt64, pgs.SFixed64:
+		lit, err = formatInt(val, 64)
+	case pgs.UInt32T, pgs.Fixed32T:
+		lit, err = formatUint(val, 32)
+	case pgs.UInt64T, pgs.Fixed64T:
+		lit, err = formatUint(val, 64)
+	case pgs.FloatT:
+		lit, err = formatFloat(val, 32)
+	case pgs.DoubleT:
+		lit, err = formatFloat(val, 64)
+	case pgs.BoolT:
+		var b bool
+		b, err = strconv.ParseBool(val)
+		lit = strconv.FormatBool(b)
+	case pgs.StringT:
+		return strconv.Quote(val), nil
+	case pgs.BytesT:
+		return fmt.Sprintf("[]byte(%s)", strconv.Quote(val)), nil
+	default:
+		return "", fmt.Errorf("no default can be set for type %s", typ)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid %s value %q", typ, val)
+	}
+	return lit, nil
+}
+
+// formatInt returns the Go literal of the signed integer value
+func formatInt(val string, bits int) (string, error) {
+	v, err := strconv.ParseInt(val, 10, bits)
+	return strconv.FormatInt(v, 10), err
+}
+
+// formatUint returns the Go literal of the unsigned integer value
+func formatUint(val string, bits int) (string, error) {
+	v, err := strconv.ParseUint(val, 10, bits)
+	return strconv.FormatUint(v, 10), err
+}
+
+// formatFloat returns the Go literal of the finite floating point value
+func formatFloat(val string, bits int) (string, error) {
+	v, err := strconv.ParseFloat(val, bits)
+	if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		err = fmt.Errorf("%v is not finite", v)
+	}
+	return strconv.FormatFloat(v, 'g', -1, bits), err
+}
+
+// defaultExprParams maps the plugin parameters replacing the redaction defaults
+// by Go expressions to their proto types, e.g. `default_int64_expr=math.MinInt64`.
+// The enums have no expression, their Go type differs by field.
+var defaultExprParams = func() map[string]pgs.ProtoType {
+	params := make(map[string]pgs.ProtoType, len(defaultParams))
+	for param, typ := range defaultParams {
+		if typ != pgs.EnumT {
+			params[param+"_expr"] = typ
+		}
+	}
+	return params
+}()
+
+// GoExpr is the Go expression of a default_<type>_expr parameter
+type GoExpr struct {
+	// Code: the expression, its packages referenced by their default alias
+	Code string
+	// Imports: import paths of the packages referenced by the expression, by
+	// their default alias
+	Imports map[string]string
+}
+
+// parseGoExpr parses the expression of a default_<type>_expr parameter, which
+// must be a single Go expression. Its package selectors reference the redact
+// package, the standard library, e.g. `strings.ToUpper("x")`, or, for the
+// leading one, any package by its import path, e.g.
+// `github.com/acme/mask.Placeholder()`.
+func parseGoExpr(expr string) (*GoExpr, error) {
+	res := &GoExpr{Code: strings.TrimSpace(expr), Imports: map[string]string{}}
+
+	// the leading package referenced by its import path is replaced by its alias
+	head := res.Code
+	if i := strings.IndexAny(head, "()[]{} "); i >= 0 {
+		head = head[:i]
+	}
+	if strings.Contains(head, "/") {
+		ref, err := parseGoRef(head)
+		if err != nil {
+			return nil, err
+		}
+		res.Imports[ref.Alias()] = ref.ImportPath
+		res.Code = ref.Alias() + "." + ref.Name + res.Code[len(head):]
+	}
+
+	// the parser rejects the statements, the unbalanced parentheses and the
+	// trailing tokens
+	if _, err := parser.ParseExpr(res.Code); err != nil {
+		return nil, fmt.Errorf("%q is not a single Go expression: %v", expr, err)
+	}
+
+	for _, sel := range res.selectors() {
+		if _, ok := res.Imports[sel.pkg]; ok || token.IsExported(sel.pkg) {
+			// the exported identifiers are declared by the generated package
+			continue
+		}
+		if sel.pkg == "redact" {
+			res.Imports[sel.pkg] = "github.com/menta2k/protoc-gen-redact/v3/redact/v3"
+		} else {
+			res.Imports[sel.pkg] = sel.pkg
+		}
+	}
+	return res, nil
+}
+
+// goSelector is a selector of an exported identifier from an identifier, e.g.
+// a package, spanning the code from start to end
+type goSelector struct {
+	pkg        string
+	start, end int
+}
+
+// selectors lists the selectors of the expression starting with an
+// identifier, e.g. `strings.ToUpper` but neither `x.y.Z` nor `f().Z`
+func (e *GoExpr) selectors() []goSelector {
+	type tok struct {
+		tok token.Token
+		lit string
+		off int
+	}
+	var s scanner.Scanner
+	src := []byte(e.Code)
+	s.Init(token.NewFileSet().AddFile("", -1, len(src)), src, nil, 0)
+	var toks []tok
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		toks = append(toks, tok{t, lit, int(pos) - 1})
+	}
+
+	var list []goSelector
+	for i := 0; i+2 < len(toks); i++ {
+		if toks[i].tok != token.IDENT || toks[i+1].tok != token.PERIOD || toks[i+2].tok != token.IDENT {
+			continue
+		}
+		if (i > 0 && toks[i-1].tok == token.PERIOD) || !token.IsExported(toks[i+2].lit) {
+			continue
+		}
+		list = append(list, goSelector{pkg: toks[i].lit, start: toks[i].off, end: toks[i+2].off})
+	}
+	return list
+}
+
+// resolve returns the Go code of the expression, with the import aliases of
+// its packages, the identifiers of the generated package are not qualified
+func (e *GoExpr) resolve(path2Alias map[string]string) string {
+	var b strings.Builder
+	last := 0
+	for _, sel := range e.selectors() {
+		path, ok := e.Imports[sel.pkg]
+		if !ok {
+			continue
+		}
+		b.WriteString(e.Code[last:sel.start])
+		if alias := path2Alias[path]; alias != "" {
+			b.WriteString(alias + ".")
+		}
+		last = sel.end
+	}
+	b.WriteString(e.Code[last:])
+	return b.String()
+}
+
+// redactionDefault returns the default redaction value for the type, taking
+// the overrides of the plugin parameters into account
+func (m *Module) redactionDefault(typ pgs.ProtoType, isRepeated bool) string {
+	if isRepeated {
+		return RedactionDefaults(typ, isRepeated)
+	}
+	// the options of the file win over the plugin parameters
+	if val, ok := m.fileDefaults[typ]; ok {
+		return val
+	}
+	if m.varPlaceholders {
+		if name := placeholderName(typ); name != "" {
+			return name
+		}
+	}
+	if m.runtimeMarker && typ == pgs.StringT {
+		return runtimeMarkerName
+	}
+	if val, ok := m.defaults[typ]; ok {
+		return val
+	}
+	return RedactionDefaults(typ, isRepeated)
+}
+
+// goStringLiteral returns the Go literal of the string, a raw string literal
+// unless it cannot hold the value unchanged, e.g. a value with a backtick
+func goStringLiteral(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// placeholderName returns the name of the package-level var holding the
+// redaction default of the type, if the type supports placeholders
+func placeholderName(typ pgs.ProtoType) string {
+	switch typ {
+	case pgs.Int32T:
+		return "RedactedInt32"
+	case pgs.Int64T:
+		return "RedactedInt64"
+	case pgs.UInt32T:
+		return "RedactedUint32"
+	case pgs.UInt64T:
+		return "RedactedUint64"
+	case pgs.SInt32:
+		return "RedactedSint32"
+	case pgs.SInt64:
+		return "RedactedSint64"
+	case pgs.Fixed32T:
+		return "RedactedFixed32"
+	case pgs.Fixed64T:
+		return "RedactedFixed64"
+	case pgs.SFixed32:
+		return "RedactedSfixed32"
+	case pgs.SFixed64:
+		return "RedactedSfixed64"
+	case pgs.FloatT:
+		return "RedactedFloat"
+	case pgs.DoubleT:
+		return "RedactedDouble"
+	case pgs.BoolT:
+		return "RedactedBool"
+	case pgs.StringT:
+		return "RedactedString"
+	default:
+		return ""
+	}
+}
+
+// runtimeMarkerName is the package-level var holding the string redaction
+// default with runtime_marker
+const runtimeMarkerName = "RedactedStringValue"
+
+// placeholders returns the package-level vars for the redaction defaults,
+// sorted by name, or only the string marker with runtime_marker
+func (m *Module) placeholders() []*PlaceholderData {
+	if m.runtimeMarker {
+		value, ok := m.defaults[pgs.StringT]
+		if !ok {
+			value = RedactionDefaults(pgs.StringT, false)
+		}
+		return []*PlaceholderData{{Name: runtimeMarkerName, GoType: goTypeName(pgs.StringT), Value: value}}
+	}
+	list := make([]*PlaceholderData, 0, len(defaultParams))
+	for _, typ := range defaultParams {
+		name := placeholderName(typ)
+		if name == "" {
+			continue
+		}
+		value, ok := m.defaults[typ]
+		if !ok {
+			value = RedactionDefaults(typ, false)
+		}
+		list = append(list, &PlaceholderData{Name: name, GoType: goTypeName(typ), Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// packageOwners returns the files declaring the package-level placeholders:
+// the first file, by name, of each Go package. The files with file_skip are
+// not generated, hence cannot declare them.
+func (m *Module) packageOwners(targets map[string]pgs.File) map[string]bool {
+	owners := make(map[string]string, len(targets))
+	for _, file := range targets {
+		fileSkip := false
+		if m.must(file.Extension(redact.E_FileSkip, &fileSkip)) && fileSkip {
+			continue
+		}
+		pkg := m.ctx.ImportPath(file).String()
+		name := file.Name().String()
+		if cur, ok := owners[pkg]; !ok || name < cur {
+			owners[pkg] = name
+		}
+	}
+	files := make(map[string]bool, len(owners))
+	for _, name := range owners {
+		files[name] = true
+	}
+	return files
+}
+
+// GoRef references a package-level Go identifier by its import path
+type GoRef struct {
+	ImportPath string
+	Name       string
+}
+
+// parseGoRef parses a `<import-path>.<Name>` reference, e.g.
+// `github.com/acme/authz.ShouldRedact`
+func parseGoRef(ref string) (*GoRef, error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 || strings.LastIndex(ref, "/") > i {
+		return nil, fmt.Errorf("%q must be formatted as <import-path>.<Name>", ref)
+	}
+	res := &GoRef{ImportPath: ref[:i], Name: ref[i+1:]}
+	if !token.IsIdentifier(res.Name) || !token.IsExported(res.Name) {
+		return nil, fmt.Errorf("%q is not an exported Go identifier", res.Name)
+	}
+	return res, nil
+}
+
+// Alias returns the default import alias of the referenced package
+func (r *GoRef) Alias() string {
+	base := path.Base(r.ImportPath)
+	alias := strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' {
+			return c
+		}
+		return '_'
+	}, base)
+	if !token.IsIdentifier(alias) {
+		alias = "_" + alias
+	}
+	return alias
+}
+
+// rulesProtoType returns the proto type of the rules matching the fields of the
+// type, the proto2 groups are redacted as the messages they generate
+func rulesProtoType(typ pgs.ProtoType) pgs.ProtoType {
+	if typ == pgs.GroupT {
+		return pgs.MessageT
+	}
+	return typ
+}
+
 // ToCustomRule return redact proto' field rules based on their type
 func ToCustomRule(typ pgs.ProtoType, lab pgs.ProtoLabel) string {
 	if lab == pgs.Repeated {
@@ -80,7 +476,7 @@ func ToCustomRule(typ pgs.ProtoType, lab pgs.ProtoLabel) string {
 		return "(redact.custom).bytes"
 	case pgs.EnumT:
 		return "(redact.custom).enum"
-	case pgs.MessageT:
+	case pgs.MessageT, pgs.GroupT:
 		return "(redact.custom).message.*"
 	default:
 		return "(redact.redact)"
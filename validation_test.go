@@ -168,8 +168,10 @@ func TestErrorMessageFormatting(t *testing.T) {
 	tests := []struct {
 		name        string
 		template    string
+		packageName string
 		serviceName string
 		methodName  string
+		fqn         string
 		expected    string
 	}{
 		{
@@ -207,14 +209,39 @@ func TestErrorMessageFormatting(t *testing.T) {
 			methodName:  "RefundAll",
 			expected:    "RefundAll in PaymentService: RefundAll is not allowed",
 		},
+		{
+			name:        "package_and_fqn",
+			template:    "%fqn% of package %package% is internal",
+			packageName: "acme.payment.v1",
+			serviceName: "PaymentServiceServer",
+			methodName:  "RefundAll",
+			fqn:         "acme.payment.v1.PaymentService.RefundAll",
+			expected:    "acme.payment.v1.PaymentService.RefundAll of package acme.payment.v1 is internal",
+		},
+		{
+			name:        "unknown_specifiers",
+			template:    "%method% denied for %user% (100%)",
+			serviceName: "UserService",
+			methodName:  "GetUser",
+			expected:    "GetUser denied for %user% (100%)",
+		},
+		{
+			name:        "specifier_in_value",
+			template:    "%service%.%method%",
+			serviceName: "Service%method%",
+			methodName:  "GetUser",
+			expected:    "Service%method%.GetUser",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.template
-			// Simulate the format specifier replacement
-			result = replaceAll(result, "%service%", tt.serviceName)
-			result = replaceAll(result, "%method%", tt.methodName)
+			result := errSpecifiers{
+				pkg:     tt.packageName,
+				service: tt.serviceName,
+				method:  tt.methodName,
+				fqn:     tt.fqn,
+			}.format(tt.template)
 
 			assert.Equal(t, tt.expected, result,
 				"Error message formatting failed for template: %s", tt.template)
@@ -222,21 +249,6 @@ func TestErrorMessageFormatting(t *testing.T) {
 	}
 }
 
-// Helper function to replace all occurrences (simplified version)
-func replaceAll(s, old, replacement string) string {
-	result := ""
-	for i := 0; i < len(s); {
-		if i <= len(s)-len(old) && s[i:i+len(old)] == old {
-			result += replacement
-			i += len(old)
-		} else {
-			result += string(s[i])
-			i++
-		}
-	}
-	return result
-}
-
 // TestMessageOptionsValidation tests mutual exclusivity of message options
 func TestMessageOptionsValidation(t *testing.T) {
 	tests := []struct {
@@ -572,7 +584,7 @@ func TestMapFieldRedactionStrategies(t *testing.T) {
 			keyType:        pgs.Int32T,
 			valueType:      pgs.MessageT,
 			strategy:       "iterate_nested",
-			redactionValue: "-",
+			redactionValue: "nil",
 			shouldIterate:  true,
 			description:    "Iterate and call Redact on each message value",
 		},
@@ -847,15 +859,11 @@ func BenchmarkTypeValidation(b *testing.B) {
 // BenchmarkErrorMessageFormatting benchmarks error message formatting
 func BenchmarkErrorMessageFormatting(b *testing.B) {
 	template := "Permission Denied. Method: \"%service%.%method%\" has been redacted"
-	serviceName := "UserService"
-	methodName := "GetUser"
+	specifiers := errSpecifiers{service: "UserService", method: "GetUser"}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		result := template
-		result = replaceAll(result, "%service%", serviceName)
-		result = replaceAll(result, "%method%", methodName)
-		_ = result
+		_ = specifiers.format(template)
 	}
 }
 
@@ -912,6 +920,35 @@ func TestRuleInformationComplexCases(t *testing.T) {
 				assert.Equal(t, pgs.MessageT, info.ProtoType)
 			},
 		},
+		{
+			name: "round_matches_any_integer",
+			rules: &redact.FieldRules{
+				Values: &redact.FieldRules_Round{Round: 1000},
+			},
+			validate: func(t *testing.T, info RuleInfo) {
+				assert.Equal(t, pgs.ProtoType(0), info.ProtoType)
+				assert.Equal(t, "1000", info.Literal)
+			},
+		},
+		{
+			name: "round_to_float_literal",
+			rules: &redact.FieldRules{
+				Values: &redact.FieldRules_RoundTo{RoundTo: 0.5},
+			},
+			validate: func(t *testing.T, info RuleInfo) {
+				assert.Equal(t, pgs.ProtoType(0), info.ProtoType)
+				assert.Equal(t, "0.5", info.Literal)
+			},
+		},
+		{
+			name: "zero_fill_matches_bytes",
+			rules: &redact.FieldRules{
+				Values: &redact.FieldRules_ZeroFill{ZeroFill: true},
+			},
+			validate: func(t *testing.T, info RuleInfo) {
+				assert.Equal(t, pgs.BytesT, info.ProtoType)
+			},
+		},
 	}
 
 	for _, tt := range tests {
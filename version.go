@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// version of the plugin, stamped at build time, e.g. with
+// -ldflags "-X main.version=v3.1.0"
+var version = "dev"
+
+// releaseVersion matches the versions of the tagged releases, the local
+// builds stamp pseudo-versions, changing with every commit
+var releaseVersion = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+// pluginVersion returns the version of the plugin: the stamped one, or the
+// version of the module installed with `go install ...@v3.x.y`
+func pluginVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && releaseVersion.MatchString(info.Main.Version) {
+		return info.Main.Version
+	}
+	return version
+}
+
+// annotationsHash returns the SHA-256 of the redaction options of the file,
+// its messages, fields, services and methods, to detect the generated files
+// out of date with their proto file
+func annotationsHash(file pgs.File) string {
+	var lines []string
+	add := func(entity string, opts proto.Message) {
+		if opts == nil || !opts.ProtoReflect().IsValid() {
+			return
+		}
+		opts.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if !fd.IsExtension() || !strings.HasPrefix(string(fd.FullName()), "redact.") {
+				return true
+			}
+			value := fmt.Sprint(v.Interface())
+			if fd.Message() != nil {
+				b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(v.Message().Interface())
+				value = hex.EncodeToString(b)
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", entity, fd.FullName(), value))
+			return true
+		})
+	}
+
+	add(file.Name().String(), file.Descriptor().GetOptions())
+	for _, msg := range file.AllMessages() {
+		add(msg.FullyQualifiedName(), msg.Descriptor().GetOptions())
+		for _, field := range msg.Fields() {
+			add(field.FullyQualifiedName(), field.Descriptor().GetOptions())
+		}
+	}
+	for _, srv := range file.Services() {
+		add(srv.FullyQualifiedName(), srv.Descriptor().GetOptions())
+		for _, meth := range srv.Methods() {
+			add(meth.FullyQualifiedName(), meth.Descriptor().GetOptions())
+		}
+	}
+
+	// the options are ranged in an undefined order
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPluginVersion tests the stamped version is used, and only the versions
+// of the tagged releases are read from the build info
+func TestPluginVersion(t *testing.T) {
+	defer func(v string) { version = v }(version)
+
+	version = "v3.2.1"
+	assert.Equal(t, "v3.2.1", pluginVersion())
+
+	version = "dev"
+	assert.Equal(t, "dev", pluginVersion(), "Test binaries have no release version")
+
+	for v, release := range map[string]bool{
+		"v3.2.1":                               true,
+		"(devel)":                              false,
+		"v3.2.2-0.20261016120000-0123456789ab": false,
+		"v3.2.1+dirty":                         false,
+	} {
+		assert.Equal(t, release, releaseVersion.MatchString(v), v)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+)
+
+// Fully qualified names of the well-known types redacted by value, as they
+// have no generated Redact() method
+const (
+	timestampName = ".google.protobuf.Timestamp"
+	durationName  = ".google.protobuf.Duration"
+	anyName       = ".google.protobuf.Any"
+	structName    = ".google.protobuf.Struct"
+	valueName     = ".google.protobuf.Value"
+	listValueName = ".google.protobuf.ListValue"
+)
+
+// wrapperType describes a well-known wrapper type by the wrapperspb
+// constructor and the proto type of the wrapped value
+type wrapperType struct {
+	ctor string
+	typ  pgs.ProtoType
+}
+
+// wrapperTypes are the well-known wrapper types, by fully qualified name
+var wrapperTypes = map[string]wrapperType{
+	".google.protobuf.DoubleValue": {"Double", pgs.DoubleT},
+	".google.protobuf.FloatValue":  {"Float", pgs.FloatT},
+	".google.protobuf.Int64Value":  {"Int64", pgs.Int64T},
+	".google.protobuf.UInt64Value": {"UInt64", pgs.UInt64T},
+	".google.protobuf.Int32Value":  {"Int32", pgs.Int32T},
+	".google.protobuf.UInt32Value": {"UInt32", pgs.UInt32T},
+	".google.protobuf.BoolValue":   {"Bool", pgs.BoolT},
+	".google.protobuf.StringValue": {"String", pgs.StringT},
+	".google.protobuf.BytesValue":  {"Bytes", pgs.BytesT},
+}
+
+// wellKnownDefault returns the redaction value of a well-known message type,
+// it returns false if the message is not a handled well-known type.
+//   - `Timestamp` is redacted to the unix epoch
+//   - `Duration` is redacted to zero
+//   - `Any` is cleared, both its type URL and payload, as the packed message
+//     cannot be known at generation time
+//   - `Struct` and `ListValue` are emptied, `Value` is set to null
+//   - wrappers, e.g. `StringValue`, wrap the redaction default of their type
+func (m *Module) wellKnownDefault(em pgs.Message, nameWithAlias func(n pgs.Entity) string) (string, bool) {
+	if em == nil {
+		return "", false
+	}
+	name := nameWithAlias(em)
+	pkg := strings.TrimSuffix(name, "."+em.Name().String())
+	switch em.FullyQualifiedName() {
+	case timestampName:
+		return fmt.Sprintf("%s.New(time.Unix(0, 0))", pkg), true
+	case durationName:
+		return fmt.Sprintf("%s.New(0)", pkg), true
+	case anyName, structName, listValueName:
+		return fmt.Sprintf("&%s{}", name), true
+	case valueName:
+		return fmt.Sprintf("%s.NewNullValue()", pkg), true
+	}
+	if wrapper, ok := wrapperTypes[em.FullyQualifiedName()]; ok {
+		return fmt.Sprintf("%s.%s(%s)", pkg, wrapper.ctor, m.redactionDefault(wrapper.typ, false)), true
+	}
+	return "", false
+}
+
+// importsTimestamp checks if the file imports the Timestamp well-known type,
+// whose redaction value is built using the time package
+func importsTimestamp(file pgs.File) bool {
+	for _, imp := range file.Imports() {
+		if imp == nil {
+			continue
+		}
+		for _, msg := range imp.AllMessages() {
+			if msg.FullyQualifiedName() == timestampName {
+				return true
+			}
+		}
+	}
+	return false
+}